@@ -133,12 +133,9 @@ func runAliasAdd(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Add alias
-	cfg.Aliases[name] = command
-
-	// Save config
-	if err := cfg.Save(appVault.ConfigPath); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	// Add alias and save config
+	if err := saveGlobalConfig(func(global *config.Config) { global.Aliases[name] = command }); err != nil {
+		return err
 	}
 
 	fmt.Println(ui.FormatSuccess(fmt.Sprintf("✓ Created alias: %s → %s", name, command)))
@@ -158,12 +155,9 @@ func runAliasRemove(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("alias '%s' not found", name)
 	}
 
-	// Remove alias
-	delete(cfg.Aliases, name)
-
-	// Save config
-	if err := cfg.Save(appVault.ConfigPath); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	// Remove alias and save config
+	if err := saveGlobalConfig(func(global *config.Config) { delete(global.Aliases, name) }); err != nil {
+		return err
 	}
 
 	fmt.Println(ui.FormatSuccess(fmt.Sprintf("✓ Removed alias: %s → %s", name, command)))
@@ -213,6 +207,7 @@ func isReservedCommand(name string) bool {
 		"delete":     true,
 		"build":      true,
 		"build-all":  true,
+		"build-log":  true,
 		"init":       true,
 		"purge":      true,
 		"version":    true,
@@ -220,6 +215,7 @@ func isReservedCommand(name string) bool {
 		"clone":      true,
 		"sync":       true,
 		"rename":     true,
+		"move":       true,
 		"doctor":     true,
 		"stats":      true,
 		"clean":      true,
@@ -227,6 +223,9 @@ func isReservedCommand(name string) bool {
 		"tag":        true,
 		"graph":      true,
 		"grep":       true,
+		"search":     true,
+		"saved":      true,
+		"related":    true,
 		"daily":      true,
 		"links":      true,
 		"explore":    true,
@@ -242,6 +241,10 @@ func isReservedCommand(name string) bool {
 		"alias":      true,
 		"dashboard":  true,
 		"dash":       true,
+		"vault":      true,
+		"trash":      true,
+		"undo":       true,
+		"history":    true,
 		"help":       true,
 	}
 
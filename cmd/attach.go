@@ -31,6 +31,7 @@ func runAttach(cmd *cobra.Command, args []string) error {
 	srcPath := args[0]
 
 	svc := services.NewAttachmentService(appVault, assetRepo)
+	svc.SetJournal(journal)
 
 	absPath, err := filepath.Abs(srcPath)
 	if err != nil {
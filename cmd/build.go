@@ -5,22 +5,35 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
 	"github.com/spf13/cobra"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 )
 
 var (
-	buildOpen     bool
-	buildTemplate bool
+	buildOpen      bool
+	buildTemplate  bool
+	buildEditError bool
+
+	buildReport     string
+	buildReportFile string
+	buildTimeout    time.Duration
+	buildShow       []string
 )
 
+// buildShowValues lists what --show can add to the build output
+var buildShowValues = []string{"warnings", "boxes"}
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
 	Use:     "build [query]",
@@ -37,6 +50,10 @@ Examples:
   lx build graph
   lx build "chemistry lab"
   lx build calc --open
+  lx build calc --edit-error   # Jump to the first error in your editor
+  lx build calc --report junit --report-file build.xml
+  lx build calc --timeout 30s
+  lx build calc --show boxes   # Include overfull/underfull box warnings
 
   # Test build a template
   lx build -t
@@ -49,6 +66,11 @@ Examples:
 func init() {
 	buildCmd.Flags().BoolVar(&buildOpen, "open", false, "Open the PDF after building")
 	buildCmd.Flags().BoolVarP(&buildTemplate, "template", "t", false, "Build a template instead of a note")
+	buildCmd.Flags().BoolVarP(&buildEditError, "edit-error", "e", false, "Open the editor at the first error if the build fails")
+	buildCmd.Flags().StringSliceVar(&buildShow, "show", nil, "Extra issues to list: warnings, boxes")
+	buildCmd.Flags().DurationVar(&buildTimeout, "timeout", 0, "Kill the build after this long (default from build_timeout config)")
+	buildCmd.Flags().StringVar(&buildReport, "report", "", "Write a build report (json, junit)")
+	buildCmd.Flags().StringVar(&buildReportFile, "report-file", "", "Report destination (default build-report.json/.xml, - for stdout)")
 }
 
 func runBuild(cmd *cobra.Command, args []string) error {
@@ -64,6 +86,17 @@ func runBuild(cmd *cobra.Command, args []string) error {
 func runBuildNote(cmd *cobra.Command, args []string) error {
 	ctx := getContext()
 
+	if err := validateReportFormat(buildReport); err != nil {
+		return err
+	}
+	defer redirectOutputForReport(buildReport, buildReportFile)()
+	for _, value := range buildShow {
+		if !slices.Contains(buildShowValues, value) {
+			return fmt.Errorf("invalid --show value: %s (supported: %s)", value, strings.Join(buildShowValues, ", "))
+		}
+	}
+	formatOpts := latexparser.FormatOptions{ShowBoxes: slices.Contains(buildShow, "boxes")}
+
 	var searchResp *services.SearchResponse
 	var err error
 	useFuzzyFinder := len(args) == 0
@@ -193,18 +226,45 @@ func runBuildNote(cmd *cobra.Command, args []string) error {
 	// Build the note using BuildService
 	// This will now use the Preprocessor -> Compiler pipeline
 	buildReq := services.BuildRequest{
-		Slug: selectedNote.Slug,
+		Slug:    selectedNote.Slug,
+		Timeout: buildTimeout,
 	}
 
 	fmt.Println(ui.FormatRocket("Compiling LaTeX..."))
 
 	// Get detailed build results
-	buildDetails, err := buildService.ExecuteWithDetails(ctx, buildReq)
+	// Ctrl+C only cancels the build itself, so it is trapped just around it
+	buildCtx, stop := getInterruptibleContext()
+	buildDetails, err := buildService.ExecuteWithDetails(buildCtx, buildReq)
+	cancelled := buildCtx.Err() != nil
+	stop()
+
+	if buildReport != "" && buildDetails != nil {
+		results := []services.BuildResponse{buildDetails.Response()}
+		if reportErr := writeBuildReport(results, 0, buildReport, buildReportFile); reportErr != nil {
+			fmt.Println(ui.FormatWarning("Failed to write report: " + reportErr.Error()))
+		}
+	}
+
 	if err != nil {
+		if buildDetails != nil && buildDetails.TimedOut {
+			fmt.Println(ui.FormatError("Build timed out after " + buildDetails.Duration.Round(time.Second).String()))
+			fmt.Println(ui.FormatMuted("The note may be stuck in a loop; raise the limit with --timeout or build_timeout"))
+			return err
+		}
+		if cancelled {
+			fmt.Println(ui.FormatWarning("Build cancelled"))
+			return err
+		}
+
 		fmt.Println(ui.FormatError("Build failed"))
 		fmt.Println()
 		if buildDetails != nil && buildDetails.Parsed != nil {
-			fmt.Println(buildDetails.Parsed.FormatIssues())
+			fmt.Println(buildDetails.Parsed.FormatIssuesWith(formatOpts))
+
+			if buildEditError {
+				openFirstError(buildDetails.Parsed)
+			}
 		} else {
 			fmt.Println(ui.FormatMuted("Error details:"))
 			fmt.Println(err.Error())
@@ -213,12 +273,30 @@ func runBuildNote(cmd *cobra.Command, args []string) error {
 	}
 
 	// Success - show summary
-	if buildDetails.Parsed != nil {
-		fmt.Println(buildDetails.Parsed.GetSummary())
+	if parsed := buildDetails.Parsed; parsed != nil {
+		fmt.Println(parsed.GetSummary())
+
+		// Show warnings (grouped by kind) when asked, otherwise just count them
+		if len(buildShow) > 0 {
+			shown := *parsed
+			if !slices.Contains(buildShow, "warnings") {
+				shown.Warnings = nil
+			}
+			fmt.Print(shown.FormatIssuesWith(formatOpts))
+		} else {
+			// Ignored header options are always shown; they change how the note builds
+			for _, issue := range parsed.Warnings {
+				if issue.Kind == latexparser.KindBuildOption {
+					fmt.Println(ui.FormatWarning(issue.Location() + ": " + issue.Message))
+				}
+			}
+			if len(parsed.Warnings) > 0 {
+				fmt.Println(ui.FormatMuted(fmt.Sprintf("\n⚠️  %d warning(s) (LaTeX warnings can usually be ignored)", len(parsed.Warnings))))
+			}
+		}
 
-		// Show warnings if any
-		if len(buildDetails.Parsed.Warnings) > 0 {
-			fmt.Println(ui.FormatMuted(fmt.Sprintf("\n⚠️  %d warning(s) (LaTeX warnings can usually be ignored)", len(buildDetails.Parsed.Warnings))))
+		if len(parsed.Boxes) > 0 && !formatOpts.ShowBoxes {
+			fmt.Println(ui.FormatMuted(fmt.Sprintf("%d overfull/underfull box warning(s) hidden (use --show boxes)", len(parsed.Boxes))))
 		}
 	} else {
 		fmt.Println(ui.FormatSuccess("Build completed successfully!"))
@@ -258,6 +336,84 @@ func openFile(path string) error {
 	return cmd.Run()
 }
 
+// validateReportFormat rejects unknown --report values before anything is built
+func validateReportFormat(format string) error {
+	if format == "" || slices.Contains(services.ReportFormats, format) {
+		return nil
+	}
+	return fmt.Errorf("unsupported report format: %s (supported: %s)", format, strings.Join(services.ReportFormats, ", "))
+}
+
+// reportStdout is the real standard output, where reports written to "-" go
+var reportStdout = os.Stdout
+
+// redirectOutputForReport sends the command's own output to stderr while a report
+// goes to stdout, so the report can be piped. It returns a func that restores stdout
+func redirectOutputForReport(format, path string) func() {
+	if format == "" || path != "-" {
+		return func() {}
+	}
+	stdout := os.Stdout
+	os.Stdout = os.Stderr
+	return func() { os.Stdout = stdout }
+}
+
+// writeBuildReport writes build results as a JSON or JUnit report
+// An empty path picks a default file name; "-" writes to stdout
+func writeBuildReport(results []services.BuildResponse, skipped int, format string, path string) error {
+	report, err := buildService.Report(getContext(), results, skipped)
+	if err != nil {
+		return err
+	}
+
+	if path == "-" {
+		return services.WriteReport(reportStdout, report, format)
+	}
+
+	if path == "" {
+		path = "build-report.json"
+		if format == services.ReportJUnit {
+			path = "build-report.xml"
+		}
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer f.Close()
+
+	if err := services.WriteReport(f, report, format); err != nil {
+		return err
+	}
+
+	fmt.Println(ui.FormatInfo("Report written to " + path))
+	return nil
+}
+
+// openFirstError opens the editor at the first error that maps back to a note
+func openFirstError(parsed *latexparser.ParseResult) {
+	for _, issue := range parsed.Errors {
+		if issue.File == "" || issue.Line <= 0 || filepath.IsAbs(issue.File) {
+			continue
+		}
+
+		// Mapped issues are relative to the vault root (notes/<filename>)
+		path := filepath.Join(appVault.RootPath, filepath.FromSlash(issue.File))
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+
+		fmt.Println(ui.FormatInfo(fmt.Sprintf("Opening %s...", issue.Location())))
+		if err := OpenEditorAtLine(path, issue.Line); err != nil {
+			fmt.Println(ui.FormatWarning("Failed to open editor: " + err.Error()))
+		}
+		return
+	}
+
+	fmt.Println(ui.FormatMuted("No error location found in the note"))
+}
+
 func runBuildTemplate(cmd *cobra.Command, args []string) error {
 	ctx := getContext()
 	useFuzzyFinder := len(args) == 0
@@ -386,7 +542,7 @@ Test document for template validation.
 	fmt.Println(ui.FormatRocket("Compiling template test..."))
 
 	// Use the compiler with detailed output
-	result := latexCompiler.CompileWithOutput(ctx, testFile, nil)
+	result := latexCompiler.CompileWithOutput(ctx, testFile, domain.CompileOptions{})
 
 	if !result.Success {
 		fmt.Println(ui.FormatError("Template compilation failed!"))
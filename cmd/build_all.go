@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -10,7 +11,13 @@ import (
 )
 
 var (
-	buildAllJobs int
+	buildAllJobs  int
+	buildAllForce bool
+
+	buildAllReport     string
+	buildAllReportFile string
+	buildAllTimeout    time.Duration
+	buildAllQuery      string
 )
 
 // buildAllCmd represents the build-all command
@@ -22,21 +29,50 @@ var buildAllCmd = &cobra.Command{
 This command uses a worker pool to compile multiple notes in parallel,
 dramatically reducing the total build time for large collections.
 
+Builds are incremental: a manifest in the cache records a hash of each
+note's source, templates, \input files, assets and linked note titles.
+Only notes whose hash changed (and the notes that depend on them) are
+rebuilt. Use --force to rebuild everything.
+
+Each note is killed if it exceeds the build timeout (build_timeout in
+the config, or --timeout). Timed-out notes are reported separately from
+failed ones. Press Ctrl+C to cancel all running builds.
+
+Use --query to build only the notes matching a query or saved search
+(see lx list --help); their dependencies are still checked.
+
+Use --report to write a JSON or JUnit report with the errors and warnings
+of every compiled note, e.g. for pre-push hooks and CI test viewers.
+
 Examples:
   lx build-all
-  lx build-all --jobs 8`,
+  lx build-all --jobs 8
+  lx build-all --force
+  lx build-all --timeout 1m
+  lx build-all --query @physics-open
+  lx build-all --report json --report-file build.json`,
 	RunE: runBuildAll,
 }
 
 func init() {
 	buildAllCmd.Flags().IntVarP(&buildAllJobs, "jobs", "j", 4, "Number of concurrent workers")
+	buildAllCmd.Flags().BoolVarP(&buildAllForce, "force", "f", false, "Rebuild all notes, even if up to date")
+	buildAllCmd.Flags().DurationVar(&buildAllTimeout, "timeout", 0, "Per-note build timeout (default from build_timeout config)")
+	buildAllCmd.Flags().StringVar(&buildAllReport, "report", "", "Write a build report (json, junit)")
+	buildAllCmd.Flags().StringVarP(&buildAllQuery, "query", "q", "", "Only build notes matching a query or @saved-search")
+	buildAllCmd.Flags().StringVar(&buildAllReportFile, "report-file", "", "Report destination (default build-report.json/.xml, - for stdout)")
 }
 
 func runBuildAll(cmd *cobra.Command, args []string) error {
 	ctx := getContext()
 
+	if err := validateReportFormat(buildAllReport); err != nil {
+		return err
+	}
+	defer redirectOutputForReport(buildAllReport, buildAllReportFile)()
+
 	// Get total count first
-	listReq := services.ListRequest{}
+	listReq := services.ListRequest{Query: buildAllQuery}
 	listResp, err := listService.Execute(ctx, listReq)
 	if err != nil {
 		fmt.Println(ui.FormatError("Failed to list notes"))
@@ -55,6 +91,10 @@ func runBuildAll(cmd *cobra.Command, args []string) error {
 	fmt.Println(ui.RenderKeyValue("Workers", fmt.Sprintf("%d", buildAllJobs)))
 	fmt.Println()
 
+	// Ctrl+C cancels every in-flight build through the context
+	ctx, stop := getInterruptibleContext()
+	defer stop()
+
 	// Create progress channel
 	progressChan := make(chan services.BuildProgress, listResp.Total)
 
@@ -65,6 +105,14 @@ func runBuildAll(cmd *cobra.Command, args []string) error {
 	go func() {
 		req := services.BuildAllRequest{
 			MaxWorkers: buildAllJobs,
+			Force:      buildAllForce,
+			Timeout:    buildAllTimeout,
+		}
+		if buildAllQuery != "" {
+			req.Slugs = make([]string, 0, listResp.Total)
+			for _, note := range listResp.Notes {
+				req.Slugs = append(req.Slugs, note.Slug)
+			}
 		}
 		resp, err := buildService.ExecuteAllWithProgress(ctx, req, progressChan)
 		if err != nil {
@@ -77,7 +125,9 @@ func runBuildAll(cmd *cobra.Command, args []string) error {
 	// Display progress
 	for progress := range progressChan {
 		status := ui.FormatSuccess("✓")
-		if !progress.Success {
+		if progress.TimedOut {
+			status = ui.FormatWarning("⏱")
+		} else if !progress.Success {
 			status = ui.FormatError("✗")
 		}
 
@@ -104,15 +154,39 @@ func runBuildAll(cmd *cobra.Command, args []string) error {
 		// Continue to show results
 	}
 
+	// Write the report once the build output is complete
+	defer func() {
+		if buildAllReport == "" {
+			return
+		}
+		if err := writeBuildReport(response.Results, response.Skipped, buildAllReport, buildAllReportFile); err != nil {
+			fmt.Println(ui.FormatWarning("Failed to write report: " + err.Error()))
+		}
+	}()
+
+	// Nothing was stale
+	if len(response.Results) == 0 {
+		fmt.Println(ui.FormatSuccess("All notes are up to date"))
+		fmt.Println(ui.FormatMuted("Use --force to rebuild everything"))
+		return nil
+	}
+
 	// Final newline after progress
 	fmt.Println()
 	fmt.Println()
 
 	// Show summary
-	fmt.Println(ui.FormatSuccess("Build completed!"))
+	if ctx.Err() != nil {
+		fmt.Println(ui.FormatWarning("Build cancelled"))
+	} else {
+		fmt.Println(ui.FormatSuccess("Build completed!"))
+	}
 	fmt.Println()
 	fmt.Println(ui.RenderKeyValue("Total", fmt.Sprintf("%d", response.Total)))
 	fmt.Println(ui.RenderKeyValue("Succeeded", ui.StyleSuccess.Render(fmt.Sprintf("%d", response.Succeeded))))
+	if response.Skipped > 0 {
+		fmt.Println(ui.RenderKeyValue("Up to date", fmt.Sprintf("%d", response.Skipped)))
+	}
 	if response.Failed > 0 {
 		fmt.Println(ui.RenderKeyValue("Failed", ui.StyleError.Render(fmt.Sprintf("%d", response.Failed))))
 		fmt.Println()
@@ -120,12 +194,31 @@ func runBuildAll(cmd *cobra.Command, args []string) error {
 		// Show failed builds
 		fmt.Println(ui.FormatWarning("Failed builds:"))
 		for _, result := range response.Results {
-			if !result.Success {
+			if !result.Success && !result.TimedOut {
 				fmt.Println(ui.FormatMuted("  • " + result.Slug + ": " + result.Error.Error()))
 			}
 		}
 	}
+	if response.TimedOut > 0 {
+		fmt.Println(ui.RenderKeyValue("Timed out", ui.StyleWarning.Render(fmt.Sprintf("%d", response.TimedOut))))
+		fmt.Println()
+
+		// Show timed-out builds
+		fmt.Println(ui.FormatWarning("Timed out (possibly stuck in a loop):"))
+		for _, result := range response.Results {
+			if result.TimedOut {
+				fmt.Println(ui.FormatMuted("  • " + result.Slug))
+			}
+		}
+	}
 
+	// Exit non-zero, so hooks and CI can use build-all as a gate
+	if response.Failed > 0 || response.TimedOut > 0 {
+		return fmt.Errorf("%d note(s) failed, %d timed out", response.Failed, response.TimedOut)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("build cancelled")
+	}
 	return nil
 }
 
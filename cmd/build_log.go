@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	buildLogLimit  int
+	buildLogLatest bool
+)
+
+var buildLogCmd = &cobra.Command{
+	Use:   "build-log [note] [build-id]",
+	Short: "Show the build history",
+	Long: `Show recent builds with their engine, duration and outcome.
+
+Without arguments, the most recent builds across the vault are listed.
+With a note, only that note's builds are listed. Pass a build ID (or
+--latest) to show the parsed errors and warnings and the full compiler log.
+
+The number of builds kept per note is set by build_history in the config.`,
+	Example: `  lx build-log
+  lx build-log graph-theory
+  lx build-log graph-theory --latest
+  lx build-log graph-theory 20250101-120000.000000`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runBuildLog,
+}
+
+func init() {
+	buildLogCmd.Flags().IntVarP(&buildLogLimit, "limit", "n", 10, "Number of builds to list")
+	buildLogCmd.Flags().BoolVar(&buildLogLatest, "latest", false, "Show the details of the most recent build")
+}
+
+func runBuildLog(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	// 1. Resolve the note, if any
+	slug := ""
+	if len(args) > 0 {
+		slug = resolveBuildLogNote(args[0])
+	}
+
+	// 2. Show a single build
+	if len(args) == 2 || buildLogLatest {
+		var id string
+		if len(args) == 2 {
+			id = args[1]
+		} else {
+			records, err := buildLogRepo.List(ctx, slug, 1)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Println(ui.FormatInfo("No builds recorded yet"))
+				return nil
+			}
+			slug, id = records[0].Slug, records[0].ID
+		}
+
+		if slug == "" {
+			return fmt.Errorf("a note is required to show a build")
+		}
+
+		record, err := buildLogRepo.Get(ctx, slug, id)
+		if err != nil {
+			return err
+		}
+		printBuildRecord(record)
+		return nil
+	}
+
+	// 3. List recent builds
+	records, err := buildLogRepo.List(ctx, slug, buildLogLimit)
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 {
+		fmt.Println(ui.FormatInfo("No builds recorded yet"))
+		return nil
+	}
+
+	for _, record := range records {
+		fmt.Println(formatBuildRecordLine(record))
+	}
+
+	fmt.Println()
+	fmt.Println(ui.FormatMuted("Show a build with: lx build-log <note> <build-id>"))
+
+	return nil
+}
+
+// resolveBuildLogNote turns a query into a slug
+// Notes that were deleted since still have history, so the literal argument is the fallback
+func resolveBuildLogNote(arg string) string {
+	if slug, err := resolveSynctexNote(arg); err == nil {
+		return slug
+	}
+	return arg
+}
+
+// formatBuildRecordLine renders one build as a single list line
+func formatBuildRecordLine(record domain.BuildRecord) string {
+	icon := ui.StyleSuccess.Render("✓")
+	switch {
+	case record.TimedOut:
+		icon = ui.StyleWarning.Render(ui.IconWarning)
+	case !record.Success:
+		icon = ui.StyleError.Render("✗")
+	}
+
+	counts := fmt.Sprintf("%d error(s), %d warning(s)", len(record.Errors), len(record.Warnings))
+
+	return fmt.Sprintf("%s %s  %-30s %-20s %8s  %s  %s",
+		icon,
+		record.Timestamp.Format("2006-01-02 15:04:05"),
+		record.Slug,
+		record.Engine,
+		formatBuildDuration(record.Duration),
+		counts,
+		ui.FormatMuted(record.ID),
+	)
+}
+
+// printBuildRecord shows the details and raw log of a single build
+func printBuildRecord(record *domain.BuildRecord) {
+	status := ui.StyleSuccess.Render("success")
+	switch {
+	case record.TimedOut:
+		status = ui.StyleWarning.Render("timed out")
+	case !record.Success:
+		status = ui.StyleError.Render("failed")
+	}
+
+	fmt.Println(ui.RenderKeyValue("Note", record.Slug))
+	fmt.Println(ui.RenderKeyValue("Build", record.ID))
+	fmt.Println(ui.RenderKeyValue("Time", record.Timestamp.Format("2006-01-02 15:04:05")))
+	fmt.Println(ui.RenderKeyValue("Engine", record.Engine))
+	fmt.Println(ui.RenderKeyValue("Duration", formatBuildDuration(record.Duration)))
+	fmt.Println(ui.RenderKeyValue("Status", status))
+
+	parsed := &latexparser.ParseResult{Errors: record.Errors, Warnings: record.Warnings}
+	fmt.Print(parsed.FormatIssues())
+
+	if strings.TrimSpace(record.Output) != "" {
+		fmt.Println()
+		fmt.Println(ui.FormatTitle("Compiler Log"))
+		fmt.Println(record.Output)
+	}
+}
+
+// formatBuildDuration rounds a duration for display
+func formatBuildDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
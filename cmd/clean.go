@@ -150,6 +150,10 @@ func runPruneAssets() error {
 	count := 0
 	for _, f := range candidates {
 		path := appVault.GetAssetPath(f)
+		if err := journal.Capture(path); err != nil {
+			fmt.Println(ui.FormatWarning(err.Error()))
+			continue
+		}
 		if err := os.Remove(path); err == nil {
 			assetRepo.Delete(ctx, f) // Update manifest
 			count++
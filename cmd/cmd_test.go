@@ -11,10 +11,11 @@ import (
 // TestCommandStructure verifies that all commands are properly registered
 func TestCommandStructure(t *testing.T) {
 	commands := []string{
-		"new", "list", "open", "edit", "delete", "build", "build-all",
-		"init", "version", "git", "clone", "sync", "rename", "doctor",
-		"stats", "clean", "config", "tag", "graph", "grep", "daily",
+		"new", "list", "open", "edit", "delete", "build", "build-all", "build-log",
+		"init", "version", "git", "clone", "sync", "rename", "move", "doctor",
+		"stats", "clean", "config", "tag", "graph", "grep", "search", "saved", "related", "daily",
 		"links", "explore", "export", "attach", "watch", "todo", "reindex",
+		"synctex", "vault", "trash", "undo", "history",
 	}
 
 	for _, cmdName := range commands {
@@ -175,7 +176,7 @@ func TestCommandAliases(t *testing.T) {
 		{"g", "grep", true},
 		{"gg", "graph", true},
 		{"s", "sync", true},
-		{"mv", "rename", true},
+		{"mv", "move", true},
 		{"t", "tag", true},
 		{"w", "watch", true},
 		{"cl", "clean", true},
@@ -222,7 +223,7 @@ func TestAliasesInHelpText(t *testing.T) {
 		{"grep", []string{"g"}},
 		{"graph", []string{"gg"}},
 		{"sync", []string{"s"}},
-		{"rename", []string{"mv"}},
+		{"move", []string{"mv"}},
 		{"tag", []string{"t"}},
 		{"watch", []string{"w"}},
 		{"clean", []string{"cl"}},
@@ -283,7 +284,7 @@ func TestAllAliasesResolveToCorrectCommand(t *testing.T) {
 		"g":      "grep",
 		"gg":     "graph",
 		"s":      "sync",
-		"mv":     "rename",
+		"mv":     "move",
 		"t":      "tag",
 		"w":      "watch",
 		"cl":     "clean",
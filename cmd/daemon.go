@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"io/fs"
 	"log"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -28,7 +31,7 @@ This command monitors the notes directory for:
   - Existing .tex files modified
   - .tex files deleted
 
-When changes are detected, it automatically rebuilds the index to keep
+When changes are detected, only the changed notes are reindexed, keeping
 connections, backlinks, and metadata up-to-date.
 
 Use --quiet to suppress reindex notifications.`,
@@ -49,8 +52,8 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	}
 	defer watcher.Close()
 
-	// Watch the notes directory
-	if err := watcher.Add(appVault.NotesPath); err != nil {
+	// Watch the notes directory and its notebooks
+	if err := watchNotebooks(watcher, appVault.NotesPath); err != nil {
 		return fmt.Errorf("failed to watch notes directory: %w", err)
 	}
 
@@ -64,21 +67,22 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 	// Debounce timer to avoid excessive reindexing
 	var debounceTimer *time.Timer
 	debounceDuration := 500 * time.Millisecond
-	needsReindex := false
+	changes := newChangeSet()
 
 	// Function to perform reindex
 	doReindex := func() {
-		if !needsReindex {
+		paths := changes.drain()
+		if len(paths) == 0 {
 			return
 		}
-		needsReindex = false
 
 		if !daemonQuiet {
 			fmt.Println(ui.FormatInfo("File changes detected, reindexing..."))
 		}
 
 		indexerService := services.NewIndexerService(noteRepo, appVault.IndexPath())
-		req := services.ReindexRequest{}
+		indexerService.SetVaultName(appVault.Name)
+		req := services.ReindexRequest{Paths: reindexPaths(paths)}
 		resp, err := indexerService.Execute(ctx, req)
 		if err != nil {
 			if !daemonQuiet {
@@ -102,8 +106,11 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 				return nil
 			}
 
-			// Only care about .tex files
-			if !strings.HasSuffix(event.Name, ".tex") {
+			// Notebooks come and go with all their notes
+			notebookChanged := notebookEvent(watcher, event)
+
+			// Otherwise only care about .tex files
+			if !notebookChanged && !strings.HasSuffix(event.Name, ".tex") {
 				continue
 			}
 
@@ -114,12 +121,13 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 			}
 
 			// Check if it's a create, write, remove, or rename event
-			if event.Has(fsnotify.Create) ||
+			if notebookChanged ||
+				event.Has(fsnotify.Create) ||
 				event.Has(fsnotify.Write) ||
 				event.Has(fsnotify.Remove) ||
 				event.Has(fsnotify.Rename) {
 
-				needsReindex = true
+				changes.add(event.Name)
 
 				// Reset debounce timer
 				if debounceTimer != nil {
@@ -143,3 +151,83 @@ func runDaemon(cmd *cobra.Command, args []string) error {
 		}
 	}
 }
+
+// watchNotebooks watches a directory and the notebooks inside it
+func watchNotebooks(watcher *fsnotify.Watcher, root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// notebookEvent reports whether an event added or removed a notebook
+// New notebooks are watched from then on
+func notebookEvent(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
+	if filepath.Ext(event.Name) != "" || strings.HasPrefix(filepath.Base(event.Name), ".") {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		info, err := os.Stat(event.Name)
+		if err != nil || !info.IsDir() {
+			return false
+		}
+		if err := watchNotebooks(watcher, event.Name); err != nil {
+			log.Printf("Watcher error: %v", err)
+		}
+		return true
+	}
+
+	// A removed or renamed notebook takes its notes with it
+	return event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)
+}
+
+// reindexPaths returns the changed files to reindex, or nil to check every note
+// Changes inside notebooks can change the slugs of notes elsewhere, so they check every note
+func reindexPaths(paths []string) []string {
+	for _, path := range paths {
+		if filepath.Ext(path) != ".tex" || filepath.Dir(path) != filepath.Clean(appVault.NotesPath) {
+			return nil
+		}
+	}
+	return paths
+}
+
+// changeSet collects the note files changed since the last reindex
+// Events arrive on the watcher goroutine while reindexing runs on the debounce timer
+type changeSet struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+func newChangeSet() *changeSet {
+	return &changeSet{paths: make(map[string]bool)}
+}
+
+// add records a changed file
+func (c *changeSet) add(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paths[path] = true
+}
+
+// drain returns the changed files and resets the set
+func (c *changeSet) drain() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	paths := make([]string, 0, len(c.paths))
+	for path := range c.paths {
+		paths = append(paths, path)
+	}
+	c.paths = make(map[string]bool)
+	return paths
+}
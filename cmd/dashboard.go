@@ -82,6 +82,7 @@ func runDashboard(cmd *cobra.Command, args []string) error {
 
 	// Initialize dashboard model
 	m := newDashboardModel(ctx, listResp.Notes)
+	m.collections = loadCollections(ctx)
 
 	// Run the TUI
 	p := tea.NewProgram(
@@ -112,9 +113,13 @@ const (
 type previewState struct {
 	content  string
 	slug     string
+	related  []services.RelatedNote
 	viewport viewport.Model
 }
 
+// previewRelated is the number of related notes shown in the preview
+const previewRelated = 3
+
 // Dashboard model
 type dashboardModel struct {
 	ctx           context.Context
@@ -137,6 +142,24 @@ type dashboardModel struct {
 	graphCursor   int
 	graphHistory  []string
 	preview       previewState
+	collections   []collection // Notebooks and saved searches shown in the sidebar
+	collection    int          // Selected collection: 0 is all notes, i is collections[i-1]
+}
+
+// collection is a notebook or a saved search shown as a live list of notes
+type collection struct {
+	name     string
+	query    string
+	notebook string // Set for notebooks, which list their nested notebooks' notes too
+	count    int    // -1 when the query is invalid
+}
+
+// request lists the notes of the collection
+func (c collection) request() services.ListRequest {
+	if c.notebook != "" {
+		return services.ListRequest{Notebook: c.notebook}
+	}
+	return services.ListRequest{Query: "@" + c.name}
 }
 
 // Key bindings
@@ -158,6 +181,9 @@ type keyMap struct {
 	Confirm key.Binding
 	Cancel  key.Binding
 	Preview key.Binding
+
+	PrevCollection key.Binding
+	NextCollection key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
@@ -168,7 +194,7 @@ func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Top, k.Bottom},
 		{k.Open, k.Edit, k.Build, k.Delete, k.New},
-		{k.Search, k.Preview, k.Graph, k.Help, k.Escape, k.Quit},
+		{k.Search, k.PrevCollection, k.NextCollection, k.Preview, k.Graph, k.Help, k.Escape, k.Quit},
 	}
 }
 
@@ -241,6 +267,14 @@ var keys = keyMap{
 		key.WithKeys(""),
 		key.WithHelp("", ""),
 	),
+	PrevCollection: key.NewBinding(
+		key.WithKeys("["),
+		key.WithHelp("[", "previous collection"),
+	),
+	NextCollection: key.NewBinding(
+		key.WithKeys("]"),
+		key.WithHelp("]", "next collection"),
+	),
 }
 
 func newDashboardModel(ctx context.Context, notes []domain.NoteHeader) dashboardModel {
@@ -289,7 +323,7 @@ func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Update preview viewport size
 		previewWidth := (msg.Width / 2) - 4
-		previewHeight := msg.Height - 16
+		previewHeight := msg.Height - 17 - previewRelated
 		if previewHeight < 10 {
 			previewHeight = 10
 		}
@@ -332,6 +366,7 @@ func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		})
 		if err == nil {
 			m.notes = listResp.Notes
+			m.collections = loadCollections(m.ctx)
 			m.applySearch()
 			// Reload preview
 			if len(m.filteredNotes) > 0 {
@@ -343,6 +378,7 @@ func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case previewLoadedMsg:
 		m.preview.content = msg.content
 		m.preview.slug = msg.slug
+		m.preview.related = msg.related
 		m.preview.viewport.SetContent(msg.content)
 		m.preview.viewport.GotoTop()
 		return m, nil
@@ -442,6 +478,22 @@ func (m dashboardModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case key.Matches(msg, m.keys.Graph):
 		return m, m.loadGraph()
 
+	case key.Matches(msg, m.keys.PrevCollection), key.Matches(msg, m.keys.NextCollection):
+		if len(m.collections) == 0 {
+			break
+		}
+		step := 1
+		if key.Matches(msg, m.keys.PrevCollection) {
+			step = len(m.collections)
+		}
+		m.collection = (m.collection + step) % (len(m.collections) + 1)
+		m.cursor = 0
+		m.offset = 0
+		m.applySearch()
+		if len(m.filteredNotes) > 0 {
+			return m, m.loadPreview(m.filteredNotes[m.cursor])
+		}
+
 	case key.Matches(msg, m.keys.Help):
 		m.mode = modeHelp
 	}
@@ -651,6 +703,13 @@ func (m dashboardModel) viewListWithPreview() string {
 	s.WriteString(searchBar)
 	s.WriteString("\n\n")
 
+	// Saved searches get a sidebar, taken from the list's share of the width
+	var sidebarLines []string
+	if len(m.collections) > 0 {
+		sidebarLines = strings.Split(m.renderCollections(collectionsWidth), "\n")
+		listWidth -= collectionsWidth + 2
+	}
+
 	// Render list and preview side by side
 	listContent := m.renderNotesListForSplit(listWidth)
 	previewContent := m.renderPreview(previewWidth)
@@ -659,14 +718,20 @@ func (m dashboardModel) viewListWithPreview() string {
 	listLines := strings.Split(listContent, "\n")
 	previewLines := strings.Split(previewContent, "\n")
 
-	maxLines := len(listLines)
-	if len(previewLines) > maxLines {
-		maxLines = len(previewLines)
-	}
+	maxLines := max(len(listLines), len(previewLines), len(sidebarLines))
 
 	for i := 0; i < maxLines; i++ {
 		var listLine, previewLine string
 
+		if len(sidebarLines) > 0 {
+			sidebarLine := ""
+			if i < len(sidebarLines) {
+				sidebarLine = sidebarLines[i]
+			}
+			s.WriteString(padRight(sidebarLine, collectionsWidth))
+			s.WriteString("  ")
+		}
+
 		if i < len(listLines) {
 			listLine = listLines[i]
 		}
@@ -742,6 +807,8 @@ func (m dashboardModel) viewHelp() string {
 			title: "Views & Search",
 			keys: []struct{ key, desc string }{
 				{"/", "Start search (type to filter, arrow keys to navigate)"},
+				{"", "Filters work too: tag:math -tag:draft has:todo date:>=2025-09"},
+				{"[ / ]", "Previous / next notebook or saved search"},
 				{"Esc", "Exit search / Cancel"},
 				{"v", "Toggle graph view"},
 				{"?", "Show this help"},
@@ -751,6 +818,7 @@ func (m dashboardModel) viewHelp() string {
 			title: "Preview",
 			keys: []struct{ key, desc string }{
 				{"PgUp/PgDn", "Scroll preview pane"},
+				{"Related", "Similar notes; + marks ones not linked yet (lx related)"},
 			},
 		},
 		{
@@ -807,7 +875,7 @@ func (m dashboardModel) viewConfirmDelete() string {
 		MarginTop(1)
 
 	content := fmt.Sprintf("%s\n\n%s\n%s\n\n%s",
-		titleStyle.Render("⚠️  Delete Note?"),
+		titleStyle.Render("⚠️  Move Note to Trash?"),
 		noteStyle.Render(m.deleteTarget.Title),
 		ui.StyleMuted.Render(m.deleteTarget.Slug),
 		promptStyle.Render("Press 'y' to confirm, 'n' or ESC to cancel"),
@@ -1075,6 +1143,90 @@ func (m dashboardModel) renderFooter() string {
 	return footerStyle.Render(content)
 }
 
+// collectionsWidth is the width of the notebook and saved search sidebar
+const collectionsWidth = 24
+
+// renderCollections renders the sidebar: all notes, then notebooks, then saved searches
+func (m dashboardModel) renderCollections(width int) string {
+	var s strings.Builder
+	s.WriteString(ui.StyleMuted.Render("Collections") + "\n")
+
+	entries := []collection{{name: "All notes", count: len(m.notes)}}
+	for _, c := range m.collections {
+		if c.notebook != "" {
+			// Nested notebooks are indented under their parent
+			depth := strings.Count(c.notebook, domain.NotebookSeparator)
+			c.name = strings.Repeat(" ", depth) + c.name[strings.LastIndex(c.name, domain.NotebookSeparator)+1:] + "/"
+		} else {
+			c.name = "@" + c.name
+		}
+		entries = append(entries, c)
+	}
+
+	for i, c := range entries {
+		count := fmt.Sprintf("%d", c.count)
+		if c.count < 0 {
+			count = "!"
+		}
+
+		name := c.name
+		if maxName := width - len(count) - 3; len(name) > maxName {
+			name = name[:max(maxName-1, 1)] + "…"
+		}
+
+		cursor := "  "
+		nameStyle := lipgloss.NewStyle().Foreground(ui.ColorDefault)
+		if i == m.collection {
+			cursor = ui.StylePrimary.Render("▶ ")
+			nameStyle = ui.StylePrimary.Copy().Bold(true)
+		}
+
+		line := cursor + nameStyle.Render(name)
+		line = padRight(line, width-len(count)) + ui.StyleMuted.Render(count)
+		s.WriteString(line + "\n")
+	}
+
+	s.WriteString("\n" + ui.StyleMuted.Render("[ ] switch"))
+	return s.String()
+}
+
+// loadCollections lists the notebooks and evaluates the saved searches for the sidebar
+func loadCollections(ctx context.Context) []collection {
+	var collections []collection
+
+	// Notebooks, with every level of nested ones, in path order
+	if headers, err := noteRepo.ListHeaders(ctx); err == nil {
+		counts := make(map[string]int)
+		for _, header := range headers {
+			notebook := header.Notebook
+			for notebook != "" {
+				counts[notebook]++
+				notebook = domain.NotebookOf(notebook)
+			}
+		}
+		notebooks := make([]string, 0, len(counts))
+		for notebook := range counts {
+			notebooks = append(notebooks, notebook)
+		}
+		sort.Strings(notebooks)
+		for _, notebook := range notebooks {
+			collections = append(collections, collection{name: notebook, notebook: notebook, count: counts[notebook]})
+		}
+	}
+
+	if appConfig == nil {
+		return collections
+	}
+	for _, name := range savedSearchNames() {
+		c := collection{name: name, query: appConfig.SavedSearches[name], count: -1}
+		if resp, err := listService.Execute(ctx, c.request()); err == nil {
+			c.count = resp.Total
+		}
+		collections = append(collections, c)
+	}
+	return collections
+}
+
 func (m dashboardModel) renderNotesListForSplit(width int) string {
 	var s strings.Builder
 
@@ -1197,7 +1349,11 @@ func (m dashboardModel) renderPreview(width int) string {
 		s.WriteString(titleStyle.Render(note.Title))
 		s.WriteString("\n")
 
-		// Tags
+		// Notebook and tags
+		var details []string
+		if note.Notebook != "" {
+			details = append(details, ui.StyleMuted.Render("In "+note.Notebook+"/"))
+		}
 		if len(note.Tags) > 0 {
 			tagStyle := lipgloss.NewStyle().Foreground(ui.ColorAccent)
 			tags := "Tags: "
@@ -1207,10 +1363,15 @@ func (m dashboardModel) renderPreview(width int) string {
 				}
 				tags += tag
 			}
-			s.WriteString(tagStyle.Render(tags))
+			details = append(details, tagStyle.Render(tags))
+		}
+		if len(details) > 0 {
+			s.WriteString(strings.Join(details, ui.StyleMuted.Render(" • ")))
 			s.WriteString("\n")
 		}
 		s.WriteString("\n")
+
+		s.WriteString(m.renderRelated(width - 4))
 	}
 
 	// Scrollable content with syntax highlighting
@@ -1223,6 +1384,33 @@ func (m dashboardModel) renderPreview(width int) string {
 	return borderStyle.Render(s.String())
 }
 
+// renderRelated renders the related notes pane of the preview
+// Unlinked notes are marked, as they are candidates for a new \lxnote
+func (m dashboardModel) renderRelated(width int) string {
+	var s strings.Builder
+	s.WriteString(ui.StyleMuted.Render("Related") + "\n")
+
+	if len(m.preview.related) == 0 {
+		s.WriteString(ui.StyleMuted.Render("  none") + "\n")
+	}
+	for _, r := range m.preview.related {
+		marker := "  "
+		if !r.Linked {
+			marker = ui.StyleAccent.Render("+ ")
+		}
+		line := marker + truncate(r.Title, max(width-9, 4))
+		s.WriteString(padRight(line, width-5) + ui.StyleMuted.Render(fmt.Sprintf("%.2f", r.Score)) + "\n")
+	}
+
+	// Keep the pane a fixed height so the preview does not jump
+	for i := max(len(m.preview.related), 1); i < previewRelated; i++ {
+		s.WriteString("\n")
+	}
+	s.WriteString("\n")
+
+	return s.String()
+}
+
 func padRight(s string, width int) string {
 	// Strip ANSI codes to get real length
 	realLen := lipgloss.Width(s)
@@ -1250,13 +1438,25 @@ func (m *dashboardModel) adjustViewport() {
 }
 
 func (m *dashboardModel) applySearch() {
+	// The selected collection narrows the notes the search runs over
+	notes := m.notes
+	if m.collection > 0 && m.collection <= len(m.collections) {
+		notes = nil
+		req := m.collections[m.collection-1].request()
+		req.SortBy, req.Reverse = "date", true
+		resp, err := listService.Execute(m.ctx, req)
+		if err == nil {
+			notes = resp.Notes
+		}
+	}
+
 	query := strings.TrimSpace(m.searchInput.Value())
 	if query == "" {
-		m.filteredNotes = m.notes
+		m.filteredNotes = notes
 	} else {
 		resp, err := listService.Search(m.ctx, services.SearchRequest{Query: query})
 		if err == nil {
-			m.filteredNotes = resp.Notes
+			m.filteredNotes = intersectNotes(resp.Notes, notes)
 		}
 	}
 
@@ -1270,6 +1470,22 @@ func (m *dashboardModel) applySearch() {
 	m.adjustViewport()
 }
 
+// intersectNotes keeps the notes of matches that are also in notes, in match order
+func intersectNotes(matches, notes []domain.NoteHeader) []domain.NoteHeader {
+	present := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		present[note.Slug] = true
+	}
+
+	var kept []domain.NoteHeader
+	for _, note := range matches {
+		if present[note.Slug] {
+			kept = append(kept, note)
+		}
+	}
+	return kept
+}
+
 func (m dashboardModel) formatRelativeTime(dateStr string) string {
 	t, err := time.Parse("2006-01-02", dateStr)
 	if err != nil {
@@ -1320,6 +1536,7 @@ type reloadNotesMsg struct{}
 type previewLoadedMsg struct {
 	slug    string
 	content string
+	related []services.RelatedNote
 }
 
 func (m dashboardModel) openNote(note domain.NoteHeader) tea.Cmd {
@@ -1413,9 +1630,13 @@ func (m dashboardModel) deleteNoteConfirmed(note *domain.NoteHeader) tea.Cmd {
 			return nil
 		}
 
-		// Delete note file
-		notePath := appVault.GetNotePath(note.Filename)
-		if err := os.Remove(notePath); err != nil {
+		// Move the note to the trash, with the assets only it used
+		// Each deletion is an operation of its own, for lx undo
+		journal.Begin("lx dashboard delete", []string{note.Slug})
+		defer journal.Commit()
+		ctx := context.Background()
+		item, err := trashService.Delete(ctx, note.Slug, trashService.OrphanedAssets(ctx, note.Slug))
+		if item == nil {
 			return statusMsg{
 				message: fmt.Sprintf("Failed to delete: %v", err),
 				style:   ui.StyleError,
@@ -1436,7 +1657,7 @@ func (m dashboardModel) deleteNoteConfirmed(note *domain.NoteHeader) tea.Cmd {
 		return tea.Sequence(
 			func() tea.Msg {
 				return statusMsg{
-					message: fmt.Sprintf("✓ Deleted: %s", note.Title),
+					message: fmt.Sprintf("✓ Moved to trash: %s (lx trash restore %s)", note.Title, note.Slug),
 					style:   ui.StyleSuccess,
 				}
 			},
@@ -1491,9 +1712,16 @@ func (m dashboardModel) loadPreview(note domain.NoteHeader) tea.Cmd {
 		// Apply syntax highlighting for LaTeX
 		highlighted := highlightLatex(content)
 
+		// Related notes come from the index; a stale or missing index just shows none
+		var related []services.RelatedNote
+		if relatedService != nil {
+			related, _ = relatedService.Related(m.ctx, services.RelatedRequest{Slug: note.Slug, Limit: previewRelated})
+		}
+
 		return previewLoadedMsg{
 			slug:    note.Slug,
 			content: highlighted,
+			related: related,
 		}
 	}
 }
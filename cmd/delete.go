@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"slices"
 	"strconv"
 	"strings"
 
@@ -26,8 +25,9 @@ var deleteCmd = &cobra.Command{
 	Short:   "Delete a note or template (and orphaned assets) (aliases: d, rm)",
 	Long: `Delete a note or template.
 
-If deleting a note, it checks if any attached assets (images/PDFs)
-become "orphaned" (not used by any other note) and offers to delete them.
+Deleted notes are moved to the trash, from where lx trash restore brings
+them back. It also checks if any attached assets (images/PDFs) become
+"orphaned" (not used by any other note) and offers to trash them too.
 
 Examples:
   lx delete graph
@@ -174,36 +174,8 @@ func runDeleteNote(cmd *cobra.Command, args []string) error {
 	}
 
 	// 2. Identify Orphaned Assets
-	// We need the index to know what assets this note uses, and if anyone else uses them
-	index, _ := indexerService.LoadIndex() // Ignore error, best effort
-
-	var orphans []string
-	if index != nil {
-		if entry, exists := index.GetNote(selectedNote.Slug); exists {
-			// Check each asset used by this note
-			for _, asset := range entry.Assets {
-				isUsedElsewhere := false
-
-				// Scan all other notes
-				for otherSlug, otherEntry := range index.Notes {
-					if otherSlug == selectedNote.Slug {
-						continue
-					}
-
-					if slices.Contains(otherEntry.Assets, asset) {
-						isUsedElsewhere = true
-					}
-					if isUsedElsewhere {
-						break
-					}
-				}
-
-				if !isUsedElsewhere {
-					orphans = append(orphans, asset)
-				}
-			}
-		}
-	}
+	// The index tells what assets this note uses, and if anyone else uses them
+	orphans := trashService.OrphanedAssets(ctx, selectedNote.Slug)
 
 	// 3. Confirmation
 	fmt.Println(ui.FormatWarning("You are about to delete:"))
@@ -219,44 +191,38 @@ func runDeleteNote(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print(ui.StyleError.Render("Delete note? (y/n): "))
+	fmt.Print(ui.StyleError.Render("Move note to the trash? (y/n): "))
 	response, err := reader.ReadString('\n')
 	if err != nil || strings.ToLower(strings.TrimSpace(response)) != "y" {
 		fmt.Println("Cancelled.")
 		return nil
 	}
 
-	// 4. Delete Note
-	if err := noteRepo.Delete(ctx, selectedNote.Slug); err != nil {
-		return err
-	}
-	fmt.Println(ui.FormatSuccess("Note deleted."))
-
-	// 5. Delete Orphans (Optional)
+	// 4. Ask about Orphans
+	var trashedAssets []string
 	if len(orphans) > 0 {
-		fmt.Print(ui.StyleWarning.Render(fmt.Sprintf("Delete %d orphaned assets? (y/n): ", len(orphans))))
+		fmt.Print(ui.StyleWarning.Render(fmt.Sprintf("Move %d orphaned assets to the trash with it? (y/n): ", len(orphans))))
 		assetResponse, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Println(ui.FormatMuted("Assets kept."))
-			return nil
+		if err == nil && strings.ToLower(strings.TrimSpace(assetResponse)) == "y" {
+			trashedAssets = orphans
 		}
+	}
 
-		if strings.ToLower(strings.TrimSpace(assetResponse)) == "y" {
-			count := 0
-			for _, filename := range orphans {
-				// Delete from disk
-				path := appVault.GetAssetPath(filename)
-				if err := os.Remove(path); err == nil {
-					// Delete from manifest
-					assetRepo.Delete(ctx, filename)
-					count++
-				}
-			}
-			fmt.Println(ui.FormatSuccess(fmt.Sprintf("Cleaned up %d assets.", count)))
-		} else {
-			fmt.Println(ui.FormatMuted("Assets kept."))
-		}
+	// 5. Move to Trash
+	item, err := trashService.Delete(ctx, selectedNote.Slug, trashedAssets)
+	if item == nil {
+		return err
 	}
+	fmt.Println(ui.FormatSuccess("Note moved to the trash."))
+	if len(item.Assets) > 0 {
+		fmt.Println(ui.FormatSuccess(fmt.Sprintf("Moved %d assets with it.", len(item.Assets))))
+	} else if len(orphans) > 0 {
+		fmt.Println(ui.FormatMuted("Assets kept."))
+	}
+	if err != nil {
+		fmt.Println(ui.FormatWarning(err.Error()))
+	}
+	fmt.Println(ui.FormatMuted("Restore it with: lx trash restore " + selectedNote.Slug))
 
 	return nil
 }
@@ -380,6 +346,9 @@ func runDeleteTemplate(cmd *cobra.Command, args []string) error {
 	}
 
 	// 5. Delete File
+	if err := journal.Capture(selected.Path); err != nil {
+		return err
+	}
 	if err := os.Remove(selected.Path); err != nil {
 		return fmt.Errorf("failed to delete template: %w", err)
 	}
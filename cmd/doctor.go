@@ -5,8 +5,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 
 	"github.com/spf13/cobra"
@@ -21,6 +25,7 @@ Checks for:
   - Vault directory integrity (including assets)
   - Configuration file existence
   - Required tools (latexmk, pandoc, git)
+  - Files sharing a slug
   - Broken links`,
 	Run: runDoctor,
 }
@@ -101,6 +106,29 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	fmt.Println()
 	fmt.Println(ui.FormatInfo("Checking content integrity..."))
 
+	// Check for files that map to the same slug; only one of them can be opened
+	checkStep("Unique Slugs", func() error {
+		duplicates, err := noteRepo.Duplicates()
+		if err != nil {
+			return err
+		}
+		if len(duplicates) == 0 {
+			return nil
+		}
+
+		slugs := make([]string, 0, len(duplicates))
+		for slug := range duplicates {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+
+		fmt.Println()
+		for _, slug := range slugs {
+			fmt.Printf("    %s <- %s\n", slug, strings.Join(duplicates[slug], ", "))
+		}
+		return fmt.Errorf("found %d slugs used by several files; rename the files apart", len(duplicates))
+	})
+
 	// Check for broken links
 	checkStep("Link Integrity", func() error {
 		headers, _ := noteRepo.ListHeaders(getContext())
@@ -109,37 +137,49 @@ func runDoctor(cmd *cobra.Command, args []string) {
 			slugMap[h.Slug] = true
 		}
 
-		brokenCount := 0
-		// Check both \lxnote{} (new) and \ref{} (deprecated)
-		lxnoteRegex := regexp.MustCompile(`\\lxnote\{([^}]+)\}`)
-		refRegex := regexp.MustCompile(`\\ref\{([^}]+)\}`)
-
+		// Read every note once; labels are needed to check \lxnote{slug#label} anchors
+		contents := make(map[string]string)
+		labels := make(map[string]map[string]bool)
 		for _, h := range headers {
 			content, _ := os.ReadFile(appVault.GetNotePath(h.Filename))
-			contentStr := string(content)
-
-			// Check \lxnote{} references
-			lxnoteMatches := lxnoteRegex.FindAllStringSubmatch(contentStr, -1)
-			for _, m := range lxnoteMatches {
-				targetSlug := m[1]
-				if !slugMap[targetSlug] {
-					if brokenCount == 0 {
-						fmt.Println()
-					}
-					fmt.Printf("    %s -> %s (Missing) [\\lxnote]\n", h.Slug, targetSlug)
-					brokenCount++
-				}
+			contents[h.Slug] = string(content)
+			labels[h.Slug] = make(map[string]bool)
+			for _, label := range domain.ExtractLabels(string(content)) {
+				labels[h.Slug][label] = true
 			}
+		}
+
+		otherVaults := make(map[string]ports.Repository)
+		brokenCount := 0
+		for _, h := range headers {
+			for _, link := range domain.NoteLinks(h, contents[h.Slug]) {
+				// \ref, \cite and \input also name LaTeX labels, bibliography keys and
+				// files, so only links that can only mean a note are checked
+				if link.Kind != domain.LinkNote && link.Kind != domain.LinkEmbed && link.Kind != domain.LinkTag {
+					continue
+				}
 
-			// Check \ref{} references (only if they match note slugs)
-			refMatches := refRegex.FindAllStringSubmatch(contentStr, -1)
-			for _, m := range refMatches {
-				targetSlug := m[1]
-				if slugMap[targetSlug] {
-					// This is a note reference using deprecated \ref{}
-					// Don't count as broken, but we could warn about deprecation
+				target, local := link.Target.Local(appVault.Name)
+				reason := ""
+				switch {
+				case !local:
+					reason = crossVaultLinkProblem(target, otherVaults)
+					if reason == "" {
+						continue
+					}
+				case !slugMap[target.Slug]:
+					reason = "Missing"
+				case target.Label != "" && !labels[target.Slug][target.Label]:
+					reason = "Missing anchor"
+				default:
 					continue
 				}
+
+				if brokenCount == 0 {
+					fmt.Println()
+				}
+				fmt.Printf("    %s -> %s (%s) [%s]\n", h.Slug, target, reason, link.Kind)
+				brokenCount++
 			}
 		}
 
@@ -150,6 +190,28 @@ func runDoctor(cmd *cobra.Command, args []string) {
 	})
 }
 
+// crossVaultLinkProblem checks a \lxnote{vault:slug} link, returning why it is broken
+// Opened vaults are kept in repos; a nil entry is a vault that could not be opened
+func crossVaultLinkProblem(target domain.NoteTarget, repos map[string]ports.Repository) string {
+	repo, ok := repos[target.Vault]
+	if !ok {
+		_, repo, _ = openVault(target.Vault)
+		repos[target.Vault] = repo
+	}
+	if repo == nil {
+		return "Unknown vault"
+	}
+
+	note, err := repo.Get(getContext(), target.Slug)
+	if err != nil {
+		return "Missing"
+	}
+	if target.Label != "" && !slices.Contains(domain.ExtractLabels(note.Content), target.Label) {
+		return "Missing anchor"
+	}
+	return ""
+}
+
 // checkStep runs a check function and prints the result nicely
 func checkStep(name string, check func() error) {
 	err := check()
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"sort"
 	"strings"
 
@@ -238,20 +237,10 @@ func unique(slice []string) []string {
 
 func openEditorCmd(slug string) tea.Cmd {
 	return func() tea.Msg {
-		// 1. Resolve the file path
-		// Since the graph only has the slug, we try to construct the path.
-		// A more robust approach would be to look up the filename via the repo,
-		// but this mimics standard behavior in lx.
-		// We assume the standard naming convention "YYYYMMDD-slug.tex" or just "slug.tex"
-
-		// For robustness in this context, we will search for the file using a glob or list logic
-		// But since we can't easily access the repo inside this Cmd closure without globals,
-		// we will rely on `appVault` which is available in the `cmd` package.
-
-		matches, _ := filepath.Glob(filepath.Join(appVault.NotesPath, "*"+slug+".tex"))
+		// 1. Resolve the file path, wherever the note's notebook is
 		targetPath := ""
-		if len(matches) > 0 {
-			targetPath = matches[0]
+		if note, err := noteRepo.Get(getContext(), slug); err == nil {
+			targetPath = appVault.GetNotePath(note.Header.Filename)
 		} else {
 			// Fallback: assume standard generation
 			targetPath = appVault.GetNotePath(domain.GenerateFilename(slug, appConfig.DateFormat))
@@ -8,6 +8,7 @@ import (
 
 	"github.com/kamal-hamza/lx-cli/internal/assets"
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 	"github.com/spf13/cobra"
 )
@@ -16,6 +17,7 @@ var (
 	exportAllFormat string
 	exportAllOutput string
 	exportAllJobs   int
+	exportAllQuery  string
 )
 
 var exportAllCmd = &cobra.Command{
@@ -26,9 +28,14 @@ var exportAllCmd = &cobra.Command{
 Uses concurrent workers to process notes in parallel.
 Useful for backups, static site generation, or sharing your vault.
 
+Use --query to export only the notes matching a query or saved search
+(see lx list --help).
+
 Examples:
   lx export-all -f markdown -o ./dist
-  lx export-all --format html --jobs 8`,
+  lx export-all --format html --jobs 8
+  lx export-all -f html --query "tag:course -tag:draft"
+  lx export-all -f markdown --query @physics-open`,
 	RunE: runExportAll,
 }
 
@@ -36,6 +43,7 @@ func init() {
 	exportAllCmd.Flags().StringVarP(&exportAllFormat, "format", "f", "markdown", "Output format (markdown, html, docx)")
 	exportAllCmd.Flags().StringVarP(&exportAllOutput, "output", "o", "", "Output directory (default: vault/exports/<format>)")
 	exportAllCmd.Flags().IntVarP(&exportAllJobs, "jobs", "j", 4, "Number of concurrent workers")
+	exportAllCmd.Flags().StringVarP(&exportAllQuery, "query", "q", "", "Only export notes matching a query or @saved-search")
 }
 
 func runExportAll(cmd *cobra.Command, args []string) error {
@@ -69,11 +77,12 @@ func runExportAll(cmd *cobra.Command, args []string) error {
 	tmpFilter.WriteString(assets.LinksFilter)
 	tmpFilter.Close()
 
-	// 4. Get All Notes
-	headers, err := noteRepo.ListHeaders(ctx)
+	// 4. Get All Notes, or those matching the query
+	resp, err := listService.Execute(ctx, services.ListRequest{Query: exportAllQuery})
 	if err != nil {
 		return err
 	}
+	headers := resp.Notes
 
 	total := len(headers)
 	if total == 0 {
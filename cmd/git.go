@@ -2,7 +2,9 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
 	"os"
 	"os/exec"
 
@@ -34,6 +36,9 @@ func runGit(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Keep the trash and the journal out of commits made by hand too
+	_ = services.NewGitService(appVault.RootPath).ExcludeLocal(vault.LocalDirs...)
+
 	c := exec.Command("git", args...)
 	c.Dir = appVault.RootPath
 	c.Stdin = os.Stdin
@@ -13,24 +13,46 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var grepMath bool
+
 var grepCmd = &cobra.Command{
-	Use:     "grep",
+	Use:     "grep [--math <formula>]",
 	Aliases: []string{"g"},
 	Short:   "Interactive vault search (alias: g)",
 	Long: `Search through all notes using a fuzzy finder.
 
 This scans all lines in your vault and lets you filter them interactively.
 Select a result to open the note at that specific line.
+Matching is literal; use lx search for notes ranked by relevance.
+
+With --math, formulas are searched instead: the note, line and label of every
+formula containing the given math are listed. Whitespace and trivial macro
+variations are ignored, so \le matches \leq and x^{2} matches x^2.
 
 Examples:
-  lx grep`,
-	Args: cobra.NoArgs,
+  lx grep
+  lx grep --math '\nabla \times'
+  lx grep --math '\int_0^\infty'`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if grepMath {
+			return cobra.MinimumNArgs(1)(cmd, args)
+		}
+		return cobra.NoArgs(cmd, args)
+	},
 	RunE: runGrep,
 }
 
+func init() {
+	grepCmd.Flags().BoolVarP(&grepMath, "math", "m", false, "Search formulas for the given math")
+}
+
 func runGrep(cmd *cobra.Command, args []string) error {
 	ctx := getContext()
 
+	if grepMath {
+		return runMathSearch([]string{strings.Join(args, " ")}, "", 0)
+	}
+
 	fmt.Println(ui.FormatRocket("Scanning vault..."))
 
 	// 1. Get ALL lines from ALL notes
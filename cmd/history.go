@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	historyLimit int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the history of the vault",
+}
+
+var historyOpsCmd = &cobra.Command{
+	Use:   "ops",
+	Short: "List recent operations that changed files",
+	Long: `List the recent operations recorded in the vault's journal, newest first.
+
+Each operation is a command that changed notes, templates or assets, such as
+lx rename, lx migrate or lx tag. lx undo reverts the most recent one that is
+not undone yet. The journal keeps the last 50 operations.`,
+	Example: `  lx history ops
+  lx history ops -n 5`,
+	Args: cobra.NoArgs,
+	RunE: runHistoryOps,
+}
+
+func init() {
+	historyOpsCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Number of operations to show (0 for all)")
+
+	historyCmd.AddCommand(historyOpsCmd)
+}
+
+func runHistoryOps(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	ops, err := journalService.History(ctx, historyLimit)
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		fmt.Println(ui.FormatInfo("No operations recorded yet"))
+		return nil
+	}
+
+	fmt.Println(ui.FormatTitle("Operations"))
+	fmt.Println()
+	for _, op := range ops {
+		fmt.Println(formatOperationLine(op))
+	}
+	fmt.Println()
+	fmt.Println(ui.FormatMuted("Revert the most recent one with: lx undo"))
+
+	return nil
+}
+
+// formatOperationLine renders one operation as a single list line
+func formatOperationLine(op domain.Operation) string {
+	icon := ui.StyleSuccess.Render("●")
+	if op.Undone() {
+		icon = ui.StyleMuted.Render("↶")
+	}
+
+	line := fmt.Sprintf("%s %s  %-40s %3d file(s)  %s",
+		icon,
+		op.Time.Format("2006-01-02 15:04:05"),
+		op.Description(),
+		len(op.Files),
+		ui.FormatMuted(op.ID),
+	)
+	if op.Undone() {
+		line += ui.FormatMuted("  (undone)")
+	}
+	return line
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/kamal-hamza/lx-cli/pkg/config"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
@@ -17,7 +18,8 @@ var initCmd = &cobra.Command{
 	Short: "Initialize the lx vault",
 	Long: `Initialize the lx vault directory structure.
 
-This creates the managed vault at ~/.local/share/lx/ with the following structure:
+This creates the managed vault at ~/.local/share/lx/, or the vault selected
+with --vault (see lx vault), with the following structure:
   - notes/      : Your LaTeX source files
   - templates/  : Your .sty template files
   - cache/      : Build artifacts (PDFs, logs, etc.)
@@ -27,7 +29,11 @@ This creates the managed vault at ~/.local/share/lx/ with the following structur
 
 func runInit(cmd *cobra.Command, args []string) error {
 	// Create vault instance
-	v, err := vault.New()
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		cfg = config.DefaultConfig()
+	}
+	v, err := selectVault(cfg)
 	if err != nil {
 		fmt.Println(ui.FormatError("Failed to determine vault location"))
 		return err
@@ -183,6 +189,8 @@ func createGitignore(v *vault.Vault) error {
 cache/
 dist/
 build/
+.trash/
+.journal/
 
 # OS generated files
 .DS_Store
@@ -20,6 +20,7 @@ var linksCmd = &cobra.Command{
 
 This command searches for the target note's "slug" inside all other notes.
 It helps you see connections and references across your knowledge base.
+Notes that pull it in with \lxembed are listed separately as "Embedded in".
 
 Examples:
   lx links graph
@@ -84,14 +85,28 @@ func runLinks(cmd *cobra.Command, args []string) error {
 		ui.StyleMuted.Render(targetNote.Slug))))
 	fmt.Println()
 
-	// 2. Scan for the Slug
+	// 2. Show notes that embed this one
+	// Embeds are a separate edge type in the index, so list them apart from mentions
+	index, err := indexerService.LoadIndex()
+	if err != nil {
+		return err
+	}
+	if entry, exists := index.GetNote(targetNote.Slug); exists && len(entry.EmbeddedIn) > 0 {
+		fmt.Println(ui.FormatSuccess(fmt.Sprintf("Embedded in %d notes:", len(entry.EmbeddedIn))))
+		for _, slug := range entry.EmbeddedIn {
+			fmt.Println(ui.StyleAccent.Render("• " + slug))
+		}
+		fmt.Println()
+	}
+
+	// 3. Scan for the Slug
 	// We use the GrepService to find occurrences of the slug string
 	matches, err := grepService.Execute(ctx, targetNote.Slug)
 	if err != nil {
 		return err
 	}
 
-	// 3. Process and Display Results
+	// 4. Process and Display Results
 	// We want to group matches by file
 	backlinks := make(map[string][]services.GrepMatch)
 	count := 0
@@ -101,6 +116,10 @@ func runLinks(cmd *cobra.Command, args []string) error {
 		if m.Slug == targetNote.Slug {
 			continue
 		}
+		// Embeds were listed above
+		if strings.Contains(m.Content, `\lxembed{`+targetNote.Slug) {
+			continue
+		}
 		backlinks[m.Slug] = append(backlinks[m.Slug], m)
 		count++
 	}
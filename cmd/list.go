@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -11,6 +12,7 @@ import (
 
 var (
 	listTagFilter string
+	listNotebook  string
 	listSortBy    string
 	listReverse   bool
 	listTemplates bool
@@ -18,17 +20,34 @@ var (
 
 // listCmd represents the list command
 var listCmd = &cobra.Command{
-	Use:     "list",
+	Use:     "list [query]",
 	Short:   "List all LaTeX notes or templates",
 	Aliases: []string{"ls"},
 	Long: `List all LaTeX notes in a table format, or list templates.
 
+Notes can be filtered with a query. Terms are combined with AND; use OR,
+a leading - and parentheses for anything else. Other words match titles,
+slugs and tags, and @name uses a saved search (see lx saved).
+
+  tag:physics          Has the tag, or one nested under it (physics/optics)
+  title:"graph"        Title contains the text
+  slug:topology        Slug contains the text
+  date:>=2025-09       Dated on or after (also =, <, <=, >; YYYY, YYYY-MM or YYYY-MM-DD)
+  links-to:<slug>      Links to or embeds the note
+  has:todo             Has open todos (also links, backlinks, embeds, tags, assets, labels)
+  orphan:true          Has no links in or out
+  notebook:courses     In the notebook, or one nested in it (courses/math201)
+
 Examples:
   # List notes
   lx list
   lx list --tag math
   lx list --sort title
   lx list --tag science --reverse
+  lx list --notebook courses/math201
+  lx list tag:physics -tag:draft date:>=2025-09
+  lx list 'has:todo (tag:exam OR tag:homework)'
+  lx list @physics-open
 
   # List templates
   lx list -t
@@ -37,7 +56,8 @@ Examples:
 }
 
 func init() {
-	listCmd.Flags().StringVar(&listTagFilter, "tag", "", "Filter notes by tag")
+	listCmd.Flags().StringVar(&listTagFilter, "tag", "", "Filter notes by tag, including nested tags")
+	listCmd.Flags().StringVar(&listNotebook, "notebook", "", "Filter notes by notebook, including nested notebooks")
 	// Sort defaults to "date", but we handle config override in runListNotes
 	listCmd.Flags().StringVar(&listSortBy, "sort", "date", "Sort by field (date, title)")
 	listCmd.Flags().BoolVar(&listReverse, "reverse", false, "Reverse sort order")
@@ -66,8 +86,11 @@ func runListNotes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Execute list service
+	query := strings.Join(args, " ")
 	req := services.ListRequest{
+		Query:     query,
 		TagFilter: listTagFilter,
+		Notebook:  listNotebook,
 		SortBy:    listSortBy,
 		Reverse:   listReverse,
 	}
@@ -81,8 +104,12 @@ func runListNotes(cmd *cobra.Command, args []string) error {
 
 	// Handle empty results
 	if resp.Total == 0 {
-		if listTagFilter != "" {
+		if query != "" {
+			fmt.Println(ui.FormatWarning("No notes match: " + query))
+		} else if listTagFilter != "" {
 			fmt.Println(ui.FormatWarning("No notes found with tag: " + listTagFilter))
+		} else if listNotebook != "" {
+			fmt.Println(ui.FormatWarning("No notes found in notebook: " + listNotebook))
 		} else {
 			fmt.Println(ui.FormatWarning("No notes found"))
 			fmt.Println(ui.FormatInfo("Create your first note with: lx new \"My Note\""))
@@ -91,8 +118,12 @@ func runListNotes(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print header
-	if listTagFilter != "" {
+	if query != "" {
+		fmt.Println(ui.FormatTitle(fmt.Sprintf("Notes (matching: %s)", query)))
+	} else if listTagFilter != "" {
 		fmt.Println(ui.FormatTitle(fmt.Sprintf("Notes (filtered by tag: %s)", listTagFilter)))
+	} else if listNotebook != "" {
+		fmt.Println(ui.FormatTitle(fmt.Sprintf("Notes (in notebook: %s)", listNotebook)))
 	} else {
 		fmt.Println(ui.FormatTitle("Notes"))
 	}
@@ -126,6 +126,10 @@ func runMigrate(cmd *cobra.Command, args []string) {
 			fmt.Println()
 		} else {
 			// Write changes
+			if err := journal.Capture(notePath); err != nil {
+				fmt.Printf("%s Failed to write %s: %v\n", ui.FormatError("✘"), header.Slug, err)
+				continue
+			}
 			if err := os.WriteFile(notePath, []byte(modifiedContent), 0644); err != nil {
 				fmt.Printf("%s Failed to write %s: %v\n", ui.FormatError("✘"), header.Slug, err)
 				continue
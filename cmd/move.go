@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+
+	fuzzyfinder "github.com/ktr0731/go-fuzzyfinder"
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var moveCmd = &cobra.Command{
+	Use:     "move [query] <notebook>",
+	Aliases: []string{"mv"},
+	Short:   "Move a note to another notebook (alias: mv)",
+	Long: `Move a note to another notebook, a folder inside the notes directory.
+
+The notebook is created if it does not exist yet; use . for the top level.
+The note keeps its filename and slug, so links to it keep working. If a
+note in another notebook has the same slug, both are then linked to by
+their qualified notebook/slug.
+
+Examples:
+  lx move limits courses/math201
+  lx mv graph-theory .
+  lx mv courses/archive       # pick the note interactively`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runMove,
+}
+
+func runMove(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	// 1. Parse Arguments & Select Note
+	notebook := args[len(args)-1]
+	if err := domain.ValidateNotebook(notebook); err != nil {
+		return err
+	}
+	notebook = domain.NormalizeNotebook(notebook)
+
+	var target *domain.NoteHeader
+	if len(args) == 2 {
+		query := args[0]
+		if note, err := noteRepo.Get(ctx, query); err == nil {
+			target = &note.Header
+		} else {
+			resp, err := listService.Search(ctx, services.SearchRequest{Query: query})
+			if err != nil {
+				return err
+			}
+			if resp.Total == 0 {
+				return fmt.Errorf("no note found matching '%s'", query)
+			}
+			target = &resp.Notes[0]
+		}
+	} else {
+		// Interactive Selection
+		resp, err := listService.Execute(ctx, services.ListRequest{SortBy: "date"})
+		if err != nil {
+			return err
+		}
+		if resp.Total == 0 {
+			fmt.Println(ui.FormatWarning("No notes found."))
+			return nil
+		}
+
+		idx, err := fuzzyfinder.Find(
+			resp.Notes,
+			func(i int) string { return resp.Notes[i].Title },
+			fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
+				if i == -1 {
+					return ""
+				}
+				return fmt.Sprintf("Move Note\n\nTitle: %s\nSlug: %s\nFile: %s", resp.Notes[i].Title, resp.Notes[i].Slug, resp.Notes[i].Filename)
+			}),
+		)
+		if err != nil {
+			return nil
+		}
+		target = &resp.Notes[idx]
+	}
+
+	// 2. Move
+	newFilename, err := noteRepo.Move(ctx, target.Slug, notebook)
+	if err != nil {
+		fmt.Println(ui.FormatError("Failed to move note"))
+		return err
+	}
+
+	destination := notebook
+	if destination == "" {
+		destination = "top level"
+	}
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Moved '%s' to %s", target.Title, destination)))
+	fmt.Println(ui.RenderKeyValue("File", newFilename))
+
+	// 3. Report the slug, which is qualified if another notebook has the same one
+	headers, err := noteRepo.ListHeaders(ctx)
+	if err != nil {
+		return nil
+	}
+	for _, header := range headers {
+		if header.Filename == newFilename {
+			fmt.Println(ui.RenderKeyValue("Slug", header.Slug))
+			if header.Slug != target.Slug {
+				fmt.Println(ui.FormatWarning(fmt.Sprintf("The slug changed; links to %s now need \\lxnote{%s}", target.Slug, header.Slug)))
+			}
+		}
+	}
+
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 var (
 	newTemplateName string
 	newTags         []string
+	newNotebook     string
 )
 
 // newCmd represents the new command
@@ -27,6 +28,7 @@ Examples:
   lx new "Graph Theory Notes"
   lx new "Chemistry Lab" --template homework --tags science,lab
   lx new "Calculus Chapter 3" -t math-common --tags math,calculus
+  lx new "Limits" --in courses/math201
 
   # Create a template
   lx new template "My Custom Template"`,
@@ -37,6 +39,7 @@ Examples:
 func init() {
 	newCmd.Flags().StringVarP(&newTemplateName, "template", "t", "", "Template to use (e.g., homework, ieee)")
 	newCmd.Flags().StringSliceVar(&newTags, "tags", []string{}, "Tags for the note (comma-separated)")
+	newCmd.Flags().StringVar(&newNotebook, "in", "", "Notebook to create the note in (e.g., courses/math201)")
 }
 
 // runNewDispatcher determines whether to create a note or template
@@ -63,6 +66,7 @@ func runNew(cmd *cobra.Command, args []string) error {
 		Tags:         newTags,
 		TemplateName: newTemplateName,
 		DateFormat:   appConfig.DateFormat, // Use configured date format
+		Notebook:     newNotebook,
 	}
 
 	ctx := getContext()
@@ -78,6 +82,9 @@ func runNew(cmd *cobra.Command, args []string) error {
 	fmt.Println(ui.RenderKeyValue("Title", resp.Note.Header.Title))
 	fmt.Println(ui.RenderKeyValue("Slug", resp.Note.Header.Slug))
 	fmt.Println(ui.RenderKeyValue("File", resp.FilePath))
+	if resp.Note.Header.Notebook != "" {
+		fmt.Println(ui.RenderKeyValue("Notebook", resp.Note.Header.Notebook))
+	}
 	if len(resp.Note.Header.Tags) > 0 {
 		fmt.Println(ui.RenderKeyValue("Tags", strings.Join(resp.Note.Header.Tags, ", ")))
 	}
@@ -18,6 +18,7 @@ import (
 var (
 	reindexWatch bool
 	reindexQuiet bool
+	reindexFull  bool
 )
 
 var reindexCmd = &cobra.Command{
@@ -32,8 +33,12 @@ This command:
   3. Detects LaTeX links (\input, \ref, \cite, etc.)
   4. Calculates backlinks by inverting connections
   5. Saves the index to index.json
+  6. Updates the full-text search index used by lx search
+  7. Updates the index of formulas used by lx grep --math
 
 The index enables fast lookups and graph visualization without scanning files.
+Notes whose modification time and content are unchanged since the last run
+are skipped; use --full to reprocess everything.
 
 Use --watch to continuously monitor for file changes and auto-reindex.`,
 	RunE: runReindex,
@@ -42,6 +47,7 @@ Use --watch to continuously monitor for file changes and auto-reindex.`,
 func init() {
 	reindexCmd.Flags().BoolVarP(&reindexWatch, "watch", "w", false, "Watch for changes and auto-reindex")
 	reindexCmd.Flags().BoolVarP(&reindexQuiet, "quiet", "q", false, "Suppress reindex notifications (only with --watch)")
+	reindexCmd.Flags().BoolVar(&reindexFull, "full", false, "Discard the index and reprocess every note")
 }
 
 func runReindex(cmd *cobra.Command, args []string) error {
@@ -58,7 +64,8 @@ func runReindex(cmd *cobra.Command, args []string) error {
 
 	startTime := time.Now()
 	indexerService := services.NewIndexerService(noteRepo, appVault.IndexPath())
-	req := services.ReindexRequest{}
+	indexerService.SetVaultName(appVault.Name)
+	req := services.ReindexRequest{Full: reindexFull}
 	resp, err := indexerService.Execute(ctx, req)
 	if err != nil {
 		fmt.Println(ui.FormatError("Reindex failed"))
@@ -71,6 +78,10 @@ func runReindex(cmd *cobra.Command, args []string) error {
 	fmt.Println()
 	fmt.Println(ui.RenderKeyValue("Total Notes", fmt.Sprintf("%d", resp.TotalNotes)))
 	fmt.Println(ui.RenderKeyValue("Total Connections", fmt.Sprintf("%d", resp.TotalConnections)))
+	fmt.Println(ui.RenderKeyValue("Updated", fmt.Sprintf("%d", resp.Updated)))
+	if resp.Removed > 0 {
+		fmt.Println(ui.RenderKeyValue("Removed", fmt.Sprintf("%d", resp.Removed)))
+	}
 	fmt.Println(ui.RenderKeyValue("Duration", duration.Round(time.Millisecond).String()))
 	fmt.Println()
 	fmt.Println(ui.FormatMuted("Index saved to: " + appVault.IndexPath()))
@@ -86,8 +97,8 @@ func runReindexWatch(ctx context.Context) error {
 	}
 	defer watcher.Close()
 
-	// Watch the notes directory
-	if err := watcher.Add(appVault.NotesPath); err != nil {
+	// Watch the notes directory and its notebooks
+	if err := watchNotebooks(watcher, appVault.NotesPath); err != nil {
 		return fmt.Errorf("failed to watch notes directory: %w", err)
 	}
 
@@ -101,21 +112,22 @@ func runReindexWatch(ctx context.Context) error {
 	// Debounce timer to avoid excessive reindexing
 	var debounceTimer *time.Timer
 	debounceDuration := 500 * time.Millisecond
-	needsReindex := false
+	changes := newChangeSet()
 
 	// Function to perform reindex
 	doReindex := func() {
-		if !needsReindex {
+		paths := changes.drain()
+		if len(paths) == 0 {
 			return
 		}
-		needsReindex = false
 
 		if !reindexQuiet {
 			fmt.Println(ui.FormatInfo("File changes detected, reindexing..."))
 		}
 
 		indexerService := services.NewIndexerService(noteRepo, appVault.IndexPath())
-		req := services.ReindexRequest{}
+		indexerService.SetVaultName(appVault.Name)
+		req := services.ReindexRequest{Paths: reindexPaths(paths)}
 		resp, err := indexerService.Execute(ctx, req)
 		if err != nil {
 			if !reindexQuiet {
@@ -139,8 +151,11 @@ func runReindexWatch(ctx context.Context) error {
 				return nil
 			}
 
-			// Only care about .tex files
-			if !strings.HasSuffix(event.Name, ".tex") {
+			// Notebooks come and go with all their notes
+			notebookChanged := notebookEvent(watcher, event)
+
+			// Otherwise only care about .tex files
+			if !notebookChanged && !strings.HasSuffix(event.Name, ".tex") {
 				continue
 			}
 
@@ -151,12 +166,13 @@ func runReindexWatch(ctx context.Context) error {
 			}
 
 			// Check if it's a create, write, remove, or rename event
-			if event.Has(fsnotify.Create) ||
+			if notebookChanged ||
+				event.Has(fsnotify.Create) ||
 				event.Has(fsnotify.Write) ||
 				event.Has(fsnotify.Remove) ||
 				event.Has(fsnotify.Rename) {
 
-				needsReindex = true
+				changes.add(event.Name)
 
 				// Reset debounce timer
 				if debounceTimer != nil {
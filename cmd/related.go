@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	relatedLimit   int
+	relatedSuggest bool
+)
+
+var relatedCmd = &cobra.Command{
+	Use:     "related <note>",
+	Aliases: []string{"rel"},
+	Short:   "Find notes on similar topics (alias: rel)",
+	Long: `List the notes most related to a note, best first.
+
+Notes are ranked by three signals, all computed offline from the index:
+  • Text similarity: TF-IDF cosine similarity of the note texts, without LaTeX commands
+  • Shared tags
+  • Co-citation: other notes that link to both
+
+Similar notes that the note does not link to yet are suggested as \lxnote links.`,
+	Example: `  lx related compactness
+  lx related "metric spaces" -n 5
+  lx related compactness --suggest`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRelated,
+}
+
+func init() {
+	relatedCmd.Flags().IntVarP(&relatedLimit, "limit", "n", 10, "Maximum number of notes to show")
+	relatedCmd.Flags().BoolVarP(&relatedSuggest, "suggest", "s", false, "Only show missing link suggestions")
+}
+
+func runRelated(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+	query := strings.Join(args, " ")
+
+	// 1. Find the note
+	resp, err := listService.Search(ctx, services.SearchRequest{Query: query})
+	if err != nil {
+		return err
+	}
+	if resp.Total == 0 {
+		return fmt.Errorf("no notes found matching: %s", query)
+	}
+	note := resp.Notes[0]
+
+	// 2. Bring the indexes up to date; unchanged notes are skipped
+	if _, err := indexerService.Execute(ctx, services.ReindexRequest{}); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	// 3. Rank
+	req := services.RelatedRequest{Slug: note.Slug, Limit: relatedLimit}
+	var related []services.RelatedNote
+	if relatedSuggest {
+		related, err = relatedService.SuggestLinks(ctx, req)
+	} else {
+		related, err = relatedService.Related(ctx, req)
+	}
+	if err != nil {
+		return err
+	}
+
+	if relatedSuggest {
+		fmt.Println(ui.FormatTitle("Missing links from " + note.Title))
+	} else {
+		fmt.Println(ui.FormatTitle("Related to " + note.Title))
+	}
+	fmt.Println()
+
+	if len(related) == 0 {
+		fmt.Println(ui.FormatMuted("No related notes found"))
+		return nil
+	}
+
+	for i, r := range related {
+		fmt.Printf("%s %s %s\n",
+			ui.StyleMuted.Render(fmt.Sprintf("%2d.", i+1)),
+			ui.StyleBold.Render(r.Title),
+			ui.FormatMuted(fmt.Sprintf("(%s, %.2f)", r.Slug, r.Score)))
+		fmt.Println("    " + ui.FormatMuted(relatedReasons(r)))
+	}
+	fmt.Println()
+
+	// 4. Suggest links to the similar notes that are not linked yet
+	if !relatedSuggest {
+		suggestions, err := relatedService.SuggestLinks(ctx, services.RelatedRequest{Slug: note.Slug, Limit: 3})
+		if err != nil {
+			return err
+		}
+		related = suggestions
+	}
+	if len(related) > 0 {
+		fmt.Println(ui.FormatInfo("Consider linking:"))
+		for _, r := range related {
+			// The link shows the note's title by itself; the comment only names it here
+			fmt.Printf("  \\lxnote{%s}  %s\n", r.Slug, ui.FormatMuted("% "+r.Title))
+		}
+	}
+
+	return nil
+}
+
+// relatedReasons describes why a note is related
+func relatedReasons(r services.RelatedNote) string {
+	reasons := []string{fmt.Sprintf("text %.2f", r.Similarity)}
+	if len(r.SharedTags) > 0 {
+		reasons = append(reasons, "tags: "+strings.Join(r.SharedTags, ", "))
+	}
+	if r.CoCited > 0 {
+		reasons = append(reasons, fmt.Sprintf("co-cited by %d", r.CoCited))
+	}
+	if r.Linked {
+		reasons = append(reasons, "linked")
+	}
+	return strings.Join(reasons, " · ")
+}
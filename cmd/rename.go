@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
@@ -17,16 +16,19 @@ import (
 var renameTemplate bool
 
 var renameCmd = &cobra.Command{
-	Use:     "rename [query] [new-title]",
-	Aliases: []string{"mv"},
-	Short:   "Rename a note and update references (alias: mv)",
+	Use:   "rename [query] [new-title]",
+	Short: "Rename a note and update references",
 	Long: `Rename a note and update all backlinks and imports.
+The note stays in its notebook; use lx move to put it in another one.
 
 Refactors:
-- \ref{old-slug}     -> \ref{new-slug}
-- \input{old-slug}   -> \input{new-slug}
-- \include{old-slug} -> \include{new-slug}
-- \cite{old-slug}    -> \cite{new-slug}
+- \lxnote{old-slug}        -> \lxnote{new-slug} (labels are kept)
+- \lxembed{old-slug}       -> \lxembed{new-slug}
+- \ref{old-slug}           -> \ref{new-slug}
+- \input{old-slug}         -> \input{new-slug}
+- \include{old-slug}       -> \include{new-slug}
+- \cite{old-slug}          -> \cite{new-slug}
+- tags: link:old-slug      -> tags: link:new-slug
 
 Examples:
   lx rename graph "Graph Theory"
@@ -142,7 +144,6 @@ func runRenameNote(cmd *cobra.Command, args []string) error {
 	}
 
 	count := 0
-	refRegex := regexp.MustCompile(`\\(ref|input|include|cite)\{` + regexp.QuoteMeta(oldSlug) + `\}`)
 
 	for filename := range filesToEdit {
 		path := appVault.GetNotePath(filename)
@@ -151,9 +152,13 @@ func runRenameNote(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		newContent := refRegex.ReplaceAllString(string(content), `\$1{`+newSlug+`}`)
+		newContent, _ := domain.RenameLinkTarget(string(content), appVault.Name, oldSlug, newSlug)
 
 		if newContent != string(content) {
+			if err := journal.Capture(path); err != nil {
+				fmt.Println(ui.FormatWarning(err.Error()))
+				continue
+			}
 			if err := os.WriteFile(path, []byte(newContent), 0644); err == nil {
 				fmt.Printf("  %s %s\n", ui.FormatSuccess("Updated"), filename)
 				count++
@@ -235,6 +240,9 @@ func runRenameTemplate(cmd *cobra.Command, args []string) error {
 	}
 	newPath := appVault.GetTemplatePath(newFilename)
 
+	if err := journal.Capture(oldPath, newPath); err != nil {
+		return err
+	}
 	if err := os.Rename(oldPath, newPath); err != nil {
 		return fmt.Errorf("failed to rename template: %w", err)
 	}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kamal-hamza/lx-cli/internal/adapters/compiler"
 	"github.com/kamal-hamza/lx-cli/internal/adapters/repository"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/internal/core/services"
 	"github.com/kamal-hamza/lx-cli/pkg/config"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
@@ -28,6 +32,10 @@ var (
 	indexerService        *services.IndexerService
 	graphService          *services.GraphService
 	grepService           *services.GrepService
+	relatedService        *services.RelatedService
+	tagService            *services.TagService
+	trashService          *services.TrashService
+	journalService        *services.JournalService
 
 	preprocessor *services.Preprocessor
 
@@ -35,9 +43,16 @@ var (
 	noteRepo     *repository.FileRepository
 	templateRepo *repository.TemplateRepository
 	assetRepo    *repository.FileAssetRepository
+	buildLogRepo *repository.FileBuildLogRepository
+
+	// Journal of the files each command changes, for lx undo
+	journal *repository.FileJournal
 
 	// Compiler
-	latexCompiler *compiler.LatexmkCompiler
+	latexCompiler ports.Compiler
+
+	// Vault selected with --vault
+	vaultFlag string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -55,6 +70,14 @@ var rootCmd = &cobra.Command{
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
 	err := rootCmd.Execute()
+
+	// Record what the command changed, even if it failed halfway
+	if journal != nil {
+		if _, err := journal.Commit(); err != nil {
+			fmt.Println(ui.FormatWarning("Failed to record operation: " + err.Error()))
+		}
+	}
+
 	if err != nil {
 		os.Exit(1)
 	}
@@ -69,6 +92,7 @@ func init() {
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(buildAllCmd)
+	rootCmd.AddCommand(buildLogCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(purgeCmd)
 	rootCmd.AddCommand(versionCmd)
@@ -76,6 +100,7 @@ func init() {
 	rootCmd.AddCommand(cloneCmd)
 	rootCmd.AddCommand(syncCmd)
 	rootCmd.AddCommand(renameCmd)
+	rootCmd.AddCommand(moveCmd)
 	rootCmd.AddCommand(doctorCmd)
 	rootCmd.AddCommand(statsCmd)
 	rootCmd.AddCommand(cleanCmd)
@@ -83,6 +108,9 @@ func init() {
 	rootCmd.AddCommand(tagCmd)
 	rootCmd.AddCommand(graphCmd)
 	rootCmd.AddCommand(grepCmd)
+	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(savedCmd)
+	rootCmd.AddCommand(relatedCmd)
 	rootCmd.AddCommand(dailyCmd)
 	rootCmd.AddCommand(linksCmd)
 	rootCmd.AddCommand(exploreCmd)
@@ -96,26 +124,25 @@ func init() {
 	rootCmd.AddCommand(exportAllCmd)
 	rootCmd.AddCommand(migrateCmd)
 	rootCmd.AddCommand(aliasCmd)
-
-	// Global flags can be added here if needed
+	rootCmd.AddCommand(synctexCmd)
+	rootCmd.AddCommand(vaultCmd)
+	rootCmd.AddCommand(trashCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(historyCmd)
+
+	// Global flags
+	rootCmd.PersistentFlags().StringVar(&vaultFlag, "vault", "", "Vault to use, by name or path (default: $LX_VAULT, the vault in the current folder, or the current vault)")
 }
 
 // initializeApp initializes the application components
 func initializeApp(cmd *cobra.Command, args []string) error {
-	// Skip initialization for init command
-	if cmd.Name() == "init" {
+	// Skip initialization for init command and vault management
+	if cmd.Name() == "init" || isVaultCommand(cmd) {
 		return nil
 	}
 
-	// Create vault instance
-	v, err := vault.New()
-	if err != nil {
-		return fmt.Errorf("failed to initialize vault: %w", err)
-	}
-	appVault = v
-
 	// Load Configuration
-	cfg, err := config.Load(appVault.ConfigPath)
+	cfg, err := loadGlobalConfig()
 	if err != nil {
 		// If config is corrupt or fails to load, warn but proceed with defaults
 		// config.Load already handles missing files by returning default
@@ -123,6 +150,18 @@ func initializeApp(cmd *cobra.Command, args []string) error {
 		fmt.Println(ui.FormatMuted("Using default settings."))
 		cfg = config.DefaultConfig()
 	}
+
+	// Select the vault
+	v, err := selectVault(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize vault: %w", err)
+	}
+	appVault = v
+
+	// Layer the vault's own settings over the global ones
+	if err := cfg.Overlay(appVault.LocalConfigPath()); err != nil {
+		fmt.Println(ui.FormatWarning("Failed to load vault config: " + err.Error()))
+	}
 	appConfig = cfg
 
 	// Apply UI Theme
@@ -135,11 +174,15 @@ func initializeApp(cmd *cobra.Command, args []string) error {
 		os.Exit(1)
 	}
 
-	// Check if latexmk is available (for build commands)
+	// Check if the configured compiler is available (for build commands)
 	if cmd.Name() == "build" || cmd.Name() == "build-all" {
-		if !compiler.IsAvailable() {
-			fmt.Println(ui.FormatError("latexmk not found"))
-			fmt.Println(ui.FormatInfo("Please install LaTeX and latexmk to use build commands"))
+		if !compiler.Available(appConfig.Compiler) {
+			binary := compiler.Binary(appConfig.Compiler)
+			if binary == "" {
+				binary = appConfig.Compiler
+			}
+			fmt.Println(ui.FormatError(binary + " not found"))
+			fmt.Println(ui.FormatInfo("Please install LaTeX and " + binary + " to use build commands"))
 			os.Exit(1)
 		}
 	}
@@ -148,12 +191,24 @@ func initializeApp(cmd *cobra.Command, args []string) error {
 	noteRepo = repository.NewFileRepository(appVault)
 	templateRepo = repository.NewTemplateRepository(appVault, appConfig.CustomTemplateDir)
 	assetRepo = repository.NewFileAssetRepository(appVault)
+	buildLogRepo = repository.NewFileBuildLogRepository(appVault, appConfig.BuildHistory)
 
-	// Initialize compiler with config
-	latexCompiler = compiler.NewLatexmkCompiler(appVault, appConfig)
+	// Journal the files this command changes
+	journal = repository.NewFileJournal(appVault)
+	journal.Begin(cmd.CommandPath(), args)
+	noteRepo.SetJournal(journal)
+	templateRepo.SetJournal(journal)
+	assetRepo.SetJournal(journal)
+
+	// Initialize the compiler selected in config
+	latexCompiler, err = compiler.New(appConfig.Compiler, appVault, appConfig)
+	if err != nil {
+		return err
+	}
 
 	// Initialize Preprocessor with caching config
 	preprocessor = services.NewPreprocessor(noteRepo, appVault, appConfig.EnableCache, appConfig.CacheExpirationMinutes)
+	preprocessor.SetVaultOpener(openVault)
 
 	// Initialize Git service
 	gitService := services.NewGitService(appVault.RootPath)
@@ -162,19 +217,84 @@ func initializeApp(cmd *cobra.Command, args []string) error {
 	createNoteService = services.NewCreateNoteService(noteRepo, templateRepo, gitService, appConfig)
 	createTemplateService = services.NewCreateTemplateService(templateRepo)
 	buildService = services.NewBuildServiceWithPreprocessor(noteRepo, latexCompiler, preprocessor, appVault)
+	buildService.SetBuildLog(buildLogRepo)
+	buildService.SetTimeout(time.Duration(appConfig.BuildTimeout) * time.Second)
 	listService = services.NewListService(noteRepo)
 	indexerService = services.NewIndexerService(noteRepo, appVault.IndexPath())
+	indexerService.SetVaultName(appVault.Name)
+	listService.SetIndexer(indexerService)
+	listService.SetSavedSearches(appConfig.SavedSearches)
 	graphService = services.NewGraphService(noteRepo, appConfig)
+	graphService.SetVaultName(appVault.Name)
 	grepService = services.NewGrepService(appVault.RootPath, appConfig.GrepCaseSensitive, appConfig.MaxSearchResults)
+	tagService = services.NewTagService(noteRepo)
+	trashService = services.NewTrashService(noteRepo, assetRepo, indexerService)
+	journalService = services.NewJournalService(journal)
+	relatedService = services.NewRelatedService(indexerService, services.NewSearchService(noteRepo, appVault.SearchIndexPath()))
 
 	return nil
 }
 
+// loadGlobalConfig loads the global config file, which also lists the vaults
+func loadGlobalConfig() (*config.Config, error) {
+	path, err := vault.GlobalConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path)
+}
+
+// selectVault returns the vault chosen with --vault, LX_VAULT, the working
+// directory or lx vault use, in that order
+func selectVault(cfg *config.Config) (*vault.Vault, error) {
+	dir, _ := os.Getwd()
+	return vault.Select(vault.Selection{
+		Flag:    vaultFlag,
+		Env:     os.Getenv(vault.EnvVar),
+		Dir:     dir,
+		Current: cfg.CurrentVault,
+		Vaults:  cfg.Vaults,
+	})
+}
+
+// openVault opens another registered vault, for \lxnote{vault:slug} links
+func openVault(name string) (*vault.Vault, ports.Repository, error) {
+	v, err := vault.Lookup(name, appConfig.Vaults)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !v.Exists() {
+		return nil, nil, fmt.Errorf("vault %s not found at %s", name, v.RootPath)
+	}
+	return v, repository.NewFileRepository(v), nil
+}
+
+// saveGlobalConfig applies an edit to the global config file and to the loaded config
+// The loaded config has the vault's own settings layered in, so it is not saved as it is
+func saveGlobalConfig(edit func(cfg *config.Config)) error {
+	cfg, err := config.Load(appVault.ConfigPath)
+	if err != nil {
+		return err
+	}
+	edit(cfg)
+	if err := cfg.Save(appVault.ConfigPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	edit(appConfig)
+	return nil
+}
+
 // getContext returns a context for operations
 func getContext() context.Context {
 	return context.Background()
 }
 
+// getInterruptibleContext returns a context that is cancelled on Ctrl+C or SIGTERM
+// Long-running commands use it so child processes are stopped cleanly
+func getInterruptibleContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(getContext(), os.Interrupt, syscall.SIGTERM)
+}
+
 // runSmartEntry handles smart entry when lx is called with arbitrary arguments
 func runSmartEntry(cmd *cobra.Command, args []string) error {
 	// If no arguments provided, launch dashboard
@@ -233,12 +353,19 @@ func runSmartAction(cmd *cobra.Command, args []string, action string) error {
 	}
 }
 
-// loadConfig loads the configuration file
+// loadConfig loads the configuration file, with the vault's own settings layered in
 func loadConfig() (*config.Config, error) {
 	if appVault == nil {
 		return nil, fmt.Errorf("vault not initialized")
 	}
-	return config.Load(appVault.ConfigPath)
+	cfg, err := config.Load(appVault.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Overlay(appVault.LocalConfigPath()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
 }
 
 // executeAliasCommand executes an expanded alias command by finding and running the appropriate subcommand
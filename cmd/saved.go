@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/config"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var savedCmd = &cobra.Command{
+	Use:   "saved",
+	Short: "Save, list and remove named searches",
+	Long: `Queries used every day can be saved under a name and reused as @name in
+lx list, lx export-all --query and lx build-all --query. The dashboard shows
+saved searches as collections.
+
+Without a subcommand, the saved searches are listed. Saved searches live
+under lx saved rather than lx search, so that lx search can look for any
+word, including save, list and rm.
+
+Saved searches are kept in the global config. Those defined in a vault's
+lx.yaml are used too, but are changed by editing that file.`,
+	Example: `  lx saved save physics-open "tag:physics has:todo"
+  lx list @physics-open
+  lx saved
+  lx saved rm physics-open`,
+	Args: cobra.NoArgs,
+	RunE: runSavedList,
+}
+
+var savedSaveCmd = &cobra.Command{
+	Use:   "save <name> <query>",
+	Short: "Save a query under a name",
+	Long: `Save a query so it can be used as @name.
+
+The query uses the syntax of lx list (see lx list --help) and may refer to
+other saved searches. Saving under an existing name replaces the query.`,
+	Example: `  lx saved save physics-open "tag:physics has:todo"
+  lx saved save this-term "@physics-open date:>=2025-09"`,
+	Args: cobra.ExactArgs(2),
+	RunE: runSavedSave,
+}
+
+var savedListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List saved searches with their current number of notes",
+	Args:    cobra.NoArgs,
+	RunE:    runSavedList,
+}
+
+var savedRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a saved search",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSavedRemove,
+}
+
+func init() {
+	savedCmd.AddCommand(savedSaveCmd)
+	savedCmd.AddCommand(savedListCmd)
+	savedCmd.AddCommand(savedRemoveCmd)
+}
+
+func runSavedSave(cmd *cobra.Command, args []string) error {
+	name := strings.TrimPrefix(args[0], "@")
+	query := strings.TrimSpace(args[1])
+
+	// 1. Validate the name and the query, including the saved searches it refers to
+	if err := validateSavedSearchName(name); err != nil {
+		return err
+	}
+	saved := make(map[string]string, len(appConfig.SavedSearches)+1)
+	for existing, q := range appConfig.SavedSearches {
+		saved[existing] = q
+	}
+	saved[name] = query
+
+	expanded, err := domain.ExpandSavedSearches("@"+name, saved)
+	if err != nil {
+		return err
+	}
+	if _, err := domain.ParseQuery(expanded); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	// 2. Save it; the vault's lx.yaml would override the global entry
+	if err := checkNotVaultLocal(name); err != nil {
+		return err
+	}
+	_, replaced := appConfig.SavedSearches[name]
+	if err := saveGlobalConfig(func(cfg *config.Config) { cfg.SavedSearches[name] = query }); err != nil {
+		return err
+	}
+
+	if replaced {
+		fmt.Println(ui.FormatSuccess(fmt.Sprintf("Updated saved search: @%s → %s", name, query)))
+	} else {
+		fmt.Println(ui.FormatSuccess(fmt.Sprintf("Saved search: @%s → %s", name, query)))
+	}
+	fmt.Println(ui.FormatMuted("List its notes with: lx list @" + name))
+	return nil
+}
+
+func runSavedList(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	if len(appConfig.SavedSearches) == 0 {
+		fmt.Println(ui.FormatInfo("No saved searches"))
+		fmt.Println(ui.FormatMuted("Save one with: lx saved save <name> \"<query>\""))
+		return nil
+	}
+
+	names := savedSearchNames()
+	width := 0
+	for _, name := range names {
+		width = max(width, len(name))
+	}
+
+	fmt.Println(ui.FormatTitle("Saved Searches"))
+	fmt.Println()
+
+	for _, name := range names {
+		count := ui.StyleError.Render("invalid")
+		if resp, err := listService.Execute(ctx, services.ListRequest{Query: "@" + name}); err == nil {
+			count = fmt.Sprintf("%d note(s)", resp.Total)
+		}
+
+		fmt.Printf("  %s%s  %s  %s\n",
+			ui.StyleSuccess.Render("@"+name),
+			strings.Repeat(" ", width-len(name)),
+			ui.FormatMuted(appConfig.SavedSearches[name]),
+			count)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runSavedRemove(cmd *cobra.Command, args []string) error {
+	name := strings.TrimPrefix(args[0], "@")
+
+	query, ok := appConfig.SavedSearches[name]
+	if !ok {
+		return fmt.Errorf("saved search '%s' not found", name)
+	}
+	if err := checkNotVaultLocal(name); err != nil {
+		return err
+	}
+
+	if err := saveGlobalConfig(func(cfg *config.Config) { delete(cfg.SavedSearches, name) }); err != nil {
+		return err
+	}
+
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Removed saved search: @%s → %s", name, query)))
+	return nil
+}
+
+// checkNotVaultLocal refuses to change a saved search defined in the vault's lx.yaml
+// Only the global config is written, so the vault's entry would win on the next run
+func checkNotVaultLocal(name string) error {
+	local := &config.Config{}
+	if err := local.Overlay(appVault.LocalConfigPath()); err != nil {
+		return err
+	}
+	if _, ok := local.SavedSearches[name]; ok {
+		return fmt.Errorf("saved search '%s' is defined in %s; edit it there", name, appVault.LocalConfigPath())
+	}
+	return nil
+}
+
+// savedSearchNames returns the names of the saved searches in order
+func savedSearchNames() []string {
+	names := make([]string, 0, len(appConfig.SavedSearches))
+	for name := range appConfig.SavedSearches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateSavedSearchName checks that a name can be written as @name
+func validateSavedSearchName(name string) error {
+	if name == "" {
+		return fmt.Errorf("saved search name cannot be empty")
+	}
+
+	for _, ch := range name {
+		if !isValidAliasChar(ch) {
+			return fmt.Errorf("saved search name contains invalid character: %c", ch)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:     "search <query>",
+	Aliases: []string{"sr"},
+	Short:   "Ranked full-text search across notes (alias: sr)",
+	Long: `Search note contents and list the best matching notes first.
+
+Notes are ranked with BM25 over a full-text index kept next to index.json.
+The index is updated by lx reindex and the daemon, and refreshed for changed
+notes before each search. Words in titles and tags count more than body text.
+
+LaTeX commands, comments and labels are not searchable, but math is:
+"alpha" finds $\alpha$. Use lx grep to find literal lines instead.
+
+A math:"..." term finds formulas containing the given math, ignoring
+whitespace and trivial macro variations (see lx grep --math). Other words
+in the query then narrow the results to the notes that also match them.
+
+Queries used every day can be saved under a name with lx saved and reused
+as @name in lx list, lx export-all --query and lx build-all --query.`,
+	Example: `  lx search compact hausdorff
+  lx search "spectral theorem" -n 5
+  lx search 'math:"\int_0^\infty"'
+  lx search 'math:"\nabla \times" maxwell'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "Maximum number of notes to show")
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	mathTerms, words := splitMathTerms(args)
+	query := strings.Join(words, " ")
+	if len(mathTerms) > 0 {
+		return runMathSearch(mathTerms, query, searchLimit)
+	}
+
+	// 1. Bring the index up to date; unchanged notes are skipped
+	if _, err := indexerService.Execute(ctx, services.ReindexRequest{}); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	// 2. Rank the notes
+	searchService := services.NewSearchService(noteRepo, appVault.SearchIndexPath())
+	results, err := searchService.Search(ctx, services.FullTextRequest{Query: query, Limit: searchLimit})
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println(ui.FormatInfo("No notes match: " + query))
+		return nil
+	}
+
+	// 3. Show each note with its best matching lines
+	mark := func(s string) string { return ui.StyleAccent.Render(s) }
+	for i, result := range results {
+		fmt.Printf("%s %s %s\n",
+			ui.StyleMuted.Render(fmt.Sprintf("%2d.", i+1)),
+			ui.StyleBold.Render(result.Title),
+			ui.FormatMuted(fmt.Sprintf("(%s, %.2f)", result.Slug, result.Score)))
+
+		for _, snippet := range result.Snippets {
+			fmt.Printf("    %s %s\n",
+				ui.StyleMuted.Render(fmt.Sprintf("%4d:", snippet.LineNum)),
+				snippet.Highlight(mark))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println(ui.FormatMuted(fmt.Sprintf("%d note(s) shown", len(results))))
+	return nil
+}
+
+// mathTermPattern matches math:"..." and math:... terms in a query
+var mathTermPattern = regexp.MustCompile(`math:(?:"([^"]*)"|(\S+))`)
+
+// splitMathTerms separates the math:"..." terms of a query from its words
+// The shell may have removed the quotes, so an argument starting with math:
+// is math up to its end, spaces included
+func splitMathTerms(args []string) (mathTerms []string, words []string) {
+	for _, arg := range args {
+		if rest, ok := strings.CutPrefix(arg, "math:"); ok && !strings.HasPrefix(rest, `"`) {
+			mathTerms = append(mathTerms, rest)
+			continue
+		}
+
+		for _, m := range mathTermPattern.FindAllStringSubmatch(arg, -1) {
+			mathTerms = append(mathTerms, m[1]+m[2])
+		}
+		if rest := strings.TrimSpace(mathTermPattern.ReplaceAllString(arg, "")); rest != "" {
+			words = append(words, rest)
+		}
+	}
+	return mathTerms, words
+}
+
+// runMathSearch lists the formulas containing every piece of math, in the notes
+// matching the words if there are any
+// Notes are ranked by the words, or listed by slug without them; limit caps the notes
+func runMathSearch(mathTerms []string, words string, limit int) error {
+	ctx := getContext()
+
+	// 1. Bring the indexes up to date; unchanged notes are skipped
+	if _, err := indexerService.Execute(ctx, services.ReindexRequest{}); err != nil {
+		return fmt.Errorf("failed to update index: %w", err)
+	}
+
+	// 2. Find the formulas; a note must contain all of the math
+	mathService := services.NewMathService(appVault.MathIndexPath())
+	hits := make(map[string][]services.MathMatch)
+	counts := make(map[string]int)
+	found := make(map[services.MathMatch]bool)
+	for _, term := range mathTerms {
+		matches, err := mathService.Search(ctx, services.MathRequest{Query: term})
+		if err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, m := range matches {
+			if !found[m] {
+				found[m] = true
+				hits[m.Slug] = append(hits[m.Slug], m)
+			}
+			if !seen[m.Slug] {
+				seen[m.Slug] = true
+				counts[m.Slug]++
+			}
+		}
+	}
+
+	var slugs []string
+	for slug, count := range counts {
+		if count == len(mathTerms) {
+			slugs = append(slugs, slug)
+		}
+	}
+	sort.Strings(slugs)
+
+	// 3. Narrow to the notes matching the words, ranked by them
+	if words != "" {
+		searchService := services.NewSearchService(noteRepo, appVault.SearchIndexPath())
+		results, err := searchService.Search(ctx, services.FullTextRequest{Query: words})
+		if err != nil {
+			return err
+		}
+		var ranked []string
+		for _, result := range results {
+			if counts[result.Slug] == len(mathTerms) {
+				ranked = append(ranked, result.Slug)
+			}
+		}
+		slugs = ranked
+	}
+	if limit > 0 && len(slugs) > limit {
+		slugs = slugs[:limit]
+	}
+
+	if len(slugs) == 0 {
+		fmt.Println(ui.FormatInfo("No formulas match: " + strings.Join(mathTerms, ", ")))
+		return nil
+	}
+
+	// 4. Show each formula as slug:line [environment label]
+	formulas := 0
+	for _, slug := range slugs {
+		matches := hits[slug]
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].Line < matches[j].Line })
+		for _, m := range matches {
+			where := m.Env
+			if m.Label != "" {
+				where += " " + m.Label
+			}
+			fmt.Printf("%s:%d  %s  %s\n",
+				ui.StyleAccent.Render(m.Slug),
+				m.Line,
+				ui.StyleMuted.Render("["+where+"]"),
+				m.Text)
+			formulas++
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(ui.FormatMuted(fmt.Sprintf("%d formula(s) in %d note(s)", formulas, len(slugs))))
+	return nil
+}
@@ -7,7 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
 
 	"github.com/spf13/cobra"
 )
@@ -47,6 +49,11 @@ func runSync(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("git not initialized. Run 'lx git init' first")
 	}
 
+	// Keep the trash and the journal out of the commit, also in vaults made before they existed
+	if err := services.NewGitService(appVault.RootPath).ExcludeLocal(vault.LocalDirs...); err != nil {
+		fmt.Println(ui.FormatWarning(err.Error()))
+	}
+
 	// 2. Check Dirty State
 	fmt.Print(ui.StyleInfo.Render("Checking status... "))
 	statusCmd := exec.Command("git", "status", "--porcelain")
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/synctex"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	synctexView  bool
+	synctexPrint bool
+)
+
+var synctexCmd = &cobra.Command{
+	Use:   "synctex [command]",
+	Short: "Jump between note source and compiled PDF",
+	Long: `Resolve positions between notes and their PDFs using SyncTeX.
+
+Builds write SyncTeX data next to each PDF in the cache. lx rewrites that
+data so it points at the original note (not the preprocessed cache copy),
+which means PDF viewers and these commands resolve to the file you edit.
+
+Viewer setup (zathura, ~/.config/zathura/zathurarc):
+  set synctex true
+  set synctex-editor-command "nvim +%{line} %{input}"
+
+Editor setup (forward search from the current line):
+  lx synctex forward <file>:<line> --view`,
+}
+
+var synctexForwardCmd = &cobra.Command{
+	Use:   "forward <note>:<line>",
+	Short: "Find the PDF position of a line in a note",
+	Long: `Print the PDF page and position (in points from the top-left corner)
+produced by a line of a note. <note> may be a slug, a filename or a path.
+
+Use --view to open the PDF viewer at that position (zathura and Skim
+are driven directly; other viewers just open the PDF).`,
+	Example: `  lx synctex forward graph-theory:42
+  lx synctex forward notes/20250101-graph-theory.tex:42 --view`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSynctexForward,
+}
+
+var synctexInverseCmd = &cobra.Command{
+	Use:   "inverse <pdf>:<page>:<x>:<y>",
+	Short: "Open the note line behind a PDF position",
+	Long: `Resolve a PDF position (page and x/y in points from the top-left corner)
+to the note line that produced it and open the editor there.
+<pdf> may be a PDF path or a note slug.
+
+Use --print to print "file:line" instead of opening the editor.`,
+	Example: `  lx synctex inverse ~/.local/share/lx/cache/graph-theory.pdf:2:120.5:340
+  lx synctex inverse graph-theory:1:72:100 --print`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSynctexInverse,
+}
+
+func init() {
+	synctexForwardCmd.Flags().BoolVar(&synctexView, "view", false, "Open the PDF viewer at the resolved position")
+	synctexInverseCmd.Flags().BoolVar(&synctexPrint, "print", false, "Print the location instead of opening the editor")
+
+	synctexCmd.AddCommand(synctexForwardCmd)
+	synctexCmd.AddCommand(synctexInverseCmd)
+}
+
+func runSynctexForward(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	noteArg, line, err := splitLocation(args[0])
+	if err != nil {
+		return err
+	}
+
+	// 1. Resolve the note
+	slug, err := resolveSynctexNote(noteArg)
+	if err != nil {
+		return err
+	}
+
+	note, err := noteRepo.Get(ctx, slug)
+	if err != nil {
+		return fmt.Errorf("failed to load note: %w", err)
+	}
+	notePath := appVault.GetNotePath(note.Header.Filename)
+	pdfPath := appVault.GetCachePath(slug + ".pdf")
+
+	// 2. Look up the position
+	doc, err := synctex.Open(synctex.PathForPDF(pdfPath))
+	if err != nil {
+		fmt.Println(ui.FormatWarning("No SyncTeX data found. Build the note first: lx build " + slug))
+		return err
+	}
+
+	pos, err := doc.Forward(notePath, line)
+	if err != nil {
+		return err
+	}
+
+	if !synctexView {
+		fmt.Printf("%s:%d:%.2f:%.2f\n", pdfPath, pos.Page, pos.X, pos.Y)
+		return nil
+	}
+
+	// 3. Open the viewer at the position
+	return openViewerAt(pdfPath, notePath, line)
+}
+
+func runSynctexInverse(cmd *cobra.Command, args []string) error {
+	// Split "<pdf>:<page>:<x>:<y>" from the right so the path may contain colons
+	parts := strings.Split(args[0], ":")
+	if len(parts) < 4 {
+		return fmt.Errorf("expected <pdf>:<page>:<x>:<y>, got %q", args[0])
+	}
+
+	n := len(parts)
+	pdfArg := strings.Join(parts[:n-3], ":")
+	page, pageErr := strconv.Atoi(parts[n-3])
+	x, xErr := strconv.ParseFloat(parts[n-2], 64)
+	y, yErr := strconv.ParseFloat(parts[n-1], 64)
+	if pageErr != nil || xErr != nil || yErr != nil {
+		return fmt.Errorf("invalid position in %q", args[0])
+	}
+
+	// A bare slug refers to the note's PDF in the cache
+	pdfPath := pdfArg
+	if !strings.HasSuffix(pdfPath, ".pdf") {
+		pdfPath = appVault.GetCachePath(pdfArg + ".pdf")
+	}
+
+	doc, err := synctex.Open(synctex.PathForPDF(pdfPath))
+	if err != nil {
+		return err
+	}
+
+	loc, err := doc.Inverse(page, x, y)
+	if err != nil {
+		return err
+	}
+
+	if synctexPrint {
+		fmt.Printf("%s:%d\n", loc.File, loc.Line)
+		return nil
+	}
+
+	return OpenEditorAtLine(loc.File, loc.Line)
+}
+
+// splitLocation splits "<target>:<line>" at the last colon
+func splitLocation(arg string) (string, int, error) {
+	idx := strings.LastIndex(arg, ":")
+	if idx <= 0 || idx == len(arg)-1 {
+		return "", 0, fmt.Errorf("expected <target>:<line>, got %q", arg)
+	}
+
+	line, err := strconv.Atoi(arg[idx+1:])
+	if err != nil || line <= 0 {
+		return "", 0, fmt.Errorf("invalid line number in %q", arg)
+	}
+
+	return arg[:idx], line, nil
+}
+
+// resolveSynctexNote turns a slug, filename, path or search query into a slug
+func resolveSynctexNote(arg string) (string, error) {
+	ctx := getContext()
+
+	if strings.HasSuffix(arg, ".tex") || strings.ContainsRune(arg, filepath.Separator) {
+		slug := domain.ParseFilename(filepath.Base(arg))
+		if noteRepo.Exists(ctx, slug) {
+			return slug, nil
+		}
+		return "", fmt.Errorf("note not found: %s", arg)
+	}
+
+	if noteRepo.Exists(ctx, arg) {
+		return arg, nil
+	}
+
+	resp, err := listService.Search(ctx, services.SearchRequest{Query: arg})
+	if err != nil {
+		return "", err
+	}
+	if resp.Total == 0 {
+		return "", fmt.Errorf("no notes found matching: %s", arg)
+	}
+
+	return resp.Notes[0].Slug, nil
+}
+
+// openViewerAt opens the PDF at the given source line when the viewer supports it
+func openViewerAt(pdfPath, notePath string, line int) error {
+	viewer := appConfig.PDFViewer
+	lowerViewer := strings.ToLower(viewer)
+
+	var c *exec.Cmd
+	switch {
+	case strings.Contains(lowerViewer, "zathura"):
+		c = exec.Command("zathura", "--synctex-forward", fmt.Sprintf("%d:1:%s", line, notePath), pdfPath)
+	case strings.Contains(lowerViewer, "skim"):
+		c = exec.Command("/Applications/Skim.app/Contents/SharedSupport/displayline", strconv.Itoa(line), pdfPath, notePath)
+	default:
+		return OpenFile(pdfPath, viewer)
+	}
+
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Start()
+}
@@ -6,7 +6,9 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/metadata"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 
 	"github.com/spf13/cobra"
@@ -16,7 +18,10 @@ var tagCmd = &cobra.Command{
 	Use:     "tag [command]",
 	Aliases: []string{"t"},
 	Short:   "Manage tags on notes (alias: t)",
-	Long:    `Add or remove tags from notes without opening the editor.`,
+	Long: `Add or remove tags from notes without opening the editor.
+
+Tags can be nested with /, as in math/algebra/groups. Filtering by a tag
+(lx list --tag math, tag:math) includes the tags nested under it.`,
 }
 
 var tagAddCmd = &cobra.Command{
@@ -43,9 +48,105 @@ var tagRemoveCmd = &cobra.Command{
 	},
 }
 
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag in every note",
+	Long: `Rename a tag in the tags header of every note that uses it.
+
+Nested tags move along: renaming math to maths turns math/algebra into
+maths/algebra. Renaming to a tag that is already in use is refused; use
+lx tag merge for that. If any note cannot be written, none is changed.`,
+	Example: `  lx tag rename algebra math/algebra
+  lx tag rename math/algebra/group math/algebra/groups`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagRename(args[0], args[1], false)
+	},
+}
+
+var tagMergeCmd = &cobra.Command{
+	Use:   "merge <from> <into>",
+	Short: "Merge a tag into another in every note",
+	Long: `Replace a tag with another that is already in use, in every note.
+
+Notes that had both tags keep one. Tags nested under <from> move under <into>.
+If any note cannot be written, none is changed.`,
+	Example: `  lx tag merge groups math/algebra/groups
+  lx tag merge hw homework`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagRename(args[0], args[1], true)
+	},
+}
+
+var tagTreeCmd = &cobra.Command{
+	Use:   "tree",
+	Short: "Show the tag hierarchy with note counts",
+	Args:  cobra.NoArgs,
+	RunE:  runTagTree,
+}
+
 func init() {
 	tagCmd.AddCommand(tagAddCmd)
 	tagCmd.AddCommand(tagRemoveCmd)
+	tagCmd.AddCommand(tagRenameCmd)
+	tagCmd.AddCommand(tagMergeCmd)
+	tagCmd.AddCommand(tagTreeCmd)
+}
+
+func runTagRename(from, to string, merge bool) error {
+	ctx := getContext()
+
+	resp, err := tagService.Rename(ctx, services.RenameTagRequest{From: from, To: to, Merge: merge})
+	if err != nil {
+		return err
+	}
+
+	for _, change := range resp.Changes {
+		fmt.Printf("  %s %s %s\n",
+			ui.FormatSuccess("Updated"),
+			change.Filename,
+			ui.FormatMuted(fmt.Sprintf("(%s → %s)", strings.Join(change.Before, ", "), strings.Join(change.After, ", "))))
+	}
+
+	action := "Renamed"
+	if merge {
+		action = "Merged"
+	}
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("%s tag '%s' → '%s' in %d files.", action, from, to, len(resp.Changes))))
+	return nil
+}
+
+func runTagTree(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	tree, err := tagService.Tree(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(tree) == 0 {
+		fmt.Println(ui.FormatInfo("No tags found"))
+		return nil
+	}
+
+	for _, node := range tree {
+		fmt.Printf("%s %s\n", ui.StyleAccent.Render(node.Name), ui.FormatMuted(fmt.Sprintf("(%d)", node.Total)))
+		printTagChildren(node.Children, "")
+	}
+	return nil
+}
+
+// printTagChildren draws nested tags with tree branches
+func printTagChildren(nodes []*domain.TagNode, indent string) {
+	for i, node := range nodes {
+		branch, next := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, next = "└── ", "    "
+		}
+		fmt.Printf("%s%s %s\n", ui.StyleMuted.Render(indent+branch), node.Name, ui.FormatMuted(fmt.Sprintf("(%d)", node.Total)))
+		printTagChildren(node.Children, indent+next)
+	}
 }
 
 func updateTags(query string, tagsInput string, isAdd bool) error {
@@ -135,21 +236,11 @@ func updateTags(query string, tagsInput string, isAdd bool) error {
 	}
 
 	// 5. Write Back
-	newTagLine := fmt.Sprintf("%%%% tags: %s", strings.Join(existingTags, ", "))
+	content = metadata.UpdateTags(content, existingTags)
 
-	if tagRegex.MatchString(content) {
-		content = tagRegex.ReplaceAllString(content, newTagLine)
-	} else {
-		// If tag line is missing, find the date line and append after it
-		dateRegex := regexp.MustCompile(`(?m)^%+\s*date:.*$`)
-		if dateRegex.MatchString(content) {
-			content = dateRegex.ReplaceAllString(content, "$0\n"+newTagLine)
-		} else {
-			// Fallback: Prepend to top
-			content = newTagLine + "\n" + content
-		}
+	if err := journal.Capture(path); err != nil {
+		return err
 	}
-
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to save file: %w", err)
 	}
@@ -1,12 +1,11 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
-	"regexp"
 	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/pkg/ui"
 
 	"github.com/charmbracelet/bubbles/table"
@@ -53,58 +52,30 @@ func runTodo(cmd *cobra.Command, args []string) error {
 	}
 
 	var todos []TodoItem
-	latexTodoRe := regexp.MustCompile(`\\todo\{([^}]+)\}`)
-	commentTodoRe := regexp.MustCompile(`%\s*TODO:\s*(.*)`)
 
 	fmt.Println(ui.FormatRocket("Scanning vault for tasks..."))
 
 	count := 0
 	for _, h := range headers {
 		path := appVault.GetNotePath(h.Filename)
-		file, err := os.Open(path)
+		content, err := os.ReadFile(path)
 		if err != nil {
 			continue
 		}
-
-		scanner := bufio.NewScanner(file)
-		lineNum := 0
-
-		for scanner.Scan() {
-			lineNum++
-			line := scanner.Text()
-			trimmed := strings.TrimSpace(line)
-
-			// 1. Check LaTeX \todo{}
-			if matches := latexTodoRe.FindStringSubmatch(trimmed); len(matches) > 1 {
-				if strings.HasPrefix(trimmed, "%") {
-					continue
-				} // Skip commented out
-				count++
-				todos = append(todos, TodoItem{
-					ID:       count,
-					Text:     matches[1],
-					Filename: h.Filename,
-					LineNum:  lineNum,
-					Original: line,
-					IsLatex:  true,
-				})
-				continue
-			}
-
-			// 2. Check Comments % TODO:
-			if matches := commentTodoRe.FindStringSubmatch(trimmed); len(matches) > 1 {
-				count++
-				todos = append(todos, TodoItem{
-					ID:       count,
-					Text:     matches[1],
-					Filename: h.Filename,
-					LineNum:  lineNum,
-					Original: line,
-					IsLatex:  false,
-				})
-			}
+		lines := strings.Split(string(content), "\n")
+
+		// Tasks in comments (other than "% TODO:") and verbatim blocks are skipped
+		for _, todo := range domain.ExtractTodos(string(content)) {
+			count++
+			todos = append(todos, TodoItem{
+				ID:       count,
+				Text:     todo.Text,
+				Filename: h.Filename,
+				LineNum:  todo.Line,
+				Original: lines[todo.Line-1],
+				IsLatex:  todo.Latex,
+			})
 		}
-		file.Close()
 	}
 
 	if len(todos) == 0 {
@@ -249,23 +220,32 @@ func markTaskDone(t TodoItem) {
 		return
 	}
 
-	lines := strings.Split(string(content), "\n")
-	if t.LineNum > len(lines) {
+	// Each task marked done is an operation of its own, for lx undo
+	journal.Begin("lx todo done", []string{t.Filename})
+	defer journal.Commit()
+	if journal.Capture(path) != nil {
 		return
 	}
 
-	original := lines[t.LineNum-1]
-	var replacement string
-
-	if t.IsLatex {
-		re := regexp.MustCompile(`\\todo\{([^}]+)\}`)
-		replacement = re.ReplaceAllString(original, "% DONE: $1")
-	} else {
-		replacement = strings.Replace(original, "TODO:", "DONE:", 1)
+	if !t.IsLatex {
+		lines := strings.Split(string(content), "\n")
+		if t.LineNum > len(lines) {
+			return
+		}
+		lines[t.LineNum-1] = strings.Replace(lines[t.LineNum-1], "TODO:", "DONE:", 1)
+		os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+		return
 	}
 
-	lines[t.LineNum-1] = replacement
-	os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+	// Comment out the \todo{...} command itself, which may contain nested braces
+	for _, todo := range domain.ExtractTodos(string(content)) {
+		if todo.Latex && todo.Line == t.LineNum && todo.Text == t.Text {
+			replacement := "% DONE: " + todo.Text
+			newContent := string(content[:todo.Start]) + replacement + string(content[todo.End:])
+			os.WriteFile(path, []byte(newContent), 0644)
+			return
+		}
+	}
 }
 
 func removeRow(rows []table.Row, i int) []table.Row {
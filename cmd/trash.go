@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	trashOlderThan string
+	trashForce     bool
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List, restore and empty deleted notes",
+	Long: `Deleted notes are moved to the .trash folder of the vault, together with
+the assets that only they used. The trash keeps where each note was and when
+it was deleted, until it is restored or the trash is emptied.`,
+	Example: `  lx trash
+  lx trash restore calculus
+  lx trash empty --older-than 30d`,
+	RunE: runTrashList,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the notes in the trash",
+	Args:    cobra.NoArgs,
+	RunE:    runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <slug|id>",
+	Short: "Restore a deleted note and its assets",
+	Long: `Move a deleted note back to where it was, along with the assets that were
+trashed with it.
+
+If the same slug was deleted more than once, the most recent deletion is
+restored; use the ID from lx trash list to pick another one.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete the notes in the trash",
+	Long: `Permanently delete the notes in the trash and their assets.
+Unlike other operations, emptying the trash cannot be undone with lx undo.
+
+With --older-than, only notes deleted longer ago than the given age are
+removed. Ages are written like 30d, 2w or 12h.`,
+	Example: `  lx trash empty
+  lx trash empty --older-than 30d`,
+	Args: cobra.NoArgs,
+	RunE: runTrashEmpty,
+}
+
+func init() {
+	trashEmptyCmd.Flags().StringVar(&trashOlderThan, "older-than", "", "Only delete notes trashed longer ago than this (e.g. 30d, 2w, 12h)")
+	trashEmptyCmd.Flags().BoolVarP(&trashForce, "force", "f", false, "Skip confirmation prompt")
+
+	trashCmd.AddCommand(trashListCmd)
+	trashCmd.AddCommand(trashRestoreCmd)
+	trashCmd.AddCommand(trashEmptyCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	items, err := trashService.List(ctx)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println(ui.FormatInfo("The trash is empty"))
+		return nil
+	}
+
+	fmt.Println(ui.FormatTitle(fmt.Sprintf("Trash (%d)", len(items))))
+	fmt.Println()
+	for _, item := range items {
+		fmt.Println(formatTrashItemLine(item))
+	}
+	fmt.Println()
+	fmt.Println(ui.FormatMuted("Restore a note with: lx trash restore <slug|id>"))
+
+	return nil
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	item, err := trashService.Restore(ctx, args[0])
+	if item == nil {
+		return err
+	}
+
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Restored: %s", item.Title)))
+	fmt.Println(ui.RenderKeyValue("File", item.Filename))
+	if len(item.Assets) > 0 {
+		fmt.Println(ui.RenderKeyValue("Assets", fmt.Sprintf("%d restored", len(item.Assets))))
+	}
+	if err != nil {
+		fmt.Println(ui.FormatWarning(err.Error()))
+	}
+
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	// 1. Parse the age
+	var olderThan time.Duration
+	if trashOlderThan != "" {
+		age, err := domain.ParseAge(trashOlderThan)
+		if err != nil {
+			return err
+		}
+		olderThan = age
+	}
+
+	// 2. Count what will be deleted
+	items, err := trashService.List(ctx)
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-olderThan)
+	count := 0
+	for _, item := range items {
+		if olderThan == 0 || !item.DeletedAt.After(cutoff) {
+			count++
+		}
+	}
+	if count == 0 {
+		fmt.Println(ui.FormatInfo("Nothing to empty"))
+		return nil
+	}
+
+	// 3. Confirm
+	if !trashForce {
+		reader := bufio.NewReader(os.Stdin)
+		fmt.Print(ui.StyleError.Render(fmt.Sprintf("Permanently delete %d note(s) from the trash? This cannot be undone (y/n): ", count)))
+		response, err := reader.ReadString('\n')
+		if err != nil || strings.ToLower(strings.TrimSpace(response)) != "y" {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	// 4. Empty
+	emptied, err := trashService.Empty(ctx, olderThan)
+	if len(emptied) > 0 {
+		fmt.Println(ui.FormatSuccess(fmt.Sprintf("Permanently deleted %d note(s)", len(emptied))))
+	}
+	return err
+}
+
+// formatTrashItemLine renders one trashed note as a single list line
+func formatTrashItemLine(item domain.TrashItem) string {
+	assets := ""
+	if len(item.Assets) > 0 {
+		assets = fmt.Sprintf("  +%d asset(s)", len(item.Assets))
+	}
+
+	return fmt.Sprintf("  %s  %-30s %s%s  %s",
+		item.DeletedAt.Format("2006-01-02 15:04"),
+		item.Slug,
+		ui.StyleBold.Render(item.Title),
+		assets,
+		ui.FormatMuted(item.ID),
+	)
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+)
+
+var (
+	undoForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the last operation that changed files",
+	Long: `Revert the last operation that changed files in the vault.
+
+Every command that changes notes, templates or assets records the files it
+touched in the vault's journal, with their content from before the change.
+lx undo writes that content back, and removes the files the operation
+created. Running it again undoes the operation before. This works whether
+or not git is enabled; see lx history ops for the recorded operations.
+
+If a file changed again since the operation, lx undo stops rather than
+throw that change away; use --force to undo anyway.`,
+	Example: `  lx tag rename physics science
+  lx undo
+  lx undo --force`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().BoolVarP(&undoForce, "force", "f", false, "Undo even if files changed since the operation")
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	ctx := getContext()
+
+	op, err := journalService.Undo(ctx, undoForce)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(ui.FormatSuccess("Undid: " + op.Description()))
+	for _, file := range op.Files {
+		action := "restored"
+		if !file.Existed {
+			action = "removed"
+		}
+		fmt.Printf("  %s %s\n", ui.StyleMuted.Render(fmt.Sprintf("%-8s", action)), file.Path)
+	}
+	fmt.Println(ui.FormatMuted(fmt.Sprintf("Operation %s, %s", op.ID, op.Time.Format("2006-01-02 15:04:05"))))
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kamal-hamza/lx-cli/pkg/ui"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Manage named vaults",
+	Long: `Keep notes in several vaults, such as personal, research and team notes.
+
+Vaults are registered by name in the global config. A command works on:
+  1. the vault named with --vault <name> (a path to a vault works too)
+  2. the vault named in $LX_VAULT
+  3. the vault the current folder is in
+  4. the vault chosen with lx vault use
+  5. the default vault at ~/.local/share/lx
+
+A vault can override global settings in an lx.yaml file at its root; keys
+missing from it keep their global value. Notes link to notes in other vaults
+with \lxnote{vault:slug}.`,
+	Example: `  lx vault add research ~/research/notes
+  lx vault use research
+  lx --vault team list
+  LX_VAULT=team lx build-all`,
+	RunE: runVaultList,
+}
+
+var vaultListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List the registered vaults",
+	Args:    cobra.NoArgs,
+	RunE:    runVaultList,
+}
+
+var vaultAddCmd = &cobra.Command{
+	Use:   "add <name> <path>",
+	Short: "Register a vault, creating it if needed",
+	Long: `Register a vault under a name.
+
+If the folder is not a vault yet, the vault structure is created in it,
+with an lx.yaml for settings that only apply to this vault.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runVaultAdd,
+}
+
+var vaultUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Choose the vault used when none is selected",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runVaultUse,
+}
+
+var vaultRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Unregister a vault, keeping its files",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runVaultRemove,
+}
+
+func init() {
+	vaultCmd.AddCommand(vaultListCmd)
+	vaultCmd.AddCommand(vaultAddCmd)
+	vaultCmd.AddCommand(vaultUseCmd)
+	vaultCmd.AddCommand(vaultRemoveCmd)
+}
+
+// isVaultCommand reports whether cmd manages vaults, which works without a selected vault
+func isVaultCommand(cmd *cobra.Command) bool {
+	return cmd.Name() == "vault" || (cmd.HasParent() && cmd.Parent().Name() == "vault")
+}
+
+func runVaultList(cmd *cobra.Command, args []string) error {
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+
+	// The default vault is listed first, unless nothing lives there
+	var vaults []*vault.Vault
+	if v, err := vault.New(); err == nil && (v.Exists() || len(cfg.Vaults) == 0) {
+		vaults = append(vaults, v)
+	}
+	for _, name := range vault.Names(cfg.Vaults) {
+		v, err := vault.Open(name, cfg.Vaults[name])
+		if err != nil {
+			return err
+		}
+		vaults = append(vaults, v)
+	}
+
+	active := ""
+	selected, selectErr := selectVault(cfg)
+	if selectErr == nil {
+		active = selected.RootPath
+	}
+	listed := false
+
+	width := 0
+	for _, v := range vaults {
+		width = max(width, len(v.Name))
+	}
+
+	fmt.Println(ui.FormatTitle("Vaults"))
+	fmt.Println()
+
+	for _, v := range vaults {
+		marker := " "
+		name := v.Name
+		if v.RootPath == active {
+			marker = "*"
+			name = ui.StyleSuccess.Render(name)
+			listed = true
+		}
+
+		status := ui.StyleError.Render("not found")
+		if v.Exists() {
+			files, _ := vault.ListNoteFiles(v.NotesPath)
+			status = fmt.Sprintf("%d note(s)", len(files))
+		}
+
+		fmt.Printf("  %s %s%s  %s  %s\n",
+			marker,
+			name,
+			strings.Repeat(" ", width-len(v.Name)),
+			ui.FormatMuted(v.RootPath),
+			status)
+	}
+	fmt.Println()
+
+	if selectErr != nil {
+		fmt.Println(ui.FormatWarning(selectErr.Error()))
+	} else if !listed {
+		fmt.Println(ui.FormatInfo("Using the unregistered vault at " + selected.RootPath))
+	}
+	return nil
+}
+
+func runVaultAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	// 1. Validate
+	if err := vault.ValidateName(name); err != nil {
+		return err
+	}
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if existing, ok := cfg.Vaults[name]; ok {
+		return fmt.Errorf("vault '%s' already exists at %s", name, existing)
+	}
+
+	v, err := vault.Open(name, args[1])
+	if err != nil {
+		return err
+	}
+	for _, other := range vault.Names(cfg.Vaults) {
+		if root, _ := filepath.Abs(cfg.Vaults[other]); root == v.RootPath {
+			return fmt.Errorf("%s is already registered as '%s'", v.RootPath, other)
+		}
+	}
+
+	// 2. Create the vault structure if the folder is not a vault yet
+	if _, err := os.Stat(v.NotesPath); os.IsNotExist(err) {
+		if err := v.Initialize(); err != nil {
+			return err
+		}
+		if err := createLocalConfig(v); err != nil {
+			return fmt.Errorf("failed to create vault config: %w", err)
+		}
+		// Editor and git helpers are optional, like in lx init
+		_ = createLatexmkrc(v)
+		_ = createTemplatesLatexmkrc(v)
+		_ = createGitignore(v)
+		fmt.Println(ui.FormatSuccess("Created vault structure in " + v.RootPath))
+	}
+
+	// 3. Register
+	cfg.Vaults[name] = v.RootPath
+	if err := cfg.Save(v.ConfigPath); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Added vault '%s'", name)))
+	fmt.Println(ui.FormatMuted(fmt.Sprintf("Use it with: lx --vault %s <command>, or lx vault use %s", name, name)))
+	return nil
+}
+
+func runVaultUse(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Vaults[name]; !ok && name != vault.DefaultName {
+		return fmt.Errorf("vault '%s' not found (see lx vault list)", name)
+	}
+
+	// The default vault is used when none is chosen
+	cfg.CurrentVault = name
+	if name == vault.DefaultName {
+		cfg.CurrentVault = ""
+	}
+	path, err := vault.GlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Save(path); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Using vault '%s'", name)))
+	if env := os.Getenv(vault.EnvVar); env != "" {
+		fmt.Println(ui.FormatWarning(fmt.Sprintf("%s=%s takes precedence while it is set", vault.EnvVar, env)))
+	}
+	return nil
+}
+
+func runVaultRemove(cmd *cobra.Command, args []string) error {
+	name := args[0]
+
+	cfg, err := loadGlobalConfig()
+	if err != nil {
+		return err
+	}
+	root, ok := cfg.Vaults[name]
+	if !ok {
+		return fmt.Errorf("vault '%s' not found (see lx vault list)", name)
+	}
+
+	delete(cfg.Vaults, name)
+	if cfg.CurrentVault == name {
+		cfg.CurrentVault = ""
+	}
+	path, err := vault.GlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Save(path); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Println(ui.FormatSuccess(fmt.Sprintf("Removed vault '%s'", name)))
+	fmt.Println(ui.FormatMuted("Its files were kept at " + root))
+	return nil
+}
+
+// createLocalConfig writes the lx.yaml of a new vault
+// It also marks the folder as a vault, so commands run inside it use it
+func createLocalConfig(v *vault.Vault) error {
+	content := `# LX Vault Configuration
+# Settings here override the global config for this vault only
+
+# compiler: "latexmk"
+# default_template: ""
+# aliases: {}
+`
+	return os.WriteFile(v.LocalConfigPath(), []byte(content), 0644)
+}
@@ -0,0 +1,112 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// buildTexInputs constructs the TEXINPUTS environment variable
+// This tells LaTeX where to find templates, assets, and other includes
+func buildTexInputs(v *vault.Vault) string {
+	// Format: .:templates//:assets//:notes//:
+	// The // means "search recursively"
+	// The trailing : means "also search default locations"
+
+	base := v.GetTexInputsEnv() // Usually returns ".:templates//:"
+
+	// Add assets and notes directories
+	parts := []string{
+		base,
+		v.AssetsPath + "//",
+		v.NotesPath + "//",
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// waitDelay bounds how long a cancelled command may keep its output pipes open
+const waitDelay = 2 * time.Second
+
+// newCommand creates a toolchain command that is killed, with all of its
+// children, when ctx is cancelled or its deadline expires
+func newCommand(ctx context.Context, binary string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = waitDelay
+	return cmd
+}
+
+// missingSourceResult builds the result returned when the input file does not exist
+func missingSourceResult(inputPath string) *domain.CompileResult {
+	message := fmt.Sprintf("source file not found: %s", inputPath)
+	return &domain.CompileResult{
+		Success: false,
+		Output:  message,
+		Parsed: &latexparser.ParseResult{
+			Errors: []latexparser.Issue{
+				{Level: latexparser.LevelError, Message: message},
+			},
+		},
+		ErrorCount: 1,
+	}
+}
+
+// newCompileResult parses raw compiler output and checks for the PDF on disk
+// The primary success criterion is: Does the PDF file exist?
+func newCompileResult(output string, pdfPath string) *domain.CompileResult {
+	parsed := latexparser.ParseLatexOutput(output)
+
+	pdfExists := pdfPath != "" && fileExists(pdfPath)
+	if pdfExists {
+		parsed.HasPDF = true
+		parsed.PDFPath = pdfPath
+	}
+
+	return &domain.CompileResult{
+		Success:    pdfExists,
+		Output:     output,
+		Parsed:     parsed,
+		PDFPath:    pdfPath,
+		ErrorCount: len(parsed.Errors),
+	}
+}
+
+// resultError converts a compile result into the error returned by Compile
+func resultError(result *domain.CompileResult) error {
+	if result.Success {
+		return nil
+	}
+
+	if result.Parsed != nil && result.Parsed.IsFatalError() {
+		return fmt.Errorf("compilation failed: %s", result.Parsed.GetSummary())
+	}
+
+	return fmt.Errorf("compilation failed: no PDF generated")
+}
+
+// outputPathFromInput derives the PDF path from the input .tex path
+func outputPathFromInput(inputPath string) string {
+	if !strings.HasSuffix(inputPath, ".tex") {
+		return ""
+	}
+
+	// Replace .tex extension with .pdf, keep same directory
+	return strings.TrimSuffix(inputPath, ".tex") + ".pdf"
+}
+
+// fileExists checks if a file exists and is a regular file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular()
+}
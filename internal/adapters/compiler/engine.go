@@ -0,0 +1,154 @@
+package compiler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// enginePasses is how many times a raw engine is run so that
+// cross-references and the table of contents settle
+const enginePasses = 2
+
+// EngineCompiler implements the Compiler port by invoking a TeX engine
+// (pdflatex, lualatex, xelatex) directly, without latexmk
+type EngineCompiler struct {
+	vault  *vault.Vault
+	engine string
+}
+
+// Ensure it implements the interface
+var _ ports.Compiler = (*EngineCompiler)(nil)
+
+// NewEngineCompiler creates a compiler that runs the given engine binary
+func NewEngineCompiler(vault *vault.Vault, engine string) *EngineCompiler {
+	return &EngineCompiler{
+		vault:  vault,
+		engine: engine,
+	}
+}
+
+// Compile compiles a preprocessed file to PDF
+func (c *EngineCompiler) Compile(ctx context.Context, inputPath string, opts domain.CompileOptions) error {
+	return resultError(c.CompileWithOutput(ctx, inputPath, opts))
+}
+
+// CompileWithOutput compiles and returns detailed output for better error reporting
+// A note's Engine option overrides the engine this compiler was created with
+func (c *EngineCompiler) CompileWithOutput(ctx context.Context, inputPath string, opts domain.CompileOptions) *domain.CompileResult {
+	if !fileExists(inputPath) {
+		return missingSourceResult(inputPath)
+	}
+
+	engine := c.engine
+	if opts.Engine != "" {
+		engine = opts.Engine
+	}
+
+	// Stale PDFs must not be mistaken for a successful run
+	pdfPath := outputPathFromInput(inputPath)
+	os.Remove(pdfPath)
+
+	// A bibliography run needs one extra pass to resolve its citations
+	runBib := opts.Bib == "biber" || opts.Bib == "bibtex"
+	passes := enginePasses
+	if runBib {
+		passes++
+	}
+
+	var output, bibOutput string
+	for pass := 0; pass < passes; pass++ {
+		out, err := c.run(ctx, engine, c.engineArgs(inputPath, opts), opts.Env)
+		output = out
+
+		// Stop early when the engine could not run at all or was cancelled
+		if ctx.Err() != nil {
+			break
+		}
+		if _, isExit := err.(*exec.ExitError); err != nil && !isExit {
+			break
+		}
+
+		// The bibliography is generated between the first and second pass
+		if pass == 0 && runBib {
+			bibOutput, _ = c.runBib(ctx, opts.Bib, inputPath, opts.Env)
+		}
+	}
+
+	if bibOutput != "" {
+		output = bibOutput + "\n" + output
+	}
+
+	result := newCompileResult(output, pdfPath)
+	result.Engine = engine
+	return result
+}
+
+// engineArgs builds the command line for a single engine pass
+func (c *EngineCompiler) engineArgs(inputPath string, opts domain.CompileOptions) []string {
+	args := []string{
+		"-interaction=nonstopmode",
+		"-file-line-error",
+		"-synctex=1",
+		"-output-directory=" + filepath.Dir(inputPath),
+	}
+	if opts.ShellEscape {
+		args = append(args, "-shell-escape")
+	}
+	return append(args, inputPath)
+}
+
+// runBib runs biber or bibtex on the auxiliary files next to the input
+func (c *EngineCompiler) runBib(ctx context.Context, tool string, inputPath string, env []string) (string, error) {
+	outDir := filepath.Dir(inputPath)
+	base := strings.TrimSuffix(filepath.Base(inputPath), ".tex")
+
+	var args []string
+	if tool == "biber" {
+		args = []string{"--input-directory", outDir, "--output-directory", outDir, base}
+	} else {
+		args = []string{filepath.Join(outDir, base)}
+	}
+
+	return c.run(ctx, tool, args, env)
+}
+
+// run executes a TeX toolchain binary from the notes directory
+func (c *EngineCompiler) run(ctx context.Context, binary string, args []string, env []string) (string, error) {
+	cmd := newCommand(ctx, binary, args...)
+	cmd.Dir = c.vault.NotesPath
+
+	cmdEnv := os.Environ()
+	cmdEnv = append(cmdEnv, "TEXINPUTS="+buildTexInputs(c.vault))
+	cmdEnv = append(cmdEnv, env...)
+	cmd.Env = cmdEnv
+
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
+
+// GetOutputPath returns the path to the compiled PDF for a given slug
+func (c *EngineCompiler) GetOutputPath(slug string) string {
+	return c.vault.GetCachePath(slug + ".pdf")
+}
+
+// Clean removes auxiliary files for a specific note
+func (c *EngineCompiler) Clean(ctx context.Context, slug string) error {
+	extensions := []string{".pdf", ".log", ".aux", ".out", ".toc", ".fls", ".synctex.gz"}
+
+	for _, ext := range extensions {
+		path := c.vault.GetCachePath(slug + ext)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("clean failed: %w", err)
+		}
+	}
+
+	return nil
+}
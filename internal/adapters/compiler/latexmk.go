@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/config"
-	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -18,6 +20,9 @@ type LatexmkCompiler struct {
 	config *config.Config
 }
 
+// Ensure it implements the interface
+var _ ports.Compiler = (*LatexmkCompiler)(nil)
+
 // NewLatexmkCompiler creates a new latexmk-based compiler
 func NewLatexmkCompiler(vault *vault.Vault, cfg *config.Config) *LatexmkCompiler {
 	return &LatexmkCompiler{
@@ -26,118 +31,65 @@ func NewLatexmkCompiler(vault *vault.Vault, cfg *config.Config) *LatexmkCompiler
 	}
 }
 
-// CompileResult holds compilation output and parsed issues
-type CompileResult struct {
-	Success    bool
-	Output     string
-	Parsed     *latexparser.ParseResult
-	PDFPath    string
-	ErrorCount int
-}
-
 // Compile compiles a note to PDF using latexmk
 // The primary success criterion is: Does the PDF file exist?
 // We use a multi-layered verification approach for maximum robustness
-func (c *LatexmkCompiler) Compile(ctx context.Context, inputPath string, env []string) error {
-	result := c.CompileWithOutput(ctx, inputPath, env)
-
-	// CRITICAL: Check if PDF actually exists on disk
-	// This is the most reliable success indicator
-	if result.PDFPath != "" && fileExists(result.PDFPath) {
-		// PDF exists! Compilation was successful.
-		// Even if latexmk returned errors, if we have a PDF, we succeeded.
-		return nil
-	}
-
-	// If we get here, no PDF was generated - this is a failure
-	if result.Parsed.IsFatalError() {
-		return fmt.Errorf("compilation failed: %s", result.Parsed.GetSummary())
-	}
-
-	// Fallback error message
-	return fmt.Errorf("compilation failed: no PDF generated")
+func (c *LatexmkCompiler) Compile(ctx context.Context, inputPath string, opts domain.CompileOptions) error {
+	// Even if latexmk returned errors, if we have a PDF, we succeeded.
+	return resultError(c.CompileWithOutput(ctx, inputPath, opts))
 }
 
 // CompileWithOutput compiles and returns detailed output for better error reporting
-func (c *LatexmkCompiler) CompileWithOutput(ctx context.Context, inputPath string, env []string) *CompileResult {
+func (c *LatexmkCompiler) CompileWithOutput(ctx context.Context, inputPath string, opts domain.CompileOptions) *domain.CompileResult {
 	// Validate input file exists
 	if !fileExists(inputPath) {
-		return &CompileResult{
-			Success: false,
-			Output:  fmt.Sprintf("source file not found: %s", inputPath),
-			Parsed: &latexparser.ParseResult{
-				Errors: []latexparser.Issue{
-					{
-						Level:   latexparser.LevelError,
-						Message: fmt.Sprintf("source file not found: %s", inputPath),
-					},
-				},
-			},
-			ErrorCount: 1,
-		}
+		return missingSourceResult(inputPath)
 	}
 
-	// Determine expected PDF output path
-	expectedPDFPath := c.GetOutputPathFromInput(inputPath)
-
 	// Run latexmk compilation
-	output, _ := c.runLatexmk(ctx, inputPath, env)
-
-	// Parse the output for errors and warnings
-	parsed := latexparser.ParseLatexOutput(output)
-
-	// CRITICAL: Verify PDF exists on disk (most reliable check)
-	pdfExists := fileExists(expectedPDFPath)
-	if pdfExists {
-		parsed.HasPDF = true
-		parsed.PDFPath = expectedPDFPath
-	}
+	output, _ := c.runLatexmk(ctx, inputPath, opts)
 
-	// Build result
-	result := &CompileResult{
-		Success:    pdfExists, // Success is defined by PDF existence
-		Output:     output,
-		Parsed:     parsed,
-		PDFPath:    expectedPDFPath,
-		ErrorCount: len(parsed.Errors),
+	// Parse the output and verify the PDF exists on disk (most reliable check)
+	result := newCompileResult(output, c.GetOutputPathFromInput(inputPath))
+	result.Engine = "latexmk"
+	if opts.Engine != "" {
+		result.Engine = fmt.Sprintf("latexmk (%s)", opts.Engine)
 	}
-
 	return result
 }
 
 // runLatexmk executes the latexmk command with proper configuration
-func (c *LatexmkCompiler) runLatexmk(ctx context.Context, inputPath string, env []string) (string, bool) {
-	// Start with flags from configuration (or defaults)
-	// This allows users to switch engines (e.g. use -xelatex instead of -pdf)
-	args := make([]string, len(c.config.LatexmkFlags))
-	copy(args, c.config.LatexmkFlags)
+func (c *LatexmkCompiler) runLatexmk(ctx context.Context, inputPath string, opts domain.CompileOptions) (string, bool) {
+	args := c.buildFlags(opts)
 
 	// Append mandatory flags for internal tool logic
 	// -g                : force rebuild (ignore timestamps)
 	// -f                : force completion even when errors occur
 	// -file-line-error  : better error messages
 	// -recorder         : track dependencies
+	// -synctex=1        : emit SyncTeX data for forward/inverse search
+	// -outdir           : keep the PDF next to the preprocessed input
 	mandatoryFlags := []string{
 		"-g",
 		"-f",
 		"-file-line-error",
 		"-recorder",
+		"-synctex=1",
+		"-outdir=" + filepath.Dir(inputPath),
 		inputPath,
 	}
 
 	args = append(args, mandatoryFlags...)
 
-	cmd := exec.CommandContext(ctx, "latexmk", args...)
+	cmd := newCommand(ctx, "latexmk", args...)
 
-	// ... [Rest of function remains the same] ...
 	// Set working directory to notes path
 	cmd.Dir = c.vault.NotesPath
 
 	// Prepare environment with TEXINPUTS
 	cmdEnv := os.Environ()
-	texinputs := c.buildTexInputs()
-	cmdEnv = append(cmdEnv, "TEXINPUTS="+texinputs)
-	cmdEnv = append(cmdEnv, env...)
+	cmdEnv = append(cmdEnv, "TEXINPUTS="+buildTexInputs(c.vault))
+	cmdEnv = append(cmdEnv, opts.Env...)
 	cmd.Env = cmdEnv
 
 	output, err := cmd.CombinedOutput()
@@ -148,23 +100,61 @@ func (c *LatexmkCompiler) runLatexmk(ctx context.Context, inputPath string, env
 	return outputStr, cmdSuccess
 }
 
-// buildTexInputs constructs the TEXINPUTS environment variable
-// This tells LaTeX where to find templates, assets, and other includes
-func (c *LatexmkCompiler) buildTexInputs() string {
-	// Format: .:templates//:assets//:notes//:
-	// The // means "search recursively"
-	// The trailing : means "also search default locations"
+// latexmkEngineFlags maps an engine name to the latexmk flag selecting it
+var latexmkEngineFlags = map[string]string{
+	"pdflatex": "-pdf",
+	"lualatex": "-lualatex",
+	"xelatex":  "-xelatex",
+}
+
+// buildFlags assembles the user-facing latexmk flags for one note
+// Per-note flags replace the configured ones; engine, shell-escape and
+// bibliography options are then layered on top
+func (c *LatexmkCompiler) buildFlags(opts domain.CompileOptions) []string {
+	// Start with flags from the note, falling back to configuration (or defaults)
+	// This allows users to switch engines (e.g. use -xelatex instead of -pdf)
+	base := c.config.LatexmkFlags
+	if len(opts.LatexmkFlags) > 0 {
+		base = opts.LatexmkFlags
+	}
+
+	args := make([]string, 0, len(base)+3)
+	for _, flag := range base {
+		// An explicit engine replaces whichever engine the flags selected
+		if opts.Engine != "" && isLatexmkEngineFlag(flag) {
+			continue
+		}
+		args = append(args, flag)
+	}
+
+	if flag, ok := latexmkEngineFlags[opts.Engine]; ok {
+		args = append(args, flag)
+	}
 
-	base := c.vault.GetTexInputsEnv() // Usually returns ".:templates//:"
+	if opts.ShellEscape {
+		args = append(args, "-shell-escape")
+	}
 
-	// Add assets and notes directories
-	parts := []string{
-		base,
-		c.vault.AssetsPath + "//",
-		c.vault.NotesPath + "//",
+	switch opts.Bib {
+	case "none":
+		args = append(args, "-bibtex-")
+	case "biber", "bibtex":
+		// latexmk picks biber or bibtex itself based on the .bcf/.aux files
+		args = append(args, "-bibtex")
 	}
 
-	return strings.Join(parts, ":")
+	return args
+}
+
+// isLatexmkEngineFlag reports whether a latexmk flag selects the TeX engine
+func isLatexmkEngineFlag(flag string) bool {
+	switch flag {
+	case "-pdf", "-pdflatex", "-pdflua", "-lualatex", "-pdfxe", "-xelatex":
+		return true
+	}
+	return strings.HasPrefix(flag, "-pdflatex=") ||
+		strings.HasPrefix(flag, "-lualatex=") ||
+		strings.HasPrefix(flag, "-xelatex=")
 }
 
 // GetOutputPath returns the path to the compiled PDF for a given slug
@@ -175,12 +165,7 @@ func (c *LatexmkCompiler) GetOutputPath(slug string) string {
 
 // GetOutputPathFromInput derives the PDF path from the input .tex path
 func (c *LatexmkCompiler) GetOutputPathFromInput(inputPath string) string {
-	if !strings.HasSuffix(inputPath, ".tex") {
-		return ""
-	}
-
-	// Replace .tex extension with .pdf, keep same directory
-	return strings.TrimSuffix(inputPath, ".tex") + ".pdf"
+	return outputPathFromInput(inputPath)
 }
 
 // Clean removes auxiliary files for a specific note
@@ -194,7 +179,7 @@ func (c *LatexmkCompiler) Clean(ctx context.Context, slug string) error {
 		targetPath,
 	}
 
-	cmd := exec.CommandContext(ctx, "latexmk", args...)
+	cmd := newCommand(ctx, "latexmk", args...)
 	cmd.Dir = c.vault.NotesPath
 
 	if err := cmd.Run(); err != nil {
@@ -209,12 +194,3 @@ func IsAvailable() bool {
 	_, err := exec.LookPath("latexmk")
 	return err == nil
 }
-
-// fileExists checks if a file exists and is a regular file
-func fileExists(path string) bool {
-	info, err := os.Stat(path)
-	if err != nil {
-		return false
-	}
-	return info.Mode().IsRegular()
-}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package compiler
+
+import "os/exec"
+
+// setProcessGroup is a no-op on Windows; cancellation kills the direct child only
+func setProcessGroup(cmd *exec.Cmd) {}
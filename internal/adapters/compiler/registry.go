@@ -0,0 +1,126 @@
+package compiler
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/config"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// DefaultCompiler is used when the config does not name a compiler
+const DefaultCompiler = "latexmk"
+
+// Factory constructs a compiler adapter for a vault
+type Factory func(v *vault.Vault, cfg *config.Config) ports.Compiler
+
+// registration describes a compiler known to the registry
+type registration struct {
+	factory Factory
+	binary  string // Executable that must be on PATH ("" = adapter handles it)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]registration{}
+)
+
+func init() {
+	Register("latexmk", "latexmk", func(v *vault.Vault, cfg *config.Config) ports.Compiler {
+		return NewLatexmkCompiler(v, cfg)
+	})
+
+	// Tectonic offers to install itself on first use, so no binary is required up front
+	Register("tectonic", "", func(v *vault.Vault, cfg *config.Config) ports.Compiler {
+		return NewTectonicCompiler(v)
+	})
+
+	for _, engine := range []string{"pdflatex", "lualatex", "xelatex"} {
+		Register(engine, engine, func(v *vault.Vault, cfg *config.Config) ports.Compiler {
+			return NewEngineCompiler(v, engine)
+		})
+	}
+}
+
+// Register adds (or replaces) a compiler under the given name
+// binary is the executable checked by Available; leave empty to skip the check
+func Register(name string, binary string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[strings.ToLower(name)] = registration{
+		factory: factory,
+		binary:  binary,
+	}
+}
+
+// New creates the compiler registered under name
+// An empty name selects DefaultCompiler
+func New(name string, v *vault.Vault, cfg *config.Config) (ports.Compiler, error) {
+	reg, err := lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return reg.factory(v, cfg), nil
+}
+
+// Available checks whether the executable needed by a compiler is installed
+func Available(name string) bool {
+	reg, err := lookup(name)
+	if err != nil {
+		return false
+	}
+	if reg.binary == "" {
+		return true
+	}
+	_, err = exec.LookPath(reg.binary)
+	return err == nil
+}
+
+// Binary returns the executable a compiler depends on (empty if none)
+func Binary(name string) string {
+	reg, err := lookup(name)
+	if err != nil {
+		return ""
+	}
+	return reg.binary
+}
+
+// Names returns all registered compiler names in sorted order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return namesLocked()
+}
+
+// lookup resolves a compiler name (case-insensitive) to its registration
+func lookup(name string) (registration, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultCompiler
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	reg, ok := registry[name]
+	if !ok {
+		return registration{}, fmt.Errorf("unknown compiler '%s' (available: %s)", name, strings.Join(namesLocked(), ", "))
+	}
+	return reg, nil
+}
+
+// namesLocked lists registered names; caller must hold registryMu
+func namesLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
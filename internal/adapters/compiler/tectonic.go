@@ -11,6 +11,9 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -20,6 +23,9 @@ type TectonicCompiler struct {
 	binaryPath string // Path to tectonic binary
 }
 
+// Ensure it implements the interface
+var _ ports.Compiler = (*TectonicCompiler)(nil)
+
 // NewTectonicCompiler creates a new Tectonic-based compiler
 func NewTectonicCompiler(vault *vault.Vault) *TectonicCompiler {
 	return &TectonicCompiler{
@@ -28,90 +34,85 @@ func NewTectonicCompiler(vault *vault.Vault) *TectonicCompiler {
 	}
 }
 
-// Compile compiles a note to PDF using Tectonic
-func (c *TectonicCompiler) Compile(ctx context.Context, slug string, env []string) error {
+// Compile compiles a preprocessed file to PDF using Tectonic
+func (c *TectonicCompiler) Compile(ctx context.Context, inputPath string, opts domain.CompileOptions) error {
+	return resultError(c.CompileWithOutput(ctx, inputPath, opts))
+}
+
+// CompileWithOutput compiles and returns detailed output for better error reporting
+// Tectonic always uses its bundled XeTeX engine and runs BibTeX itself,
+// so only the ShellEscape option applies here
+func (c *TectonicCompiler) CompileWithOutput(ctx context.Context, inputPath string, opts domain.CompileOptions) *domain.CompileResult {
 	// Ensure Tectonic is available
 	if err := c.ensureTectonic(ctx); err != nil {
-		return err
+		return &domain.CompileResult{
+			Success: false,
+			Engine:  "tectonic",
+			Output:  err.Error(),
+			Parsed: &latexparser.ParseResult{
+				Errors: []latexparser.Issue{
+					{Level: latexparser.LevelError, Message: err.Error()},
+				},
+			},
+			ErrorCount: 1,
+		}
 	}
 
-	// Find the source file
-	sourceFile, err := c.findSourceFile(slug)
-	if err != nil {
-		return err
+	if !fileExists(inputPath) {
+		return missingSourceResult(inputPath)
 	}
 
-	sourcePath := c.vault.GetNotePath(sourceFile)
-
 	// Prepare Tectonic command
-	// -X compile: Use the V2 CLI interface
-	// --outdir: Output directory for PDF
+	// --outdir: Output directory for PDF (next to the preprocessed input)
 	// --keep-logs: Keep log files for debugging
+	// --synctex: Emit SyncTeX data for forward/inverse search
+	// -Z search-path: Tectonic ignores TEXINPUTS, so vault folders are passed explicitly
 	args := []string{
-		"-X", "compile",
-		sourcePath,
-		"--outdir", c.vault.CachePath,
+		inputPath,
+		"--outdir", filepath.Dir(inputPath),
 		"--keep-logs",
+		"--synctex",
+		"-Z", "search-path=" + c.vault.TemplatesPath,
+		"-Z", "search-path=" + c.vault.AssetsPath,
+		"-Z", "search-path=" + c.vault.NotesPath,
+	}
+	if opts.ShellEscape {
+		args = append(args, "-Z", "shell-escape")
 	}
 
-	cmd := exec.CommandContext(ctx, c.binaryPath, args...)
+	cmd := newCommand(ctx, c.binaryPath, args...)
 
-	// Set working directory to cache
-	cmd.Dir = c.vault.CachePath
+	// Set working directory to notes path so relative includes resolve
+	cmd.Dir = c.vault.NotesPath
 
 	// Prepare environment
 	cmdEnv := os.Environ()
-
-	// Add TEXINPUTS for template discovery
-	texinputs := c.vault.GetTexInputsEnv()
-	cmdEnv = append(cmdEnv, "TEXINPUTS="+texinputs)
-
-	// Add any additional environment variables
-	cmdEnv = append(cmdEnv, env...)
-
+	cmdEnv = append(cmdEnv, "TEXINPUTS="+buildTexInputs(c.vault))
+	cmdEnv = append(cmdEnv, opts.Env...)
 	cmd.Env = cmdEnv
 
-	// Capture output
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("compilation failed: %w\nOutput: %s", err, string(output))
-	}
+	// Capture output; success is judged by the PDF on disk
+	output, _ := cmd.CombinedOutput()
 
-	return nil
+	result := newCompileResult(string(output), outputPathFromInput(inputPath))
+	result.Engine = "tectonic"
+	return result
 }
 
 // GetOutputPath returns the path to the compiled PDF
 func (c *TectonicCompiler) GetOutputPath(slug string) string {
-	// Find the source file to get the base name
-	sourceFile, err := c.findSourceFile(slug)
-	if err != nil {
-		// Fallback to constructing from slug
-		return c.vault.GetCachePath(slug + ".pdf")
-	}
-
-	// Replace .tex with .pdf
-	pdfName := strings.TrimSuffix(sourceFile, ".tex") + ".pdf"
-	return c.vault.GetCachePath(pdfName)
+	// The preprocessor writes "slug.tex" to cache, so output is "slug.pdf" in cache
+	return c.vault.GetCachePath(slug + ".pdf")
 }
 
 // Clean removes auxiliary files for a specific note
 func (c *TectonicCompiler) Clean(ctx context.Context, slug string) error {
 	// Tectonic doesn't create as many auxiliary files as latexmk,
 	// but we still need to clean up logs and the PDF
-
-	sourceFile, err := c.findSourceFile(slug)
-	if err != nil {
-		return err
-	}
-
-	baseName := strings.TrimSuffix(sourceFile, ".tex")
-
-	// Files to remove
-	extensions := []string{".pdf", ".log", ".aux", ".out", ".toc"}
+	extensions := []string{".pdf", ".log", ".aux", ".out", ".toc", ".synctex.gz"}
 
 	for _, ext := range extensions {
-		filename := baseName + ext
-		path := c.vault.GetCachePath(filename)
+		path := c.vault.GetCachePath(slug + ext)
 
 		// Ignore errors if file doesn't exist
 		os.Remove(path)
@@ -252,28 +253,6 @@ func (c *TectonicCompiler) installTectonic(ctx context.Context) error {
 	return nil
 }
 
-// findSourceFile finds the .tex file matching the slug
-func (c *TectonicCompiler) findSourceFile(slug string) (string, error) {
-	entries, err := os.ReadDir(c.vault.NotesPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read notes directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tex") {
-			continue
-		}
-
-		// Check if filename contains the slug
-		name := strings.TrimSuffix(entry.Name(), ".tex")
-		if strings.HasSuffix(name, slug) || strings.Contains(name, "-"+slug) {
-			return entry.Name(), nil
-		}
-	}
-
-	return "", fmt.Errorf("source file not found for slug: %s", slug)
-}
-
 // IsTectonicAvailable checks if Tectonic can be used
 func IsTectonicAvailable() bool {
 	// Check system PATH
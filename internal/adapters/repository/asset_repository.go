@@ -9,6 +9,7 @@ import (
 	"sync"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -17,6 +18,9 @@ type FileAssetRepository struct {
 	manifestPath string
 	mu           sync.RWMutex
 	cache        map[string]domain.Asset
+
+	// Records before-images of the manifest, if set
+	journal ports.Journal
 }
 
 func NewFileAssetRepository(v *vault.Vault) *FileAssetRepository {
@@ -27,6 +31,11 @@ func NewFileAssetRepository(v *vault.Vault) *FileAssetRepository {
 	}
 }
 
+// SetJournal records the changes to the manifest, so operations can be undone
+func (r *FileAssetRepository) SetJournal(journal ports.Journal) {
+	r.journal = journal
+}
+
 // Load reads the manifest from disk
 func (r *FileAssetRepository) Load() error {
 	r.mu.Lock()
@@ -66,6 +75,9 @@ func (r *FileAssetRepository) flush() error {
 	if err != nil {
 		return err
 	}
+	if err := capture(r.journal, r.manifestPath); err != nil {
+		return err
+	}
 
 	return os.WriteFile(r.manifestPath, data, 0644)
 }
@@ -131,8 +143,8 @@ func (r *FileAssetRepository) Delete(ctx context.Context, filename string) error
 	}
 
 	r.mu.Lock()
-	defer r.mu.Unlock()
-
 	delete(r.cache, filename)
+	r.mu.Unlock()
+
 	return r.flush()
 }
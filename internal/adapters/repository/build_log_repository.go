@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// FileBuildLogRepository stores build history under cache/builds/<slug>/
+// Each build is a <id>.json record plus a <id>.log file with the raw output
+type FileBuildLogRepository struct {
+	root string
+	keep int // Builds kept per note (0 keeps everything)
+	mu   sync.Mutex
+}
+
+// Ensure it implements the interface
+var _ ports.BuildLogRepository = (*FileBuildLogRepository)(nil)
+
+// NewFileBuildLogRepository creates a build history store in the vault cache
+func NewFileBuildLogRepository(v *vault.Vault, keep int) *FileBuildLogRepository {
+	return &FileBuildLogRepository{
+		root: v.GetCachePath("builds"),
+		keep: keep,
+	}
+}
+
+// Save stores a build record and prunes the oldest builds of that note
+func (r *FileBuildLogRepository) Save(ctx context.Context, record *domain.BuildRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := filepath.Join(r.root, record.Slug)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create build log directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal build record: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, record.ID+".log"), []byte(record.Output), 0644); err != nil {
+		return fmt.Errorf("failed to write build log: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, record.ID+".json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write build record: %w", err)
+	}
+
+	return r.prune(dir)
+}
+
+// prune removes the oldest builds beyond the retention limit
+func (r *FileBuildLogRepository) prune(dir string) error {
+	if r.keep <= 0 {
+		return nil
+	}
+
+	ids, err := recordIDs(dir)
+	if err != nil {
+		return err
+	}
+
+	// IDs are timestamps, so lexical order is chronological
+	for len(ids) > r.keep {
+		oldest := ids[0]
+		ids = ids[1:]
+		os.Remove(filepath.Join(dir, oldest+".json"))
+		os.Remove(filepath.Join(dir, oldest+".log"))
+	}
+
+	return nil
+}
+
+// List returns the most recent builds, newest first
+func (r *FileBuildLogRepository) List(ctx context.Context, slug string, limit int) ([]domain.BuildRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var dirs []string
+	if slug != "" {
+		dirs = []string{filepath.Join(r.root, slug)}
+	} else {
+		// Qualified slugs such as courses/math201/limits are stored in nested folders
+		err := filepath.WalkDir(r.root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() && path != r.root {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			if os.IsNotExist(err) {
+				return []domain.BuildRecord{}, nil
+			}
+			return nil, fmt.Errorf("failed to read build logs: %w", err)
+		}
+	}
+
+	records := []domain.BuildRecord{}
+	for _, dir := range dirs {
+		ids, err := recordIDs(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			record, err := readRecord(dir, id)
+			if err != nil {
+				continue // Skip corrupt records
+			}
+			records = append(records, *record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Timestamp.After(records[j].Timestamp)
+	})
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	return records, nil
+}
+
+// Get retrieves a single build including its raw log
+func (r *FileBuildLogRepository) Get(ctx context.Context, slug string, id string) (*domain.BuildRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir := filepath.Join(r.root, slug)
+	record, err := readRecord(dir, id)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := os.ReadFile(filepath.Join(dir, id+".log"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read build log: %w", err)
+	}
+	record.Output = string(output)
+
+	return record, nil
+}
+
+// recordIDs lists the build IDs stored in a note's directory, oldest first
+func recordIDs(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read build logs: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if id, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// readRecord loads a build record without its raw log
+func readRecord(dir string, id string) (*domain.BuildRecord, error) {
+	data, err := os.ReadFile(filepath.Join(dir, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("build not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to read build record: %w", err)
+	}
+
+	var record domain.BuildRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal build record: %w", err)
+	}
+
+	return &record, nil
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -18,6 +20,13 @@ import (
 type FileRepository struct {
 	vault *vault.Vault
 	mu    sync.RWMutex
+
+	// Slug-to-file lookup table, cached on disk and checked against directory mtimes
+	indexMu sync.Mutex
+	index   *domain.SlugIndex
+
+	// Records before-images of the files it changes, if set
+	journal ports.Journal
 }
 
 // NewFileRepository creates a new file-based repository
@@ -30,37 +39,18 @@ func NewFileRepository(vault *vault.Vault) *FileRepository {
 // Ensure it implements the interface
 var _ ports.Repository = (*FileRepository)(nil)
 
-// ListHeaders returns all note headers
+// SetJournal records the files the repository changes, so operations can be undone
+func (r *FileRepository) SetJournal(journal ports.Journal) {
+	r.journal = journal
+}
+
+// ListHeaders returns all note headers, including the notes inside notebooks
+// Headers are cached in the slug index; only files modified since are read again
 func (r *FileRepository) ListHeaders(ctx context.Context) ([]domain.NoteHeader, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	entries, err := os.ReadDir(r.vault.NotesPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read notes directory: %w", err)
-	}
-
-	var notes []domain.NoteHeader
-	for _, entry := range entries {
-		if entry.IsDir() || filepath.Ext(entry.Name()) != ".tex" {
-			continue
-		}
-
-		info, err := entry.Info()
-		if err != nil {
-			continue
-		}
-
-		// Read the file header
-		path := filepath.Join(r.vault.NotesPath, entry.Name())
-		header, err := r.readHeader(path, entry.Name(), info)
-		if err != nil {
-			continue
-		}
-		notes = append(notes, *header)
-	}
-
-	return notes, nil
+	return r.headers()
 }
 
 // Get retrieves a note by its slug
@@ -69,13 +59,17 @@ func (r *FileRepository) Get(ctx context.Context, slug string) (*domain.NoteBody
 	defer r.mu.RUnlock()
 
 	// 1. Find the file associated with the slug
-	filename, err := r.findFilenameBySlug(slug)
+	file, err := r.findBySlug(slug)
 	if err != nil {
 		return nil, err
 	}
 
 	// 2. Read file content
-	path := filepath.Join(r.vault.NotesPath, filename)
+	path := r.vault.GetNotePath(filepath.FromSlash(file.filename))
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %w", err)
+	}
 	contentBytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
@@ -93,8 +87,10 @@ func (r *FileRepository) Get(ctx context.Context, slug string) (*domain.NoteBody
 		Title:    meta.Title,
 		Date:     meta.Date,
 		Tags:     meta.Tags,
-		Slug:     slug,
-		Filename: filename,
+		Slug:     file.slug,
+		Filename: file.filename,
+		Notebook: domain.NotebookOf(file.filename),
+		ModTime:  info.ModTime(),
 	}
 
 	return &domain.NoteBody{
@@ -108,30 +104,34 @@ func (r *FileRepository) Save(ctx context.Context, note *domain.NoteBody) error
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	path := filepath.Join(r.vault.NotesPath, note.Header.Filename)
+	defer r.invalidate()
+
+	path := r.vault.GetNotePath(filepath.FromSlash(note.Header.Filename))
+	if err := capture(r.journal, path); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create notebook directory: %w", err)
+	}
 	return os.WriteFile(path, []byte(note.Content), 0644)
 }
 
-// Delete removes a note
+// Delete moves a note to the trash, from where it can be restored
 func (r *FileRepository) Delete(ctx context.Context, slug string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	filename, err := r.findFilenameBySlug(slug)
-	if err != nil {
-		return err
-	}
-
-	path := filepath.Join(r.vault.NotesPath, filename)
-	return os.Remove(path)
+	_, err := r.trash(slug, nil)
+	return err
 }
 
 // Exists checks if a slug exists
+// A slug shared by several notes exists, even though it cannot be used to read them
 func (r *FileRepository) Exists(ctx context.Context, slug string) bool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
-	_, err := r.findFilenameBySlug(slug)
-	return err == nil
+	_, err := r.findBySlug(slug)
+	return err == nil || errors.Is(err, domain.ErrAmbiguousSlug) || errors.Is(err, domain.ErrDuplicateSlug)
 }
 
 // Rename updates a note's title and filename
@@ -140,13 +140,15 @@ func (r *FileRepository) Rename(ctx context.Context, oldSlug string, newTitle st
 	defer r.mu.Unlock()
 
 	// 1. Find existing file
-	oldFilename, err := r.findFilenameBySlug(oldSlug)
+	file, err := r.findBySlug(oldSlug)
 	if err != nil {
 		return err
 	}
+	oldFilename := file.filename
+	defer r.invalidate()
 
 	// 2. Read existing content
-	oldPath := filepath.Join(r.vault.NotesPath, oldFilename)
+	oldPath := r.vault.GetNotePath(filepath.FromSlash(oldFilename))
 	contentBytes, err := os.ReadFile(oldPath)
 	if err != nil {
 		return fmt.Errorf("failed to read original file: %w", err)
@@ -155,20 +157,23 @@ func (r *FileRepository) Rename(ctx context.Context, oldSlug string, newTitle st
 
 	// 3. Generate new slug
 	newSlug := domain.GenerateSlug(newTitle)
-	if newSlug == oldSlug {
+	if newSlug == domain.ParseFilename(path.Base(oldFilename)) {
 		// Just update title in metadata, keep filename
 		newContent, err := metadata.UpdateTitle(content, newTitle)
 		if err != nil {
 			return err
 		}
+		if err := capture(r.journal, oldPath); err != nil {
+			return err
+		}
 		return os.WriteFile(oldPath, []byte(newContent), 0644)
 	}
 
-	// 4. Generate new filename (PRESERVING DATE)
-	newFilename := preserveDatePrefix(oldFilename, newSlug)
+	// 4. Generate new filename (PRESERVING DATE), in the same notebook
+	newFilename := path.Join(path.Dir(oldFilename), preserveDatePrefix(path.Base(oldFilename), newSlug))
 
 	// Ensure new filename doesn't already exist
-	newPath := filepath.Join(r.vault.NotesPath, newFilename)
+	newPath := r.vault.GetNotePath(filepath.FromSlash(newFilename))
 	if _, err := os.Stat(newPath); err == nil && newFilename != oldFilename {
 		return fmt.Errorf("destination filename already exists: %s", newFilename)
 	}
@@ -180,6 +185,9 @@ func (r *FileRepository) Rename(ctx context.Context, oldSlug string, newTitle st
 	}
 
 	// 6. Write new file
+	if err := capture(r.journal, oldPath, newPath); err != nil {
+		return err
+	}
 	if err := os.WriteFile(newPath, []byte(newContent), 0644); err != nil {
 		return fmt.Errorf("failed to write new file: %w", err)
 	}
@@ -192,62 +200,70 @@ func (r *FileRepository) Rename(ctx context.Context, oldSlug string, newTitle st
 	return nil
 }
 
-// -----------------------------------------------------------------------------
-// Helpers
-// -----------------------------------------------------------------------------
+// Move puts a note in another notebook, keeping its filename
+// The empty notebook is the top level of the notes directory. Returns the new filename
+func (r *FileRepository) Move(ctx context.Context, slug string, notebook string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-func (r *FileRepository) findFilenameBySlug(targetSlug string) (string, error) {
-	entries, err := os.ReadDir(r.vault.NotesPath)
-	if err != nil {
+	// 1. Validate the destination
+	if err := domain.ValidateNotebook(notebook); err != nil {
 		return "", err
 	}
+	notebook = domain.NormalizeNotebook(notebook)
 
-	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".tex" {
-			slug := domain.ParseFilename(entry.Name())
-			if slug == targetSlug {
-				return entry.Name(), nil
-			}
-		}
+	// 2. Find existing file
+	file, err := r.findBySlug(slug)
+	if err != nil {
+		return "", err
+	}
+	if domain.NotebookOf(file.filename) == notebook {
+		return "", fmt.Errorf("note is already in %s", notebookName(notebook))
 	}
 
-	return "", fmt.Errorf("note not found: %s", targetSlug)
-}
-
-func (r *FileRepository) readHeader(path string, filename string, info os.FileInfo) (*domain.NoteHeader, error) {
-	// Read first 1KB for metadata to be fast
-	f, err := os.Open(path)
+	// 3. Ensure the destination is free, and has no note with the same slug
+	newFilename := path.Join(notebook, path.Base(file.filename))
+	newPath := r.vault.GetNotePath(filepath.FromSlash(newFilename))
+	if _, err := os.Stat(newPath); err == nil {
+		return "", fmt.Errorf("destination filename already exists: %s", newFilename)
+	}
+	headers, err := r.headers()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer f.Close()
-
-	buf := make([]byte, 1024)
-	n, err := f.Read(buf)
-	if err != nil && n == 0 {
-		return nil, err
+	slug = domain.ParseFilename(path.Base(file.filename))
+	for _, other := range headers {
+		if other.Notebook == notebook && domain.ParseFilename(path.Base(other.Filename)) == slug {
+			return "", fmt.Errorf("%s already has a note named %s: %s", notebookName(notebook), slug, other.Filename)
+		}
 	}
-	content := string(buf[:n])
 
-	// Use Extract instead of Parse
-	meta, err := metadata.Extract(content)
-	if err != nil {
-		// Fallback for files without valid metadata
-		return &domain.NoteHeader{
-			Title:    domain.ParseFilename(filename),
-			Date:     info.ModTime().Format("2006-01-02"),
-			Slug:     domain.ParseFilename(filename),
-			Filename: filename,
-		}, nil
+	// 4. Move the file, creating the notebook if needed
+	oldPath := r.vault.GetNotePath(filepath.FromSlash(file.filename))
+	if err := capture(r.journal, oldPath, newPath); err != nil {
+		return "", err
+	}
+	defer r.invalidate()
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create notebook directory: %w", err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return "", fmt.Errorf("failed to move note: %w", err)
 	}
 
-	return &domain.NoteHeader{
-		Title:    meta.Title,
-		Date:     meta.Date,
-		Tags:     meta.Tags,
-		Slug:     domain.ParseFilename(filename),
-		Filename: filename,
-	}, nil
+	return newFilename, nil
+}
+
+// -----------------------------------------------------------------------------
+// Helpers
+// -----------------------------------------------------------------------------
+
+// notebookName names a notebook in messages
+func notebookName(notebook string) string {
+	if notebook == "" {
+		return "the top level"
+	}
+	return notebook
 }
 
 func preserveDatePrefix(oldFilename, newSlug string) string {
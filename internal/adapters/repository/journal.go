@@ -0,0 +1,411 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// Ensure it implements the interface
+var _ ports.Journal = (*FileJournal)(nil)
+
+// operationRecordName is the record of an operation, next to its before-images:
+//
+//	.journal/<id>/operation.json
+//	.journal/<id>/files/<n>
+const operationRecordName = "operation.json"
+
+// journalLimit is the number of operations kept; older ones are dropped
+const journalLimit = 50
+
+// FileJournal keeps the journal of operations in the vault
+type FileJournal struct {
+	vault *vault.Vault
+	mu    sync.Mutex
+
+	// Operation in progress and its folder, created on the first capture
+	current  *domain.Operation
+	dir      string
+	captured map[string]bool
+}
+
+// NewFileJournal creates a journal for a vault
+func NewFileJournal(v *vault.Vault) *FileJournal {
+	return &FileJournal{vault: v}
+}
+
+// Begin starts recording an operation, committing the one in progress
+func (j *FileJournal) Begin(command string, args []string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	_, _ = j.commit()
+	j.current = &domain.Operation{
+		Command: command,
+		Args:    args,
+		Time:    time.Now(),
+	}
+	j.captured = make(map[string]bool)
+}
+
+// Capture records the before-image of files about to change
+// The record is written on every capture, so a crash mid-operation can still be undone
+func (j *FileJournal) Capture(paths ...string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.current == nil {
+		return nil
+	}
+
+	for _, p := range paths {
+		rel, ok := j.relPath(p)
+		if !ok || j.captured[rel] {
+			continue
+		}
+
+		// 1. Make a folder for the operation
+		if j.dir == "" {
+			dir, err := j.newOperationDir()
+			if err != nil {
+				return err
+			}
+			j.dir = dir
+		}
+
+		// 2. Copy the file, if it exists
+		image := domain.FileImage{Path: rel}
+		data, err := os.ReadFile(p)
+		switch {
+		case err == nil:
+			image.Existed = true
+			image.Blob = filepath.ToSlash(filepath.Join("files", strconv.Itoa(len(j.current.Files))))
+			if err := os.MkdirAll(filepath.Join(j.dir, "files"), 0755); err != nil {
+				return fmt.Errorf("failed to create journal directory: %w", err)
+			}
+			if err := os.WriteFile(filepath.Join(j.dir, filepath.FromSlash(image.Blob)), data, 0644); err != nil {
+				return fmt.Errorf("failed to journal %s: %w", rel, err)
+			}
+		case !os.IsNotExist(err):
+			return fmt.Errorf("failed to journal %s: %w", rel, err)
+		}
+
+		// 3. Record it
+		j.captured[rel] = true
+		j.current.Files = append(j.current.Files, image)
+		if err := writeOperationRecord(j.dir, j.current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Commit finishes the operation in progress, recording what its files look like now
+func (j *FileJournal) Commit() (*domain.Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.commit()
+}
+
+// commit finishes the operation in progress; callers hold mu
+func (j *FileJournal) commit() (*domain.Operation, error) {
+	op, dir := j.current, j.dir
+	j.current, j.dir, j.captured = nil, "", nil
+	if op == nil || dir == "" {
+		return nil, nil
+	}
+
+	for i := range op.Files {
+		op.Files[i].After = j.hashFile(op.Files[i].Path)
+	}
+	if err := writeOperationRecord(dir, op); err != nil {
+		return nil, err
+	}
+	if err := j.prune(); err != nil {
+		return op, err
+	}
+	return op, nil
+}
+
+// List returns the recorded operations, most recent first
+func (j *FileJournal) List(ctx context.Context) ([]domain.Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.list()
+}
+
+// list reads the operations; callers hold mu
+func (j *FileJournal) list() ([]domain.Operation, error) {
+	entries, err := os.ReadDir(j.vault.JournalPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var ops []domain.Operation
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		op, err := readOperationRecord(filepath.Join(j.vault.JournalPath(), entry.Name()))
+		if err != nil {
+			// Folders without a record hold no before-image yet
+			continue
+		}
+		ops = append(ops, *op)
+	}
+
+	sort.SliceStable(ops, func(a, b int) bool {
+		return ops[a].Time.After(ops[b].Time)
+	})
+	return ops, nil
+}
+
+// Modified returns the files of an operation that changed since it ran
+func (j *FileJournal) Modified(ctx context.Context, id string) ([]string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	dir, err := j.operationDir(id)
+	if err != nil {
+		return nil, err
+	}
+	op, err := readOperationRecord(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var modified []string
+	for _, image := range op.Files {
+		if j.hashFile(image.Path) != image.After {
+			modified = append(modified, image.Path)
+		}
+	}
+	return modified, nil
+}
+
+// Revert writes the before-images of an operation back and marks it undone
+// Files the operation created are removed, with the folders they leave empty
+func (j *FileJournal) Revert(ctx context.Context, id string) (*domain.Operation, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	// 1. Read the record
+	dir, err := j.operationDir(id)
+	if err != nil {
+		return nil, err
+	}
+	op, err := readOperationRecord(dir)
+	if err != nil {
+		return nil, err
+	}
+	if op.Undone() {
+		return nil, fmt.Errorf("operation %s was already undone", id)
+	}
+
+	// 2. Put every file back, the last captured first
+	for i := len(op.Files) - 1; i >= 0; i-- {
+		image := op.Files[i]
+		target := filepath.Join(j.vault.RootPath, filepath.FromSlash(image.Path))
+
+		if !image.Existed {
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return nil, fmt.Errorf("failed to remove %s: %w", image.Path, err)
+			}
+			j.removeEmptyParents(target)
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(image.Blob)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read before-image of %s: %w", image.Path, err)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", image.Path, err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", image.Path, err)
+		}
+	}
+
+	// 3. Mark it undone
+	now := time.Now()
+	op.UndoneAt = &now
+	if err := writeOperationRecord(dir, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// Forget drops the operations started no later than until that touched any of
+// the files, with their before-images
+func (j *FileJournal) Forget(ctx context.Context, until time.Time, paths ...string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	forget := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		if rel, ok := j.relPath(p); ok {
+			forget[rel] = true
+		}
+	}
+
+	ops, err := j.list()
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if op.Time.After(until) {
+			continue
+		}
+		for _, image := range op.Files {
+			if !forget[image.Path] {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(j.vault.JournalPath(), op.ID)); err != nil {
+				return fmt.Errorf("failed to forget operation %s: %w", op.ID, err)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// relPath returns a path relative to the vault root, with forward slashes
+// Files outside the vault, and the journal itself, are not journaled
+func (j *FileJournal) relPath(p string) (string, bool) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(j.vault.RootPath, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".journal" || strings.HasPrefix(rel, ".journal/") {
+		return "", false
+	}
+	return rel, true
+}
+
+// hashFile returns the SHA-256 of a vault file, or "" if it does not exist
+func (j *FileJournal) hashFile(rel string) string {
+	data, err := os.ReadFile(filepath.Join(j.vault.RootPath, filepath.FromSlash(rel)))
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// removeEmptyParents removes the folders an operation created, up to the top-level vault folders
+func (j *FileJournal) removeEmptyParents(path string) {
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		rel, err := filepath.Rel(j.vault.RootPath, dir)
+		if err != nil || !strings.Contains(filepath.ToSlash(rel), "/") {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+	}
+}
+
+// prune drops the oldest operations beyond journalLimit; callers hold mu
+func (j *FileJournal) prune() error {
+	ops, err := j.list()
+	if err != nil {
+		return err
+	}
+	for i := journalLimit; i < len(ops); i++ {
+		if err := os.RemoveAll(filepath.Join(j.vault.JournalPath(), ops[i].ID)); err != nil {
+			return fmt.Errorf("failed to prune journal: %w", err)
+		}
+	}
+	return nil
+}
+
+// operationDir returns the folder of a recorded operation
+func (j *FileJournal) operationDir(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid operation: %s", id)
+	}
+	dir := filepath.Join(j.vault.JournalPath(), id)
+	if _, err := os.Stat(filepath.Join(dir, operationRecordName)); err != nil {
+		return "", fmt.Errorf("operation not found: %s", id)
+	}
+	return dir, nil
+}
+
+// newOperationDir creates the folder of the operation in progress and sets its ID
+// IDs start with the time of the operation, so the journal lists in order
+func (j *FileJournal) newOperationDir() (string, error) {
+	base := j.current.Time.Format("20060102-150405")
+	if err := os.MkdirAll(j.vault.JournalPath(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	j.current.ID = base
+	for n := 2; ; n++ {
+		dir := filepath.Join(j.vault.JournalPath(), j.current.ID)
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create journal directory: %w", err)
+		}
+		j.current.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// readOperationRecord reads the record of an operation
+func readOperationRecord(dir string) (*domain.Operation, error) {
+	data, err := os.ReadFile(filepath.Join(dir, operationRecordName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read operation: %w", err)
+	}
+	var op domain.Operation
+	if err := json.Unmarshal(data, &op); err != nil {
+		return nil, fmt.Errorf("failed to parse operation: %w", err)
+	}
+	return &op, nil
+}
+
+// writeOperationRecord writes the record of an operation
+func writeOperationRecord(dir string, op *domain.Operation) error {
+	data, err := json.MarshalIndent(op, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode operation: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, operationRecordName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write operation: %w", err)
+	}
+	return nil
+}
+
+// capture records before-images in a journal, if the repository has one
+func capture(journal ports.Journal, paths ...string) error {
+	if journal == nil {
+		return nil
+	}
+	return journal.Capture(paths...)
+}
@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/services"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// setupJournalVault creates an empty vault in a temporary folder with a journal
+func setupJournalVault(t *testing.T) (*vault.Vault, *FileJournal) {
+	t.Helper()
+
+	root := t.TempDir()
+	v := &vault.Vault{
+		Name:          "test",
+		RootPath:      root,
+		NotesPath:     filepath.Join(root, "notes"),
+		TemplatesPath: filepath.Join(root, "templates"),
+		AssetsPath:    filepath.Join(root, "assets"),
+		CachePath:     filepath.Join(root, "cache"),
+	}
+	if err := v.Initialize(); err != nil {
+		t.Fatalf("failed to initialize vault: %v", err)
+	}
+	return v, NewFileJournal(v)
+}
+
+// readFile returns the content of a file, or "" if it cannot be read
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func TestFileJournal_RevertRestoresFiles(t *testing.T) {
+	v, journal := setupJournalVault(t)
+	ctx := context.Background()
+
+	existing := v.GetNotePath("existing.tex")
+	created := v.GetNotePath(filepath.Join("notebook", "created.tex"))
+	os.WriteFile(existing, []byte("before"), 0644)
+
+	// 1. Overwrite one file and create another
+	journal.Begin("lx edit", []string{"existing"})
+	if err := journal.Capture(existing, created); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	os.WriteFile(existing, []byte("after"), 0644)
+	os.MkdirAll(filepath.Dir(created), 0755)
+	os.WriteFile(created, []byte("new"), 0644)
+	op, err := journal.Commit()
+	if err != nil || op == nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	// 2. Revert it
+	if _, err := journal.Revert(ctx, op.ID); err != nil {
+		t.Fatalf("Revert failed: %v", err)
+	}
+
+	if got := readFile(t, existing); got != "before" {
+		t.Errorf("expected the overwritten file to be restored, got %q", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("expected the created file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(created)); !os.IsNotExist(err) {
+		t.Errorf("expected the folder the operation created to be removed, got %v", err)
+	}
+
+	// 3. It cannot be reverted twice
+	if _, err := journal.Revert(ctx, op.ID); err == nil {
+		t.Error("expected a second revert to fail")
+	}
+}
+
+func TestFileJournal_ModifiedSinceOperation(t *testing.T) {
+	v, journal := setupJournalVault(t)
+	ctx := context.Background()
+
+	path := v.GetNotePath("note.tex")
+	os.WriteFile(path, []byte("v1"), 0644)
+
+	journal.Begin("lx tag", []string{"note"})
+	journal.Capture(path)
+	os.WriteFile(path, []byte("v2"), 0644)
+	op, _ := journal.Commit()
+
+	modified, err := journal.Modified(ctx, op.ID)
+	if err != nil || len(modified) != 0 {
+		t.Fatalf("expected no changes right after the operation, got %v (%v)", modified, err)
+	}
+
+	// A later edit must not be thrown away by undo
+	os.WriteFile(path, []byte("v3"), 0644)
+	modified, _ = journal.Modified(ctx, op.ID)
+	if !reflect.DeepEqual(modified, []string{"notes/note.tex"}) {
+		t.Errorf("expected the edited note to be reported, got %v", modified)
+	}
+
+	undo := services.NewJournalService(journal)
+	if _, err := undo.Undo(ctx, false); err == nil {
+		t.Fatal("expected undo to refuse while the note changed since")
+	}
+	if got := readFile(t, path); got != "v3" {
+		t.Errorf("expected the refused undo to leave the note alone, got %q", got)
+	}
+	if _, err := undo.Undo(ctx, true); err != nil {
+		t.Fatalf("forced undo failed: %v", err)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("expected the forced undo to restore the note, got %q", got)
+	}
+}
+
+func TestFileJournal_UndoTrashDeletion(t *testing.T) {
+	v, journal := setupJournalVault(t)
+	ctx := context.Background()
+
+	noteRepo := NewFileRepository(v)
+	assetRepo := NewFileAssetRepository(v)
+	noteRepo.SetJournal(journal)
+	assetRepo.SetJournal(journal)
+	trash := services.NewTrashService(noteRepo, assetRepo, nil)
+
+	// 1. A note with an asset of its own
+	header, _ := domain.NewNoteHeader("Graph Theory", []string{}, "")
+	if err := noteRepo.Save(ctx, domain.NewNoteBody(header, "\\includegraphics{graph.png}")); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	os.WriteFile(v.GetAssetPath("graph.png"), []byte("png"), 0644)
+	asset := domain.Asset{Filename: "graph.png", Description: "A graph"}
+	if err := assetRepo.Save(ctx, asset); err != nil {
+		t.Fatalf("Save asset failed: %v", err)
+	}
+	notePath := v.GetNotePath(header.Filename)
+	manifest := readFile(t, filepath.Join(v.AssetsPath, ".manifest.json"))
+
+	// 2. Delete it into the trash
+	journal.Begin("lx delete", []string{header.Slug})
+	item, err := trash.Delete(ctx, header.Slug, []string{"graph.png"})
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	journal.Commit()
+
+	if _, err := os.Stat(notePath); !os.IsNotExist(err) {
+		t.Fatalf("expected the note to be in the trash, got %v", err)
+	}
+
+	// 3. Undo the deletion
+	if _, err := services.NewJournalService(journal).Undo(ctx, false); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	if got := readFile(t, notePath); got == "" {
+		t.Error("expected the note to be back")
+	}
+	if got := readFile(t, v.GetAssetPath("graph.png")); got != "png" {
+		t.Errorf("expected the asset to be back, got %q", got)
+	}
+	if got := readFile(t, filepath.Join(v.AssetsPath, ".manifest.json")); got != manifest {
+		t.Errorf("expected the asset manifest to be restored, got %s", got)
+	}
+	if _, err := os.Stat(filepath.Join(v.TrashPath(), item.ID)); !os.IsNotExist(err) {
+		t.Errorf("expected the trash item to be removed, got %v", err)
+	}
+}
+
+func TestFileRepository_PurgeForgetsJournal(t *testing.T) {
+	v, journal := setupJournalVault(t)
+	ctx := context.Background()
+
+	noteRepo := NewFileRepository(v)
+	noteRepo.SetJournal(journal)
+	header, _ := domain.NewNoteHeader("Secret", []string{}, "")
+	note := domain.NewNoteBody(header, "v1")
+	noteRepo.Save(ctx, note)
+
+	// 1. Edit the note, then delete it
+	journal.Begin("lx tag", []string{header.Slug})
+	note.Content = "v2"
+	noteRepo.Save(ctx, note)
+	journal.Commit()
+
+	journal.Begin("lx delete", []string{header.Slug})
+	item, err := noteRepo.Trash(ctx, header.Slug, nil)
+	if err != nil {
+		t.Fatalf("Trash failed: %v", err)
+	}
+	journal.Commit()
+
+	// 2. A new note takes its place; its history must survive the purge
+	time.Sleep(10 * time.Millisecond)
+	journal.Begin("lx new", []string{"Secret"})
+	noteRepo.Save(ctx, domain.NewNoteBody(header, "new"))
+	journal.Commit()
+
+	// 3. Empty the trash
+	if err := noteRepo.Purge(ctx, item.ID); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	ops, err := journal.List(ctx)
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(ops) != 1 || ops[0].Command != "lx new" {
+		t.Errorf("expected only the new note's operation to be kept, got %+v", ops)
+	}
+}
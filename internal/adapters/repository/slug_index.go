@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/metadata"
+)
+
+// noteFile is a note file and the slug it is known by
+type noteFile struct {
+	filename string // Relative to the notes directory, with / separators
+	slug     string
+}
+
+// findBySlug finds the note with a slug using the slug index
+func (r *FileRepository) findBySlug(slug string) (noteFile, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	index, err := r.slugIndex(false)
+	if err != nil {
+		return noteFile{}, err
+	}
+	filename, canonical, err := index.Lookup(slug)
+	if err != nil {
+		return noteFile{}, err
+	}
+	return noteFile{filename: filename, slug: canonical}, nil
+}
+
+// headers returns the header of every note, rereading only the files that changed
+func (r *FileRepository) headers() ([]domain.NoteHeader, error) {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	index, err := r.slugIndex(true)
+	if err != nil {
+		return nil, err
+	}
+
+	// In filename order, like a directory listing
+	filenames := make([]string, 0, len(index.Files))
+	for filename := range index.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	headers := make([]domain.NoteHeader, 0, len(filenames))
+	for _, filename := range filenames {
+		headers = append(headers, index.Header(filename))
+	}
+	return headers, nil
+}
+
+// Duplicates returns the slugs that several files in one notebook map to
+// Only one of those files can be read by slug; the others need renaming
+func (r *FileRepository) Duplicates() (map[string][]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	index, err := r.slugIndex(false)
+	if err != nil {
+		return nil, err
+	}
+	return index.Duplicates(), nil
+}
+
+// invalidate makes the next lookup rescan the notes directory
+// Cached headers are kept; they are checked against file mtimes anyway
+func (r *FileRepository) invalidate() {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.index != nil {
+		r.index.Dirs = make(map[string]time.Time)
+	}
+}
+
+// slugIndex returns the slug index, loading it from disk and rescanning the notes
+// directory if a notebook changed. With checkFiles, headers of files modified since
+// they were read are reread too. Callers hold indexMu
+func (r *FileRepository) slugIndex(checkFiles bool) (*domain.SlugIndex, error) {
+	// 1. Load the cached index
+	if r.index == nil {
+		r.index = r.loadSlugIndex()
+	}
+	index := r.index
+	changed := false
+
+	// 2. Rescan if a notebook directory changed
+	if !r.dirsFresh(index) {
+		if err := r.rescan(index); err != nil {
+			return nil, err
+		}
+		changed = true
+	} else if checkFiles {
+		// 3. Reread headers of edited files
+		for filename, entry := range index.Files {
+			info, err := os.Stat(r.vault.GetNotePath(filepath.FromSlash(filename)))
+			if err != nil || info.ModTime().Equal(entry.ModTime) {
+				continue
+			}
+			index.Files[filename] = r.readEntry(filename, info)
+			changed = true
+		}
+		if changed {
+			index.AssignSlugs()
+		}
+	}
+
+	if changed {
+		r.saveSlugIndex(index)
+	}
+	return index, nil
+}
+
+// dirsFresh reports whether no notebook directory changed since the index was built
+func (r *FileRepository) dirsFresh(index *domain.SlugIndex) bool {
+	if len(index.Dirs) == 0 {
+		return false
+	}
+	for notebook := range index.Dirs {
+		info, err := os.Stat(r.vault.GetNotePath(filepath.FromSlash(notebook)))
+		if err != nil || !index.DirFresh(notebook, info.ModTime()) {
+			return false
+		}
+	}
+	return true
+}
+
+// rescan lists the notes directory and its notebooks again
+// Headers of files unchanged since they were read are kept
+func (r *FileRepository) rescan(index *domain.SlugIndex) error {
+	scannedAt := time.Now()
+	dirs := make(map[string]time.Time)
+	files := make(map[string]domain.SlugEntry)
+
+	root := r.vault.NotesPath
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			dirs[domain.NormalizeNotebook(rel)] = info.ModTime()
+			return nil
+		}
+		if filepath.Ext(d.Name()) != ".tex" {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if entry, ok := index.Files[rel]; ok && entry.ModTime.Equal(info.ModTime()) {
+			files[rel] = entry
+		} else {
+			files[rel] = r.readEntry(rel, info)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read notes directory: %w", err)
+	}
+
+	index.ScannedAt = scannedAt
+	index.Dirs = dirs
+	index.Files = files
+	index.AssignSlugs()
+	return nil
+}
+
+// readEntry reads the header of a note file for the index
+func (r *FileRepository) readEntry(filename string, info os.FileInfo) domain.SlugEntry {
+	entry := domain.SlugEntry{
+		Title:   domain.ParseFilename(filepath.Base(filename)),
+		Date:    info.ModTime().Format("2006-01-02"),
+		ModTime: info.ModTime(),
+	}
+
+	// Read first 1KB for metadata to be fast
+	f, err := os.Open(r.vault.GetNotePath(filepath.FromSlash(filename)))
+	if err != nil {
+		return entry
+	}
+	defer f.Close()
+
+	buf := make([]byte, 1024)
+	n, _ := f.Read(buf)
+	meta, err := metadata.Extract(string(buf[:n]))
+	if err != nil {
+		// Fallback for files without valid metadata
+		return entry
+	}
+
+	entry.Title = meta.Title
+	entry.Date = meta.Date
+	entry.Tags = meta.Tags
+	return entry
+}
+
+// loadSlugIndex reads the cached index, starting over if it is missing or outdated
+func (r *FileRepository) loadSlugIndex() *domain.SlugIndex {
+	data, err := os.ReadFile(r.vault.SlugIndexPath())
+	if err != nil {
+		return domain.NewSlugIndex()
+	}
+
+	var index domain.SlugIndex
+	if err := json.Unmarshal(data, &index); err != nil || index.Version != domain.SlugIndexVersion || index.Files == nil {
+		return domain.NewSlugIndex()
+	}
+	index.AssignSlugs()
+	return &index
+}
+
+// saveSlugIndex writes the index to the cache
+// The index is only a cache, so failing to write it is not an error
+func (r *FileRepository) saveSlugIndex(index *domain.SlugIndex) {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(r.vault.SlugIndexPath()), 0755); err != nil {
+		return
+	}
+
+	// Write through a temporary file, so another lx process never reads half an index
+	tmp := r.vault.SlugIndexPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return
+	}
+	_ = os.Rename(tmp, r.vault.SlugIndexPath())
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -16,6 +17,9 @@ import (
 type TemplateRepository struct {
 	vault             *vault.Vault
 	customTemplateDir string
+
+	// Records the templates it creates, if set
+	journal ports.Journal
 }
 
 // NewTemplateRepository creates a new file-based template repository
@@ -26,6 +30,11 @@ func NewTemplateRepository(vault *vault.Vault, customTemplateDir string) *Templa
 	}
 }
 
+// SetJournal records the templates the repository creates, so operations can be undone
+func (r *TemplateRepository) SetJournal(journal ports.Journal) {
+	r.journal = journal
+}
+
 // List returns all available templates (including custom ones)
 func (r *TemplateRepository) List(ctx context.Context) ([]domain.Template, error) {
 	var templates []domain.Template
@@ -129,6 +138,9 @@ func (r *TemplateRepository) Create(ctx context.Context, template *domain.Templa
 	content := r.renderTemplateContent(template)
 
 	// Write the file
+	if err := capture(r.journal, path); err != nil {
+		return err
+	}
 	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write template file: %w", err)
 	}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/metadata"
+)
+
+// Ensure it implements the interface
+var _ ports.TrashRepository = (*FileRepository)(nil)
+
+// trashRecordName is the record of a trash item, next to the note and its assets:
+//
+//	.trash/<id>/item.json
+//	.trash/<id>/<note filename>
+//	.trash/<id>/assets/<asset filename>
+const trashRecordName = "item.json"
+
+// Trash moves a note and the given assets to the trash, keeping a record of where they were
+func (r *FileRepository) Trash(ctx context.Context, slug string, assets []domain.Asset) (*domain.TrashItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.trash(slug, assets)
+}
+
+// trash moves a note to the trash; callers hold mu
+func (r *FileRepository) trash(slug string, assets []domain.Asset) (*domain.TrashItem, error) {
+	// 1. Find the note
+	file, err := r.findBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	notePath := r.vault.GetNotePath(filepath.FromSlash(file.filename))
+
+	item := &domain.TrashItem{
+		Slug:      file.slug,
+		Title:     domain.ParseFilename(path.Base(file.filename)),
+		Filename:  file.filename,
+		DeletedAt: time.Now(),
+	}
+	if content, err := os.ReadFile(notePath); err == nil {
+		if meta, err := metadata.Extract(string(content)); err == nil {
+			item.Title = meta.Title
+		}
+	}
+
+	// 2. Make a folder for the item
+	dir, err := r.newTrashDir(item)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. Move the note and record it
+	trashedNote := filepath.Join(dir, path.Base(file.filename))
+	if err := capture(r.journal, notePath, trashedNote, filepath.Join(dir, trashRecordName)); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, err
+	}
+	defer r.invalidate()
+	if err := os.Rename(notePath, trashedNote); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to move note to the trash: %w", err)
+	}
+	if err := writeTrashRecord(dir, item); err != nil {
+		return nil, err
+	}
+
+	// 4. Move the assets that still exist, recording each one so none is lost on failure
+	for _, asset := range assets {
+		assetPath := r.vault.GetAssetPath(asset.Filename)
+		if _, err := os.Stat(assetPath); err != nil {
+			continue
+		}
+		trashed := filepath.Join(dir, "assets", asset.Filename)
+		if err := capture(r.journal, assetPath, trashed); err != nil {
+			return item, err
+		}
+		if err := os.MkdirAll(filepath.Dir(trashed), 0755); err != nil {
+			return item, fmt.Errorf("failed to create trash directory: %w", err)
+		}
+		if err := os.Rename(assetPath, trashed); err != nil {
+			return item, fmt.Errorf("failed to move asset %s to the trash: %w", asset.Filename, err)
+		}
+		item.Assets = append(item.Assets, asset)
+		if err := writeTrashRecord(dir, item); err != nil {
+			return item, err
+		}
+	}
+
+	return item, nil
+}
+
+// ListTrash returns the trashed notes, most recently deleted first
+func (r *FileRepository) ListTrash(ctx context.Context) ([]domain.TrashItem, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries, err := os.ReadDir(r.vault.TrashPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read trash: %w", err)
+	}
+
+	var items []domain.TrashItem
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		item, err := readTrashRecord(filepath.Join(r.vault.TrashPath(), entry.Name()))
+		if err != nil {
+			// Folders without a record were not made by lx
+			continue
+		}
+		items = append(items, *item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore moves a trashed note and its assets back
+// Assets that exist again in the assets folder are kept as they are
+func (r *FileRepository) Restore(ctx context.Context, id string) (*domain.TrashItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 1. Read the record
+	dir, err := r.trashDir(id)
+	if err != nil {
+		return nil, err
+	}
+	item, err := readTrashRecord(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Ensure the note's place is free, and its notebook has no note with the same slug
+	notePath := r.vault.GetNotePath(filepath.FromSlash(item.Filename))
+	if _, err := os.Stat(notePath); err == nil {
+		return nil, fmt.Errorf("a note already exists at %s", item.Filename)
+	}
+	headers, err := r.headers()
+	if err != nil {
+		return nil, err
+	}
+	notebook := domain.NotebookOf(item.Filename)
+	slug := domain.ParseFilename(path.Base(item.Filename))
+	for _, other := range headers {
+		if other.Notebook == notebook && domain.ParseFilename(path.Base(other.Filename)) == slug {
+			return nil, fmt.Errorf("%s already has a note named %s: %s", notebookName(notebook), slug, other.Filename)
+		}
+	}
+
+	// 3. Move the note back, recreating its notebook if needed
+	if err := capture(r.journal, trashItemFiles(dir)...); err != nil {
+		return nil, err
+	}
+	if err := capture(r.journal, notePath); err != nil {
+		return nil, err
+	}
+	for _, asset := range item.Assets {
+		if err := capture(r.journal, r.vault.GetAssetPath(asset.Filename)); err != nil {
+			return nil, err
+		}
+	}
+	defer r.invalidate()
+	if err := os.MkdirAll(filepath.Dir(notePath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create notebook directory: %w", err)
+	}
+	if err := os.Rename(filepath.Join(dir, path.Base(item.Filename)), notePath); err != nil {
+		return nil, fmt.Errorf("failed to restore note: %w", err)
+	}
+
+	// 4. Move the assets back
+	for _, asset := range item.Assets {
+		assetPath := r.vault.GetAssetPath(asset.Filename)
+		if _, err := os.Stat(assetPath); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(assetPath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create assets directory: %w", err)
+		}
+		if err := os.Rename(filepath.Join(dir, "assets", asset.Filename), assetPath); err != nil {
+			return nil, fmt.Errorf("failed to restore asset %s: %w", asset.Filename, err)
+		}
+	}
+
+	// 5. Drop the item from the trash
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("failed to remove trash item: %w", err)
+	}
+	return item, nil
+}
+
+// Purge deletes a trashed note and its assets for good
+// It is not journaled. The operations that touched the note or its assets up to
+// their deletion, where they were or in the trash, are dropped from the journal,
+// so no copy of them is left behind
+func (r *FileRepository) Purge(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dir, err := r.trashDir(id)
+	if err != nil {
+		return err
+	}
+	if r.journal != nil {
+		item, err := readTrashRecord(dir)
+		if err != nil {
+			return err
+		}
+		paths := append(trashItemFiles(dir), r.vault.GetNotePath(filepath.FromSlash(item.Filename)))
+		for _, asset := range item.Assets {
+			paths = append(paths, r.vault.GetAssetPath(asset.Filename))
+		}
+		// Notes created at the same place since keep their history
+		if err := r.journal.Forget(ctx, item.DeletedAt, paths...); err != nil {
+			return err
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove trash item: %w", err)
+	}
+	return nil
+}
+
+// trashDir returns the folder of an existing trash item
+func (r *FileRepository) trashDir(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid trash item: %s", id)
+	}
+	dir := filepath.Join(r.vault.TrashPath(), id)
+	if _, err := os.Stat(filepath.Join(dir, trashRecordName)); err != nil {
+		return "", fmt.Errorf("not in the trash: %s", id)
+	}
+	return dir, nil
+}
+
+// newTrashDir creates the folder of a new trash item and sets its ID
+// IDs start with the deletion time, so the trash lists in order
+func (r *FileRepository) newTrashDir(item *domain.TrashItem) (string, error) {
+	base := item.DeletedAt.Format("20060102-150405") + "-" + strings.ReplaceAll(item.Slug, "/", "-")
+	if err := os.MkdirAll(r.vault.TrashPath(), 0755); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	item.ID = base
+	for n := 2; ; n++ {
+		dir := filepath.Join(r.vault.TrashPath(), item.ID)
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			return dir, nil
+		}
+		if !os.IsExist(err) {
+			return "", fmt.Errorf("failed to create trash directory: %w", err)
+		}
+		item.ID = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// trashItemFiles returns the files inside a trash item, for the journal
+func trashItemFiles(dir string) []string {
+	var files []string
+	_ = filepath.WalkDir(dir, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	return files
+}
+
+// readTrashRecord reads the record of a trash item
+func readTrashRecord(dir string) (*domain.TrashItem, error) {
+	data, err := os.ReadFile(filepath.Join(dir, trashRecordName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash item: %w", err)
+	}
+	var item domain.TrashItem
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("failed to parse trash item: %w", err)
+	}
+	return &item, nil
+}
+
+// writeTrashRecord writes the record of a trash item
+func writeTrashRecord(dir string, item *domain.TrashItem) error {
+	data, err := json.MarshalIndent(item, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trash item: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, trashRecordName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write trash item: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+// BuildManifest records the inputs each note was last built from
+// It lets build-all skip notes whose sources have not changed
+type BuildManifest struct {
+	Version string                   `json:"version"`
+	Notes   map[string]ManifestEntry `json:"notes"`
+}
+
+// ManifestEntry holds the content hash of a single successful build
+type ManifestEntry struct {
+	// Hash covers the note source, its templates, \input files,
+	// assets and the titles of the notes it links to
+	Hash string `json:"hash"`
+
+	// Dependencies are the slugs of notes this note links to or inputs
+	Dependencies []string `json:"dependencies"`
+
+	BuiltAt time.Time `json:"built_at"`
+}
+
+// NewBuildManifest creates a new empty manifest
+func NewBuildManifest() *BuildManifest {
+	return &BuildManifest{
+		Version: "1.0",
+		Notes:   make(map[string]ManifestEntry),
+	}
+}
+
+// GetEntry retrieves the manifest entry for a note
+func (m *BuildManifest) GetEntry(slug string) (ManifestEntry, bool) {
+	entry, exists := m.Notes[slug]
+	return entry, exists
+}
+
+// SetEntry adds or updates the manifest entry for a note
+func (m *BuildManifest) SetEntry(slug string, entry ManifestEntry) {
+	if m.Notes == nil {
+		m.Notes = make(map[string]ManifestEntry)
+	}
+	m.Notes[slug] = entry
+}
+
+// RemoveEntry drops a note from the manifest so it is rebuilt next time
+func (m *BuildManifest) RemoveEntry(slug string) {
+	delete(m.Notes, slug)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+)
+
+// BuildRecord is one entry in a note's persistent build history
+type BuildRecord struct {
+	ID        string              `json:"id"`
+	Slug      string              `json:"slug"`
+	Timestamp time.Time           `json:"timestamp"`
+	Engine    string              `json:"engine"`
+	Duration  time.Duration       `json:"duration"`
+	Success   bool                `json:"success"`
+	TimedOut  bool                `json:"timed_out,omitempty"` // Killed after exceeding the build timeout
+	Errors    []latexparser.Issue `json:"errors"`
+	Warnings  []latexparser.Issue `json:"warnings"`
+
+	// Output is the raw compiler log; it is stored next to the record
+	// and only loaded when a single build is requested
+	Output string `json:"-"`
+}
+
+// NewBuildRecord creates a record for a build that started at the given time
+func NewBuildRecord(slug string, started time.Time) *BuildRecord {
+	return &BuildRecord{
+		ID:        started.Format("20060102-150405.000000"),
+		Slug:      slug,
+		Timestamp: started,
+		Errors:    []latexparser.Issue{},
+		Warnings:  []latexparser.Issue{},
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "github.com/kamal-hamza/lx-cli/pkg/latexparser"
+
+// CompileResult holds compilation output and parsed issues
+type CompileResult struct {
+	Success    bool
+	Engine     string // Toolchain that produced the output, e.g. "latexmk (lualatex)"
+	Output     string
+	Parsed     *latexparser.ParseResult
+	PDFPath    string
+	ErrorCount int
+}
+
+// CompileOptions carries per-note build settings to a compiler adapter
+// Zero values mean "use the compiler's configured defaults"
+type CompileOptions struct {
+	Env          []string // Additional environment variables (e.g., TEXINPUTS)
+	Engine       string   // pdflatex, lualatex or xelatex
+	ShellEscape  bool     // Allow the engine to run external commands
+	LatexmkFlags []string // Replaces the configured latexmk flags
+	Bib          string   // biber, bibtex or none
+}
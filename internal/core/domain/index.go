@@ -18,18 +18,43 @@ type IndexEntry struct {
 	Tags     []string `json:"tags"`
 	Filename string   `json:"filename"`
 
+	// Change detection for incremental reindexing
+	ModTime time.Time `json:"mod_time"`
+	Hash    string    `json:"hash"` // SHA-256 of the note content
+
 	// Graph Connections
-	OutgoingLinks []string `json:"outgoing_links"` // \ref, \input, etc.
-	Backlinks     []string `json:"backlinks"`      // Other files pointing here
+	Edges         []IndexEdge `json:"edges,omitempty"` // Typed links, see ExtractLinks
+	OutgoingLinks []string    `json:"outgoing_links"`  // Linked notes (every edge kind but embeds)
+	Backlinks     []string    `json:"backlinks"`       // Other files pointing here
+
+	// Transclusion: \lxembed{slug} pulls another note's content in
+	Embeds     []string `json:"embeds,omitempty"`      // Notes this one embeds
+	EmbeddedIn []string `json:"embedded_in,omitempty"` // Notes embedding this one
 
 	// Assets used in this note
 	Assets []string `json:"assets"` // \includegraphics{...}
+
+	// Labels defined in this note; \lxnote{slug#label} can link to them
+	Labels []string `json:"labels,omitempty"`
+
+	// Open \todo{...} and % TODO: items, see ExtractTodos
+	Todos int `json:"todos,omitempty"`
 }
 
+// IndexEdge is a typed link from an indexed note to another note
+type IndexEdge struct {
+	Target string   `json:"target"`
+	Kind   LinkKind `json:"kind"`
+}
+
+// IndexVersion is bumped whenever entries change shape
+// Indexes with another version are rebuilt from scratch
+const IndexVersion = "1.4"
+
 // NewIndex creates a new empty index
 func NewIndex() *Index {
 	return &Index{
-		Version:     "1.1",
+		Version:     IndexVersion,
 		LastIndexed: time.Now(),
 		Notes:       make(map[string]IndexEntry),
 	}
@@ -43,6 +68,11 @@ func (idx *Index) AddNote(slug string, entry IndexEntry) {
 	idx.Notes[slug] = entry
 }
 
+// RemoveNote removes a note from the index
+func (idx *Index) RemoveNote(slug string) {
+	delete(idx.Notes, slug)
+}
+
 // GetNote retrieves a note from the index
 func (idx *Index) GetNote(slug string) (IndexEntry, bool) {
 	entry, exists := idx.Notes[slug]
@@ -64,7 +94,7 @@ func (idx *Index) Count() int {
 func (idx *Index) CountConnections() int {
 	count := 0
 	for _, entry := range idx.Notes {
-		count += len(entry.OutgoingLinks)
+		count += len(entry.OutgoingLinks) + len(entry.Embeds)
 	}
 	return count
 }
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Operation is a journaled run of a command that changed files in the vault
+// It keeps a before-image of each file, so the run can be undone without git
+type Operation struct {
+	ID      string      `json:"id"` // Folder of the operation inside the journal
+	Command string      `json:"command"`
+	Args    []string    `json:"args,omitempty"`
+	Time    time.Time   `json:"time"`
+	Files   []FileImage `json:"files"`
+
+	UndoneAt *time.Time `json:"undone_at,omitempty"`
+}
+
+// FileImage is the state of one file before and after an operation
+type FileImage struct {
+	Path    string `json:"path"`    // Relative to the vault root, with forward slashes
+	Existed bool   `json:"existed"` // False when the operation created the file
+	Blob    string `json:"blob,omitempty"`
+
+	// SHA-256 of the file once the operation finished; empty if it removed the file
+	After string `json:"after,omitempty"`
+}
+
+// Undone reports whether the operation has been undone
+func (o Operation) Undone() bool {
+	return o.UndoneAt != nil
+}
+
+// Description returns the command line of the operation
+func (o Operation) Description() string {
+	return strings.TrimSpace(o.Command + " " + strings.Join(o.Args, " "))
+}
+
+// LastUndoable returns the most recent operation that has not been undone
+// ops must be ordered newest first, as journals list them
+func LastUndoable(ops []Operation) (*Operation, bool) {
+	for i := range ops {
+		if !ops[i].Undone() {
+			return &ops[i], true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLastUndoable(t *testing.T) {
+	undone := time.Now()
+	ops := []Operation{
+		{ID: "3", UndoneAt: &undone},
+		{ID: "2"},
+		{ID: "1"},
+	}
+
+	op, ok := LastUndoable(ops)
+	if !ok || op.ID != "2" {
+		t.Errorf("expected operation 2, got %v", op)
+	}
+
+	if _, ok := LastUndoable(ops[:1]); ok {
+		t.Error("expected nothing to undo when every operation was undone")
+	}
+	if _, ok := LastUndoable(nil); ok {
+		t.Error("expected nothing to undo in an empty journal")
+	}
+}
+
+func TestOperation_Description(t *testing.T) {
+	op := Operation{Command: "lx tag rename", Args: []string{"physics", "science"}}
+	if got := op.Description(); got != "lx tag rename physics science" {
+		t.Errorf("unexpected description: %q", got)
+	}
+	if got := (Operation{Command: "lx migrate"}).Description(); got != "lx migrate" {
+		t.Errorf("unexpected description: %q", got)
+	}
+}
@@ -0,0 +1,239 @@
+package domain
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// NoteTarget is the target of a note link: a slug with an optional vault and anchor
+// "graph-theory#thm:euler" points at \label{thm:euler} inside graph-theory, and
+// "research:graph-theory" at the note in the vault named research
+type NoteTarget struct {
+	Vault string
+	Slug  string
+	Label string
+}
+
+// VaultSeparator separates the vault from the slug in a link target
+const VaultSeparator = ":"
+
+// ParseNoteTarget splits a link target into its vault, slug and optional label
+// Labels often contain colons, so only a colon before the # names a vault
+func ParseNoteTarget(target string) NoteTarget {
+	slug, label, _ := strings.Cut(strings.TrimSpace(target), "#")
+	vault := ""
+	if before, after, ok := strings.Cut(slug, VaultSeparator); ok {
+		vault, slug = before, after
+	}
+	return NoteTarget{
+		Vault: strings.TrimSpace(vault),
+		Slug:  strings.TrimSpace(slug),
+		Label: strings.TrimSpace(label),
+	}
+}
+
+// String formats the target as it is written in \lxnote{...}
+func (t NoteTarget) String() string {
+	target := t.Slug
+	if t.Vault != "" {
+		target = t.Vault + VaultSeparator + target
+	}
+	if t.Label != "" {
+		target += "#" + t.Label
+	}
+	return target
+}
+
+// Local returns the target as a link inside currentVault, without a vault prefix
+// A link naming the current vault is local too. For a note in another vault it
+// reports false; such links are left out of the graph and the index of this vault
+func (t NoteTarget) Local(currentVault string) (NoteTarget, bool) {
+	if t.Vault != "" && t.Vault != currentVault {
+		return t, false
+	}
+	t.Vault = ""
+	return t, true
+}
+
+// ExtractLabels returns the unique \label names defined in content, in order
+func ExtractLabels(content string) []string {
+	seen := make(map[string]bool)
+	labels := []string{}
+	for _, inv := range latexscan.FindCommands(content, "label") {
+		label := strings.TrimSpace(inv.Arg.Text)
+		if label != "" && !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// LinkKind is the type of an edge from one note to another
+type LinkKind string
+
+const (
+	LinkNote    LinkKind = "lxnote"   // \lxnote{slug} or \lxnote{slug#label}
+	LinkRef     LinkKind = "ref"      // \ref{slug} (deprecated for notes) and \cref{slug}
+	LinkInput   LinkKind = "input"    // \input{../notes/slug.tex}
+	LinkInclude LinkKind = "include"  // \include{slug}
+	LinkCite    LinkKind = "cite"     // \cite{slug}
+	LinkTag     LinkKind = "tag-link" // a "link:slug" tag
+	LinkEmbed   LinkKind = "embed"    // \lxembed{slug} or \lxembed{slug#label}
+)
+
+// Link is a typed edge from a note to another note
+// Start and End locate the argument in the content; they are -1 for tag links
+type Link struct {
+	Kind   LinkKind
+	Target NoteTarget
+	Start  int
+	End    int
+}
+
+// TagLinkPrefix marks tags that link to another note, e.g. "link:graph-theory"
+const TagLinkPrefix = "link:"
+
+// The metadata tags line, e.g. "% tags: math, link:graph-theory"
+var tagsLinePattern = regexp.MustCompile(`(?mi)^%+\s*tags:.*$`)
+
+// linkCommandKinds maps the LaTeX commands whose argument names another note to edge types
+var linkCommandKinds = map[string]LinkKind{
+	"lxnote":  LinkNote,
+	"lxembed": LinkEmbed,
+	"ref":     LinkRef,
+	"cref":    LinkRef,
+	"input":   LinkInput,
+	"include": LinkInclude,
+	"cite":    LinkCite,
+}
+
+// ExtractLinks finds every link in note content, in order of appearance
+// Links in comments, verbatim environments and \iffalse blocks don't count.
+// Targets of \ref, \input etc. are normalized to slugs, so "../notes/20250101-foo.tex" is "foo"
+func ExtractLinks(content string) []Link {
+	var links []Link
+
+	names := make([]string, 0, len(linkCommandKinds))
+	for name := range linkCommandKinds {
+		names = append(names, name)
+	}
+
+	for _, inv := range latexscan.FindCommands(content, names...) {
+		kind := linkCommandKinds[inv.Name]
+		argStart, argEnd := inv.Arg.Start, inv.Arg.End
+
+		switch kind {
+		case LinkNote, LinkEmbed:
+			links = append(links, Link{
+				Kind:   kind,
+				Target: ParseNoteTarget(content[argStart:argEnd]),
+				Start:  argStart,
+				End:    argEnd,
+			})
+		case LinkCite, LinkRef:
+			// \cite{a,b} and \cref{a,b} name several targets
+			offset := argStart
+			for _, key := range strings.Split(content[argStart:argEnd], ",") {
+				if slug := SlugFromReference(key); slug != "" {
+					links = append(links, Link{Kind: kind, Target: NoteTarget{Slug: slug}, Start: offset, End: offset + len(key)})
+				}
+				offset += len(key) + 1
+			}
+		default:
+			if slug := SlugFromReference(content[argStart:argEnd]); slug != "" {
+				links = append(links, Link{Kind: kind, Target: NoteTarget{Slug: slug}, Start: argStart, End: argEnd})
+			}
+		}
+	}
+
+	return links
+}
+
+// TagLinks returns the links declared with "link:slug" tags
+func TagLinks(tags []string) []Link {
+	var links []Link
+	for _, tag := range tags {
+		if slug, ok := strings.CutPrefix(tag, TagLinkPrefix); ok && strings.TrimSpace(slug) != "" {
+			links = append(links, Link{
+				Kind:   LinkTag,
+				Target: NoteTarget{Slug: strings.TrimSpace(slug)},
+				Start:  -1,
+				End:    -1,
+			})
+		}
+	}
+	return links
+}
+
+// NoteLinks returns all links of a note: those in its content and its tag links
+func NoteLinks(header NoteHeader, content string) []Link {
+	return append(ExtractLinks(content), TagLinks(header.Tags)...)
+}
+
+// SlugFromReference turns a link argument into a slug
+// Paths, the .tex extension and a date prefix are stripped
+func SlugFromReference(ref string) string {
+	ref = strings.ReplaceAll(ref, "\\", "/")
+	ref = filepath.Base(strings.TrimSpace(ref))
+	ref = strings.TrimSuffix(ref, ".tex")
+
+	if len(ref) > 9 && ref[8] == '-' {
+		isDate := true
+		for i := 0; i < 8; i++ {
+			if ref[i] < '0' || ref[i] > '9' {
+				isDate = false
+				break
+			}
+		}
+		if isDate {
+			ref = ref[9:]
+		}
+	}
+	return strings.TrimSpace(ref)
+}
+
+// RenameLinkTarget rewrites every link to oldSlug so it points at newSlug
+// Labels, paths, date prefixes and a prefix naming the current vault are kept.
+// It returns the new content and the number of links rewritten
+func RenameLinkTarget(content, currentVault, oldSlug, newSlug string) (string, int) {
+	var sb strings.Builder
+	count := 0
+	cursor := 0
+
+	for _, link := range ExtractLinks(content) {
+		if _, local := link.Target.Local(currentVault); !local || link.Target.Slug != oldSlug {
+			continue
+		}
+
+		arg := content[link.Start:link.End]
+		var rewritten string
+		switch link.Kind {
+		case LinkNote, LinkEmbed:
+			rewritten = NoteTarget{Vault: link.Target.Vault, Slug: newSlug, Label: link.Target.Label}.String()
+		default:
+			// The slug is the last part of a path such as ../notes/20250101-slug.tex
+			idx := strings.LastIndex(arg, oldSlug)
+			rewritten = arg[:idx] + newSlug + arg[idx+len(oldSlug):]
+		}
+
+		sb.WriteString(content[cursor:link.Start])
+		sb.WriteString(rewritten)
+		cursor = link.End
+		count++
+	}
+	sb.WriteString(content[cursor:])
+	content = sb.String()
+
+	// Tag links live in the metadata header
+	tagPattern := regexp.MustCompile(`(` + regexp.QuoteMeta(TagLinkPrefix) + `)` + regexp.QuoteMeta(oldSlug) + `(\s*(?:,|$))`)
+	content = tagsLinePattern.ReplaceAllStringFunc(content, func(line string) string {
+		count += len(tagPattern.FindAllString(line, -1))
+		return tagPattern.ReplaceAllString(line, "${1}"+newSlug+"${2}")
+	})
+
+	return content, count
+}
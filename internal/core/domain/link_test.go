@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNoteTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   NoteTarget
+	}{
+		{"graph-theory", NoteTarget{Slug: "graph-theory"}},
+		{"graph-theory#thm:euler", NoteTarget{Slug: "graph-theory", Label: "thm:euler"}},
+		{" graph-theory # sec:intro ", NoteTarget{Slug: "graph-theory", Label: "sec:intro"}},
+		{"research:graph-theory", NoteTarget{Vault: "research", Slug: "graph-theory"}},
+		{"research:graph-theory#thm:euler", NoteTarget{Vault: "research", Slug: "graph-theory", Label: "thm:euler"}},
+	}
+
+	for _, tt := range tests {
+		got := ParseNoteTarget(tt.target)
+		if got != tt.want {
+			t.Errorf("ParseNoteTarget(%q) = %+v, want %+v", tt.target, got, tt.want)
+		}
+		if got.String() != tt.want.String() {
+			t.Errorf("String() = %q, want %q", got.String(), tt.want.String())
+		}
+	}
+}
+
+func TestNoteTarget_Local(t *testing.T) {
+	tests := []struct {
+		target    string
+		wantSlug  string
+		wantLocal bool
+	}{
+		{"graph-theory", "graph-theory", true},
+		{"home:graph-theory", "graph-theory", true},
+		{"research:graph-theory", "graph-theory", false},
+	}
+
+	for _, tt := range tests {
+		got, local := ParseNoteTarget(tt.target).Local("home")
+		if local != tt.wantLocal || got.Slug != tt.wantSlug {
+			t.Errorf("Local(%q) = %+v, %v, want slug %q, %v", tt.target, got, local, tt.wantSlug, tt.wantLocal)
+		}
+		if local && got.Vault != "" {
+			t.Errorf("Local(%q) kept vault %q", tt.target, got.Vault)
+		}
+	}
+}
+
+func TestExtractLabels(t *testing.T) {
+	content := `\section{Intro}\label{sec:intro}
+\begin{theorem}\label{thm:euler}\end{theorem}
+See \ref{thm:euler} and again \label{sec:intro}`
+
+	got := ExtractLabels(content)
+	want := []string{"sec:intro", "thm:euler"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestExtractLinks(t *testing.T) {
+	content := `\lxnote[see]{graph-theory#thm:euler}
+\lxembed{definitions}
+\ref{algebra} \cref{a,b}
+\input{../notes/20250101-topology.tex}
+\include{chapter1}
+\cite[p.~3]{logic}`
+
+	want := []struct {
+		kind   LinkKind
+		target string
+	}{
+		{LinkNote, "graph-theory#thm:euler"},
+		{LinkEmbed, "definitions"},
+		{LinkRef, "algebra"},
+		{LinkRef, "a"},
+		{LinkRef, "b"},
+		{LinkInput, "topology"},
+		{LinkInclude, "chapter1"},
+		{LinkCite, "logic"},
+	}
+
+	got := ExtractLinks(content)
+	if len(got) != len(want) {
+		t.Fatalf("ExtractLinks() returned %d links, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Kind != w.kind || got[i].Target.String() != w.target {
+			t.Errorf("link %d = %s %s, want %s %s", i, got[i].Kind, got[i].Target, w.kind, w.target)
+		}
+	}
+}
+
+func TestTagLinks(t *testing.T) {
+	links := TagLinks([]string{"math", "link:graph-theory"})
+	if len(links) != 1 || links[0].Kind != LinkTag || links[0].Target.Slug != "graph-theory" {
+		t.Errorf("TagLinks() = %+v, want one tag link to graph-theory", links)
+	}
+}
+
+func TestRenameLinkTarget(t *testing.T) {
+	content := `% tags: math, link:old-note
+\lxnote{old-note#sec:intro} \lxembed{old-note}
+\input{../notes/20250101-old-note.tex}
+\cite{other, old-note}
+\lxnote{old-note-two} \lxnote{team:old-note} \lxnote{home:old-note}`
+
+	got, count := RenameLinkTarget(content, "home", "old-note", "new-note")
+
+	want := `% tags: math, link:new-note
+\lxnote{new-note#sec:intro} \lxembed{new-note}
+\input{../notes/20250101-new-note.tex}
+\cite{other, new-note}
+\lxnote{old-note-two} \lxnote{team:old-note} \lxnote{home:new-note}`
+
+	if got != want {
+		t.Errorf("RenameLinkTarget() =\n%s\nwant\n%s", got, want)
+	}
+	if count != 6 {
+		t.Errorf("RenameLinkTarget() count = %d, want 6", count)
+	}
+}
+
+func TestExtractLinks_IgnoresInactiveCode(t *testing.T) {
+	content := `% \lxnote{commented}
+\iffalse \lxnote{disabled} \fi
+\begin{verbatim}\lxnote{verbatim}\end{verbatim}
+\lxnote{live}`
+
+	links := ExtractLinks(content)
+	if len(links) != 1 || links[0].Target.Slug != "live" {
+		t.Errorf("ExtractLinks() = %+v, want only the live link", links)
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+)
+
+// MathIndexVersion is bumped whenever the math index format or normalization changes
+// Indexes with another version are rebuilt from scratch
+const MathIndexVersion = "1.0"
+
+// MathIndex holds the formulas of every note, normalized for searching
+type MathIndex struct {
+	Version     string                 `json:"version"`
+	LastIndexed time.Time              `json:"last_indexed"`
+	Notes       map[string][]MathEntry `json:"notes"`
+}
+
+// MathEntry is one piece of math in a note
+type MathEntry struct {
+	Line       int    `json:"line"`
+	Env        string `json:"env"`             // Math environment, or the delimiter: $, $$, \( or \[
+	Label      string `json:"label,omitempty"` // \label of the formula
+	Text       string `json:"text"`            // Source, with whitespace collapsed
+	Normalized string `json:"normalized"`      // See latexscan.NormalizeMath
+}
+
+// NewMathIndex creates a new empty math index
+func NewMathIndex() *MathIndex {
+	return &MathIndex{
+		Version:     MathIndexVersion,
+		LastIndexed: time.Now(),
+		Notes:       make(map[string][]MathEntry),
+	}
+}
+
+// SetNote replaces the formulas of a note
+func (i *MathIndex) SetNote(slug string, entries []MathEntry) {
+	if len(entries) == 0 {
+		delete(i.Notes, slug)
+		return
+	}
+	i.Notes[slug] = entries
+}
+
+// RemoveNote removes the formulas of a note
+func (i *MathIndex) RemoveNote(slug string) {
+	delete(i.Notes, slug)
+}
+
+// Count returns the number of indexed formulas
+func (i *MathIndex) Count() int {
+	count := 0
+	for _, entries := range i.Notes {
+		count += len(entries)
+	}
+	return count
+}
+
+// UpdateLastIndexed updates the last indexed timestamp
+func (i *MathIndex) UpdateLastIndexed() {
+	i.LastIndexed = time.Now()
+}
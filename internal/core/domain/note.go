@@ -9,11 +9,13 @@ import (
 
 // NoteHeader represents the lightweight metadata of a note
 type NoteHeader struct {
-	Title    string   `yaml:"title"`
-	Date     string   `yaml:"date"`
-	Tags     []string `yaml:"tags"`
-	Slug     string   `yaml:"-"`
-	Filename string   `yaml:"-"`
+	Title    string    `yaml:"title"`
+	Date     string    `yaml:"date"`
+	Tags     []string  `yaml:"tags"`
+	Slug     string    `yaml:"-"`
+	Filename string    `yaml:"-"` // Path relative to the notes directory
+	Notebook string    `yaml:"-"` // Directory of the note inside the notes directory, "" at the top level
+	ModTime  time.Time `yaml:"-"` // Last modification of the note file
 }
 
 // NoteBody represents the full note with content
@@ -130,10 +132,10 @@ func NewNoteBody(header *NoteHeader, content string) *NoteBody {
 	}
 }
 
-// HasTag checks if the note has a specific tag
+// HasTag checks if the note has a specific tag, or a tag nested under it
 func (h *NoteHeader) HasTag(tag string) bool {
 	for _, t := range h.Tags {
-		if strings.EqualFold(t, tag) {
+		if TagMatches(t, tag) {
 			return true
 		}
 	}
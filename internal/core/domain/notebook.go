@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// NotebookSeparator separates the levels of a notebook, as in courses/math201
+const NotebookSeparator = "/"
+
+// NormalizeNotebook cleans a notebook path; the top level of the notes directory is ""
+func NormalizeNotebook(notebook string) string {
+	notebook = strings.TrimSpace(strings.ReplaceAll(notebook, `\`, NotebookSeparator))
+	notebook = strings.Trim(path.Clean(NotebookSeparator+notebook), NotebookSeparator)
+	return notebook
+}
+
+// ValidateNotebook checks that a notebook stays inside the notes directory
+func ValidateNotebook(notebook string) error {
+	raw := strings.ReplaceAll(notebook, `\`, NotebookSeparator)
+	for _, part := range strings.Split(raw, NotebookSeparator) {
+		if part == ".." {
+			return fmt.Errorf("notebook cannot leave the notes directory: %s", notebook)
+		}
+	}
+	for _, part := range strings.Split(NormalizeNotebook(notebook), NotebookSeparator) {
+		if strings.HasPrefix(part, ".") {
+			return fmt.Errorf("notebook cannot be hidden: %s", notebook)
+		}
+	}
+	return nil
+}
+
+// NotebookOf returns the notebook of a note filename relative to the notes directory
+// "courses/math201/20250101-limits.tex" -> "courses/math201"
+func NotebookOf(filename string) string {
+	return NormalizeNotebook(path.Dir(strings.ReplaceAll(filename, `\`, NotebookSeparator)))
+}
+
+// InNotebook reports whether a note is in the notebook or a notebook nested in it
+// The empty notebook is the whole vault
+func (h *NoteHeader) InNotebook(notebook string) bool {
+	notebook = NormalizeNotebook(notebook)
+	return notebook == "" || h.Notebook == notebook || strings.HasPrefix(h.Notebook, notebook+NotebookSeparator)
+}
+
+// QualifiedSlug prefixes a slug with its notebook, as in courses/math201/limits
+func QualifiedSlug(notebook, slug string) string {
+	if notebook == "" {
+		return slug
+	}
+	return notebook + NotebookSeparator + slug
+}
+
+// AssignSlugs returns the slug of each note filename, in the same order
+// A note keeps the slug of its filename, unless a note in another notebook has the
+// same one; then the notes inside notebooks are qualified as notebook/slug, so a note
+// at the top level keeps the short slug
+func AssignSlugs(filenames []string) []string {
+	slugs := make([]string, len(filenames))
+	counts := make(map[string]int, len(filenames))
+	for i, filename := range filenames {
+		slugs[i] = ParseFilename(path.Base(strings.ReplaceAll(filename, `\`, NotebookSeparator)))
+		counts[slugs[i]]++
+	}
+
+	for i, filename := range filenames {
+		if counts[slugs[i]] > 1 {
+			slugs[i] = QualifiedSlug(NotebookOf(filename), slugs[i])
+		}
+	}
+	return slugs
+}
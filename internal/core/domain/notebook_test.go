@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeNotebook(t *testing.T) {
+	tests := map[string]string{
+		"":                  "",
+		".":                 "",
+		"/":                 "",
+		"courses/math201/":  "courses/math201",
+		" /courses//math/ ": "courses/math",
+		`courses\math201`:   "courses/math201",
+	}
+
+	for input, want := range tests {
+		if got := NormalizeNotebook(input); got != want {
+			t.Errorf("NormalizeNotebook(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestValidateNotebook(t *testing.T) {
+	for _, notebook := range []string{"", ".", "courses/math201", "/courses/"} {
+		if err := ValidateNotebook(notebook); err != nil {
+			t.Errorf("ValidateNotebook(%q) failed: %v", notebook, err)
+		}
+	}
+	for _, notebook := range []string{"..", "courses/../../etc", ".trash", "courses/.git"} {
+		if err := ValidateNotebook(notebook); err == nil {
+			t.Errorf("ValidateNotebook(%q) should fail", notebook)
+		}
+	}
+}
+
+func TestNoteHeader_InNotebook(t *testing.T) {
+	header := NoteHeader{Notebook: NotebookOf("courses/math201/20250101-limits.tex")}
+
+	tests := map[string]bool{
+		"":                true,
+		"courses":         true,
+		"courses/math201": true,
+		"courses/math":    false,
+		"physics":         false,
+	}
+	for notebook, want := range tests {
+		if got := header.InNotebook(notebook); got != want {
+			t.Errorf("InNotebook(%q) = %v, want %v", notebook, got, want)
+		}
+	}
+}
+
+func TestAssignSlugs(t *testing.T) {
+	filenames := []string{
+		"20250101-limits.tex",
+		"courses/math201/limits.tex",
+		"courses/math201/20250102-series.tex",
+		"courses/math202/integrals.tex",
+		"archive/integrals.tex",
+	}
+	want := []string{
+		"limits",
+		"courses/math201/limits",
+		"series",
+		"courses/math202/integrals",
+		"archive/integrals",
+	}
+
+	if got := AssignSlugs(filenames); !reflect.DeepEqual(got, want) {
+		t.Errorf("AssignSlugs() = %v, want %v", got, want)
+	}
+}
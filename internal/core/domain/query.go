@@ -0,0 +1,564 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Query fields understood by ParseQuery
+const (
+	FieldTag      = "tag"
+	FieldTitle    = "title"
+	FieldSlug     = "slug"
+	FieldDate     = "date"
+	FieldLinksTo  = "links-to"
+	FieldHas      = "has"
+	FieldOrphan   = "orphan"
+	FieldNotebook = "notebook"
+)
+
+// QueryFields lists the supported fields, for help texts and errors
+var QueryFields = []string{FieldTag, FieldTitle, FieldSlug, FieldDate, FieldLinksTo, FieldHas, FieldOrphan, FieldNotebook}
+
+// hasValues are the properties has: can test for
+var hasValues = []string{"todo", "links", "backlinks", "embeds", "tags", "assets", "labels"}
+
+// savedSearchPattern matches a saved search reference such as @physics-open
+var savedSearchPattern = regexp.MustCompile(`^@([A-Za-z0-9_-]+)`)
+
+// maxSavedSearchDepth limits how deeply saved searches may refer to each other
+const maxSavedSearchDepth = 8
+
+// datePattern accepts a year, a month or a day
+var datePattern = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2})?)?$`)
+
+// QueryNode is a node of a parsed query
+type QueryNode interface {
+	// Match reports whether an indexed note satisfies the node
+	Match(slug string, entry IndexEntry) bool
+	String() string
+}
+
+// AndNode matches notes matching all of its children
+type AndNode struct{ Children []QueryNode }
+
+// OrNode matches notes matching any of its children
+type OrNode struct{ Children []QueryNode }
+
+// NotNode matches notes its child does not match
+type NotNode struct{ Child QueryNode }
+
+// FieldNode matches a field against a value, as in tag:physics or date:>=2025-09
+type FieldNode struct {
+	Field string
+	Op    string // Comparison for dates: =, <, <=, >, >=
+	Value string
+}
+
+// TextNode matches free text against titles, slugs and tags
+type TextNode struct{ Text string }
+
+// Query is a parsed note query
+// Terms are combined with AND; OR, a leading - and parentheses are also supported:
+//
+//	tag:physics -tag:draft date:>=2025-09 title:"graph" links-to:linear-algebra has:todo orphan:true
+type Query struct {
+	Root QueryNode // nil matches every note
+}
+
+// ParseQuery parses a query string into its syntax tree
+func ParseQuery(input string) (*Query, error) {
+	tokens, err := lexQuery(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &queryParser{tokens: tokens}
+	if len(tokens) == 0 {
+		return &Query{}, nil
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in query", p.tokens[p.pos].text)
+	}
+
+	return &Query{Root: root}, nil
+}
+
+// ExpandSavedSearches replaces @name references with the saved queries, in parentheses
+// Saved searches may refer to each other, but not in a cycle
+func ExpandSavedSearches(input string, saved map[string]string) (string, error) {
+	return expandSavedSearches(input, saved, nil)
+}
+
+func expandSavedSearches(input string, saved map[string]string, stack []string) (string, error) {
+	if !strings.Contains(input, "@") {
+		return input, nil
+	}
+
+	var sb strings.Builder
+	inQuote := false
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		if c == '"' {
+			inQuote = !inQuote
+		}
+
+		// A reference starts a term: at the beginning, after a space, ( or -
+		atTerm := i == 0 || strings.ContainsRune(" \t(-", rune(input[i-1]))
+		match := savedSearchPattern.FindStringSubmatch(input[i:])
+		if c != '@' || inQuote || !atTerm || match == nil {
+			sb.WriteByte(c)
+			continue
+		}
+
+		name := match[1]
+		query, ok := saved[name]
+		if !ok {
+			return "", fmt.Errorf("unknown saved search: @%s", name)
+		}
+		if slices.Contains(stack, name) || len(stack) >= maxSavedSearchDepth {
+			return "", fmt.Errorf("saved search @%s refers to itself", name)
+		}
+
+		expanded, err := expandSavedSearches(query, saved, append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("(" + expanded + ")")
+		i += len(match[0]) - 1
+	}
+
+	return sb.String(), nil
+}
+
+// Match reports whether an indexed note satisfies the query
+func (q *Query) Match(slug string, entry IndexEntry) bool {
+	return q.Root == nil || q.Root.Match(slug, entry)
+}
+
+// IsPlainText reports whether the query is only free text, without fields or operators
+func (q *Query) IsPlainText() bool {
+	switch root := q.Root.(type) {
+	case nil, *TextNode:
+		return true
+	case *AndNode:
+		for _, child := range root.Children {
+			if _, ok := child.(*TextNode); !ok {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the query in canonical form
+func (q *Query) String() string {
+	if q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+func (n *AndNode) Match(slug string, entry IndexEntry) bool {
+	for _, child := range n.Children {
+		if !child.Match(slug, entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *AndNode) String() string {
+	return joinNodes(n.Children, " ")
+}
+
+func (n *OrNode) Match(slug string, entry IndexEntry) bool {
+	for _, child := range n.Children {
+		if child.Match(slug, entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *OrNode) String() string {
+	return "(" + joinNodes(n.Children, " OR ") + ")"
+}
+
+func (n *NotNode) Match(slug string, entry IndexEntry) bool {
+	return !n.Child.Match(slug, entry)
+}
+
+func (n *NotNode) String() string {
+	return "-" + n.Child.String()
+}
+
+func (n *FieldNode) Match(slug string, entry IndexEntry) bool {
+	value := strings.ToLower(n.Value)
+
+	switch n.Field {
+	case FieldTag:
+		return slices.ContainsFunc(entry.Tags, func(tag string) bool { return TagMatches(tag, n.Value) })
+	case FieldTitle:
+		return strings.Contains(strings.ToLower(entry.Title), value)
+	case FieldSlug:
+		return strings.Contains(slug, value)
+	case FieldDate:
+		return compareDate(entry.Date, n.Op, n.Value)
+	case FieldLinksTo:
+		return slices.Contains(entry.OutgoingLinks, value) || slices.Contains(entry.Embeds, value)
+	case FieldHas:
+		return hasProperty(entry, value)
+	case FieldOrphan:
+		orphan, _ := strconv.ParseBool(value)
+		return entry.IsOrphan() == orphan
+	case FieldNotebook:
+		header := NoteHeader{Notebook: NotebookOf(entry.Filename)}
+		return header.InNotebook(n.Value)
+	default:
+		return false
+	}
+}
+
+func (n *FieldNode) String() string {
+	value := n.Value
+	if strings.ContainsAny(value, " ()") {
+		value = strconv.Quote(value)
+	}
+	if n.Op == "=" {
+		return n.Field + ":" + value
+	}
+	return n.Field + ":" + n.Op + value
+}
+
+func (n *TextNode) Match(slug string, entry IndexEntry) bool {
+	text := strings.ToLower(n.Text)
+	if strings.Contains(strings.ToLower(entry.Title), text) || strings.Contains(slug, text) {
+		return true
+	}
+	return slices.ContainsFunc(entry.Tags, func(tag string) bool { return strings.Contains(strings.ToLower(tag), text) })
+}
+
+func (n *TextNode) String() string {
+	if strings.ContainsAny(n.Text, " ()") {
+		return strconv.Quote(n.Text)
+	}
+	return n.Text
+}
+
+// IsOrphan reports whether a note has no links in or out
+func (e IndexEntry) IsOrphan() bool {
+	return len(e.OutgoingLinks) == 0 && len(e.Backlinks) == 0 && len(e.Embeds) == 0 && len(e.EmbeddedIn) == 0
+}
+
+// hasProperty tests the properties of has:
+func hasProperty(entry IndexEntry, property string) bool {
+	switch property {
+	case "todo":
+		return entry.Todos > 0
+	case "links":
+		return len(entry.OutgoingLinks) > 0
+	case "backlinks":
+		return len(entry.Backlinks) > 0
+	case "embeds":
+		return len(entry.Embeds) > 0
+	case "tags":
+		return len(entry.Tags) > 0
+	case "assets":
+		return len(entry.Assets) > 0
+	case "labels":
+		return len(entry.Labels) > 0
+	default:
+		return false
+	}
+}
+
+// compareDate compares a note date with a year, month or day
+// The note date is cut to the precision of the value, so date:2025-09 is all of September
+func compareDate(date, op, value string) bool {
+	if len(date) > len(value) {
+		date = date[:len(value)]
+	}
+
+	switch op {
+	case "<":
+		return date < value
+	case "<=":
+		return date <= value
+	case ">":
+		return date > value
+	case ">=":
+		return date >= value
+	default:
+		return date == value
+	}
+}
+
+func joinNodes(nodes []QueryNode, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// queryToken is a lexical element of a query
+type queryToken struct {
+	kind queryTokenKind
+	text string
+	node QueryNode // Set for terms
+}
+
+type queryTokenKind int
+
+const (
+	tokenTerm queryTokenKind = iota
+	tokenNot
+	tokenOr
+	tokenOpen
+	tokenClose
+)
+
+// lexQuery splits a query into terms, operators and parentheses
+func lexQuery(input string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, text: ")"})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, queryToken{kind: tokenNot, text: "-"})
+			i++
+		default:
+			word, next, err := readQueryWord(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			i = next
+
+			if word.text == "OR" && !word.quoted {
+				tokens = append(tokens, queryToken{kind: tokenOr, text: "OR"})
+				continue
+			}
+
+			node, err := parseTerm(word)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, queryToken{kind: tokenTerm, text: word.text, node: node})
+		}
+	}
+
+	return tokens, nil
+}
+
+// queryWord is a raw term: an optional field prefix and a possibly quoted value
+type queryWord struct {
+	text   string // Full source text of the term
+	field  string
+	value  string
+	quoted bool
+}
+
+// readQueryWord reads a term starting at i, returning it and the index after it
+func readQueryWord(runes []rune, i int) (queryWord, int, error) {
+	start := i
+	var word queryWord
+
+	// An optional field: letters and dashes followed by a colon
+	j := i
+	for j < len(runes) && (unicode.IsLetter(runes[j]) || runes[j] == '-') {
+		j++
+	}
+	if j > i && j < len(runes) && runes[j] == ':' {
+		word.field = strings.ToLower(string(runes[i:j]))
+		i = j + 1
+	}
+
+	// The value, quoted or up to the next space or parenthesis
+	if i < len(runes) && runes[i] == '"' {
+		end := i + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		if end >= len(runes) {
+			return word, 0, fmt.Errorf("unterminated quote in query")
+		}
+		word.value = string(runes[i+1 : end])
+		word.quoted = true
+		i = end + 1
+	} else {
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' {
+			end++
+		}
+		word.value = string(runes[i:end])
+		i = end
+	}
+
+	word.text = string(runes[start:i])
+	return word, i, nil
+}
+
+// parseTerm validates a term and turns it into a node
+func parseTerm(word queryWord) (QueryNode, error) {
+	if word.field == "" {
+		return &TextNode{Text: word.value}, nil
+	}
+
+	node := &FieldNode{Field: word.field, Op: "=", Value: word.value}
+
+	switch word.field {
+	case FieldTag, FieldTitle, FieldSlug, FieldLinksTo, FieldNotebook:
+	case FieldDate:
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(node.Value, op) {
+				node.Op = op
+				node.Value = strings.TrimPrefix(node.Value, op)
+				break
+			}
+		}
+		if !datePattern.MatchString(node.Value) {
+			return nil, fmt.Errorf("invalid date in %q (expected YYYY, YYYY-MM or YYYY-MM-DD)", word.text)
+		}
+	case FieldHas:
+		node.Value = strings.ToLower(node.Value)
+		if !slices.Contains(hasValues, node.Value) {
+			return nil, fmt.Errorf("unknown property in %q (expected one of: %s)", word.text, strings.Join(hasValues, ", "))
+		}
+	case FieldOrphan:
+		if _, err := strconv.ParseBool(node.Value); err != nil {
+			return nil, fmt.Errorf("invalid value in %q (expected true or false)", word.text)
+		}
+	default:
+		return nil, fmt.Errorf("unknown query field %q (expected one of: %s)", word.field, strings.Join(QueryFields, ", "))
+	}
+
+	if node.Value == "" {
+		return nil, fmt.Errorf("missing value in %q", word.text)
+	}
+	return node, nil
+}
+
+// queryParser builds the syntax tree from tokens
+// OR binds looser than the implicit AND, so "a b OR c" is "(a b) OR c"
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() (queryToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return queryToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	children := []QueryNode{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokenOr {
+			break
+		}
+		p.pos++
+
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, next)
+	}
+
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	var children []QueryNode
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokenOr || tok.kind == tokenClose {
+			break
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+
+	switch len(children) {
+	case 0:
+		return nil, fmt.Errorf("empty expression in query")
+	case 1:
+		return children[0], nil
+	default:
+		return &AndNode{Children: children}, nil
+	}
+}
+
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	tok, _ := p.peek()
+	p.pos++
+
+	switch tok.kind {
+	case tokenNot:
+		next, ok := p.peek()
+		if !ok {
+			return nil, fmt.Errorf("nothing to negate at end of query")
+		}
+		if next.kind != tokenTerm && next.kind != tokenOpen && next.kind != tokenNot {
+			return nil, fmt.Errorf("nothing to negate before %s in query", next.text)
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child}, nil
+	case tokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokenClose {
+			return nil, fmt.Errorf("missing ) in query")
+		}
+		p.pos++
+		return node, nil
+	default:
+		if tok.node == nil {
+			return nil, fmt.Errorf("unexpected %s in query", tok.text)
+		}
+		return tok.node, nil
+	}
+}
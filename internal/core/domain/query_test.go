@@ -0,0 +1,156 @@
+package domain
+
+import (
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{"graph theory", "graph theory"},
+		{`tag:physics -tag:draft date:>=2025-09 title:"graph theory"`, `tag:physics -tag:draft date:>=2025-09 title:"graph theory"`},
+		{"links-to:linear-algebra has:TODO orphan:true", "links-to:linear-algebra has:todo orphan:true"},
+		{"tag:a tag:b OR tag:c", "(tag:a tag:b OR tag:c)"},
+		{"tag:a (tag:b OR -tag:c)", "tag:a (tag:b OR -tag:c)"},
+		{"well-known", "well-known"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			query, err := ParseQuery(tt.input)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) failed: %v", tt.input, err)
+			}
+			if got := query.String(); got != tt.want {
+				t.Errorf("ParseQuery(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Errors(t *testing.T) {
+	for _, input := range []string{
+		"colour:red",
+		"date:last-week",
+		"has:coffee",
+		"orphan:maybe",
+		`title:"unterminated`,
+		"(tag:a",
+		"tag:a )",
+		"tag:",
+		"tag:a OR",
+		"a -)",
+		"-OR b",
+		"-)",
+	} {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("ParseQuery(%q) expected an error", input)
+		}
+	}
+}
+
+func TestQuery_Match(t *testing.T) {
+	entry := IndexEntry{
+		Title:         "Graph Theory Basics",
+		Date:          "2025-09-14",
+		Tags:          []string{"math", "Draft"},
+		OutgoingLinks: []string{"linear-algebra"},
+		Todos:         2,
+		Filename:      "courses/math201/20250914-graph-theory-basics.tex",
+	}
+	orphan := IndexEntry{Title: "Loose Thoughts", Date: "2024-01-02", Filename: "loose-thoughts.tex"}
+
+	tests := []struct {
+		query      string
+		wantEntry  bool
+		wantOrphan bool
+	}{
+		{"tag:math", true, false},
+		{"tag:draft", true, false},
+		{"-tag:draft", false, true},
+		{"date:2025-09", true, false},
+		{"date:>=2025-09", true, false},
+		{"date:>2025-09", false, false},
+		{"date:<2025", false, true},
+		{"title:graph", true, false},
+		{"links-to:linear-algebra", true, false},
+		{"has:todo", true, false},
+		{"orphan:true", false, true},
+		{"orphan:false has:links", true, false},
+		{"tag:math OR title:loose", true, true},
+		{"basics", true, false},
+		{"thoughts -tag:math", false, true},
+		{"notebook:courses", true, false},
+		{"notebook:courses/math201", true, false},
+		{"notebook:courses/math", false, false},
+		{"-notebook:courses", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery failed: %v", err)
+			}
+			if got := query.Match("graph-theory-basics", entry); got != tt.wantEntry {
+				t.Errorf("Match(entry) = %v, want %v", got, tt.wantEntry)
+			}
+			if got := query.Match("loose-thoughts", orphan); got != tt.wantOrphan {
+				t.Errorf("Match(orphan) = %v, want %v", got, tt.wantOrphan)
+			}
+		})
+	}
+}
+
+func TestQuery_IsPlainText(t *testing.T) {
+	for input, want := range map[string]bool{
+		"":                true,
+		"graph":           true,
+		"graph theory":    true,
+		"tag:math":        false,
+		"graph -theory":   false,
+		"graph OR theory": false,
+	} {
+		query, _ := ParseQuery(input)
+		if got := query.IsPlainText(); got != want {
+			t.Errorf("IsPlainText(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestExpandSavedSearches(t *testing.T) {
+	saved := map[string]string{
+		"open":      "has:todo",
+		"physics":   "tag:physics @open",
+		"loop":      "@loop",
+		"loop-a":    "@loop-b",
+		"loop-b":    "tag:x OR @loop-a",
+		"with-dash": "tag:a",
+	}
+
+	for input, want := range map[string]string{
+		"@open":                  "(has:todo)",
+		"@physics -tag:draft":    "(tag:physics (has:todo)) -tag:draft",
+		"-@open OR (@with-dash)": "-(has:todo) OR ((tag:a))",
+		`"@open" email@open`:     `"@open" email@open`,
+		"tag:math":               "tag:math",
+	} {
+		got, err := ExpandSavedSearches(input, saved)
+		if err != nil {
+			t.Errorf("ExpandSavedSearches(%q) failed: %v", input, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ExpandSavedSearches(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"@missing", "@loop", "@loop-a"} {
+		if _, err := ExpandSavedSearches(input, saved); err == nil {
+			t.Errorf("ExpandSavedSearches(%q): expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"time"
+)
+
+// SearchIndexVersion is bumped whenever the search index format or tokenization changes
+// Indexes with another version are rebuilt from scratch
+const SearchIndexVersion = "1.0"
+
+// SearchIndex is an inverted index of note content, used for ranked full-text search
+type SearchIndex struct {
+	Version     string                    `json:"version"`
+	LastIndexed time.Time                 `json:"last_indexed"`
+	Documents   map[string]SearchDocument `json:"documents"`
+	Postings    map[string][]Posting      `json:"postings"` // Term -> notes containing it
+	TotalLength int                       `json:"total_length"`
+}
+
+// SearchDocument is the per-note data needed for ranking
+type SearchDocument struct {
+	Length int `json:"length"` // Number of terms in the note
+}
+
+// Posting records how often a term occurs in a note
+type Posting struct {
+	Slug string `json:"slug"`
+	Freq int    `json:"freq"`
+}
+
+// NewSearchIndex creates a new empty search index
+func NewSearchIndex() *SearchIndex {
+	return &SearchIndex{
+		Version:     SearchIndexVersion,
+		LastIndexed: time.Now(),
+		Documents:   make(map[string]SearchDocument),
+		Postings:    make(map[string][]Posting),
+	}
+}
+
+// AddDocument adds a note with its term frequencies
+// A note that is already indexed must be removed first
+func (i *SearchIndex) AddDocument(slug string, freqs map[string]int) {
+	length := 0
+	for term, freq := range freqs {
+		i.Postings[term] = append(i.Postings[term], Posting{Slug: slug, Freq: freq})
+		length += freq
+	}
+
+	i.Documents[slug] = SearchDocument{Length: length}
+	i.TotalLength += length
+}
+
+// RemoveDocuments removes notes and their postings in a single pass
+func (i *SearchIndex) RemoveDocuments(slugs map[string]bool) {
+	removed := false
+	for slug := range slugs {
+		if doc, exists := i.Documents[slug]; exists {
+			i.TotalLength -= doc.Length
+			delete(i.Documents, slug)
+			removed = true
+		}
+	}
+	if !removed {
+		return
+	}
+
+	for term, postings := range i.Postings {
+		kept := postings[:0]
+		for _, posting := range postings {
+			if !slugs[posting.Slug] {
+				kept = append(kept, posting)
+			}
+		}
+		if len(kept) == 0 {
+			delete(i.Postings, term)
+		} else {
+			i.Postings[term] = kept
+		}
+	}
+}
+
+// HasDocument checks if a note is indexed
+func (i *SearchIndex) HasDocument(slug string) bool {
+	_, exists := i.Documents[slug]
+	return exists
+}
+
+// Count returns the number of indexed notes
+func (i *SearchIndex) Count() int {
+	return len(i.Documents)
+}
+
+// AverageLength returns the mean number of terms per note
+func (i *SearchIndex) AverageLength() float64 {
+	if len(i.Documents) == 0 {
+		return 0
+	}
+	return float64(i.TotalLength) / float64(len(i.Documents))
+}
+
+// UpdateLastIndexed updates the last indexed timestamp
+func (i *SearchIndex) UpdateLastIndexed() {
+	i.LastIndexed = time.Now()
+}
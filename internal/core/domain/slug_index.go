@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SlugIndexVersion is bumped whenever the slug index format changes
+// Indexes with another version are rebuilt from scratch
+const SlugIndexVersion = "1.0"
+
+// racyWindow is how recent a directory change may be for its mtime to be trusted
+// Filesystems with coarse timestamps can change a directory twice within one tick
+const racyWindow = 2 * time.Second
+
+// ErrAmbiguousSlug is returned when notes in several notebooks share a slug
+// They have to be named by their qualified notebook/slug instead
+var ErrAmbiguousSlug = errors.New("slug is used in several notebooks")
+
+// ErrDuplicateSlug is returned when several files in one notebook map to the same slug
+var ErrDuplicateSlug = errors.New("slug is used by several files")
+
+// SlugIndex maps slugs to note files, and caches their headers
+// It is valid as long as no notebook directory changed since ScannedAt
+type SlugIndex struct {
+	Version   string               `json:"version"`
+	ScannedAt time.Time            `json:"scanned_at"`
+	Dirs      map[string]time.Time `json:"dirs"`  // Notebook -> directory mtime; "" is the notes directory
+	Files     map[string]SlugEntry `json:"files"` // Filename relative to the notes directory -> entry
+
+	bySlug map[string][]string // Slug -> filenames, sorted
+}
+
+// SlugEntry is a note file and the header read from it
+type SlugEntry struct {
+	Slug    string    `json:"slug"`
+	ModTime time.Time `json:"mod_time"` // Mtime of the file when the header was read
+	Title   string    `json:"title"`
+	Date    string    `json:"date"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+// NewSlugIndex creates a new empty slug index
+func NewSlugIndex() *SlugIndex {
+	return &SlugIndex{
+		Version: SlugIndexVersion,
+		Dirs:    make(map[string]time.Time),
+		Files:   make(map[string]SlugEntry),
+	}
+}
+
+// DirFresh reports whether a notebook directory is unchanged since the scan
+// Directories changed just before the scan are never fresh, as a change in the
+// same timestamp tick would leave their mtime as it is
+func (i *SlugIndex) DirFresh(notebook string, modTime time.Time) bool {
+	recorded, ok := i.Dirs[notebook]
+	return ok && recorded.Equal(modTime) && modTime.Before(i.ScannedAt.Add(-racyWindow))
+}
+
+// AssignSlugs gives every file its slug, qualifying the ones notebooks share
+func (i *SlugIndex) AssignSlugs() {
+	filenames := make([]string, 0, len(i.Files))
+	for filename := range i.Files {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	i.bySlug = make(map[string][]string, len(filenames))
+	for n, slug := range AssignSlugs(filenames) {
+		entry := i.Files[filenames[n]]
+		entry.Slug = slug
+		i.Files[filenames[n]] = entry
+		i.bySlug[slug] = append(i.bySlug[slug], filenames[n])
+	}
+}
+
+// Lookup returns the file of a slug, and the slug the file is known by
+// Notes can also be found by their qualified notebook/slug, even when the short slug is unique
+func (i *SlugIndex) Lookup(slug string) (string, string, error) {
+	if i.bySlug == nil {
+		i.AssignSlugs()
+	}
+
+	if files := i.bySlug[slug]; len(files) == 1 {
+		return files[0], slug, nil
+	} else if len(files) > 1 {
+		return "", "", fmt.Errorf("%w: %s is %s; rename one of them", ErrDuplicateSlug, slug, strings.Join(files, ", "))
+	}
+
+	// A qualified slug of a note known by its short slug, or a short slug shared by notebooks
+	var candidates []string
+	for filename, entry := range i.Files {
+		short := ParseFilename(path.Base(filename))
+		if QualifiedSlug(NotebookOf(filename), short) == slug {
+			return filename, entry.Slug, nil
+		}
+		if short == slug {
+			candidates = append(candidates, entry.Slug)
+		}
+	}
+	if len(candidates) > 0 {
+		sort.Strings(candidates)
+		candidates = slices.Compact(candidates)
+		if len(candidates) == 1 {
+			// Files in one notebook sharing a slug
+			return i.Lookup(candidates[0])
+		}
+		return "", "", fmt.Errorf("%w: %s could be %s", ErrAmbiguousSlug, slug, strings.Join(candidates, ", "))
+	}
+
+	return "", "", fmt.Errorf("note not found: %s", slug)
+}
+
+// Duplicates returns the slugs that several files map to, with their files
+func (i *SlugIndex) Duplicates() map[string][]string {
+	if i.bySlug == nil {
+		i.AssignSlugs()
+	}
+
+	duplicates := make(map[string][]string)
+	for slug, files := range i.bySlug {
+		if len(files) > 1 {
+			duplicates[slug] = files
+		}
+	}
+	return duplicates
+}
+
+// Header returns the cached header of a note file
+func (i *SlugIndex) Header(filename string) NoteHeader {
+	entry := i.Files[filename]
+	return NoteHeader{
+		Title:    entry.Title,
+		Date:     entry.Date,
+		Tags:     entry.Tags,
+		Slug:     entry.Slug,
+		Filename: filename,
+		Notebook: NotebookOf(filename),
+		ModTime:  entry.ModTime,
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestSlugIndex(filenames ...string) *SlugIndex {
+	index := NewSlugIndex()
+	for _, filename := range filenames {
+		index.Files[filename] = SlugEntry{Title: filename}
+	}
+	index.AssignSlugs()
+	return index
+}
+
+func TestSlugIndex_Lookup(t *testing.T) {
+	index := newTestSlugIndex(
+		"20250101-graph-theory.tex",
+		"courses/math201/limits.tex",
+		"courses/math201/series.tex",
+		"courses/math202/series.tex",
+	)
+
+	tests := []struct {
+		slug         string
+		wantFile     string
+		wantSlug     string
+		wantErr      error
+		wantNotFound bool
+	}{
+		{slug: "graph-theory", wantFile: "20250101-graph-theory.tex", wantSlug: "graph-theory"},
+		{slug: "limits", wantFile: "courses/math201/limits.tex", wantSlug: "limits"},
+		{slug: "courses/math201/limits", wantFile: "courses/math201/limits.tex", wantSlug: "limits"},
+		{slug: "courses/math202/series", wantFile: "courses/math202/series.tex", wantSlug: "courses/math202/series"},
+		{slug: "series", wantErr: ErrAmbiguousSlug},
+		{slug: "missing", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.slug, func(t *testing.T) {
+			file, slug, err := index.Lookup(tt.slug)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.wantNotFound:
+				if err == nil {
+					t.Errorf("expected an error, got %s", file)
+				}
+			case err != nil:
+				t.Errorf("Lookup failed: %v", err)
+			case file != tt.wantFile || slug != tt.wantSlug:
+				t.Errorf("Lookup() = %s, %s, want %s, %s", file, slug, tt.wantFile, tt.wantSlug)
+			}
+		})
+	}
+}
+
+func TestSlugIndex_Duplicates(t *testing.T) {
+	index := newTestSlugIndex(
+		"20250101-limits.tex",
+		"limits.tex",
+		"courses/series.tex",
+		"courses/2025-01-02-series.tex",
+		"unique.tex",
+	)
+
+	want := map[string][]string{
+		"limits":         {"20250101-limits.tex", "limits.tex"},
+		"courses/series": {"courses/2025-01-02-series.tex", "courses/series.tex"},
+	}
+	if got := index.Duplicates(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Duplicates() = %v, want %v", got, want)
+	}
+
+	// Neither file is returned silently
+	for _, slug := range []string{"limits", "series", "courses/series"} {
+		if _, _, err := index.Lookup(slug); !errors.Is(err, ErrDuplicateSlug) {
+			t.Errorf("Lookup(%s): expected ErrDuplicateSlug, got %v", slug, err)
+		}
+	}
+}
+
+func TestSlugIndex_DirFresh(t *testing.T) {
+	index := NewSlugIndex()
+	index.ScannedAt = time.Now()
+	old := index.ScannedAt.Add(-time.Hour)
+	recent := index.ScannedAt.Add(-time.Second / 2)
+	index.Dirs[""] = old
+	index.Dirs["courses"] = recent
+
+	if !index.DirFresh("", old) {
+		t.Error("expected an unchanged directory to be fresh")
+	}
+	if index.DirFresh("", old.Add(time.Second)) {
+		t.Error("expected a changed directory to be stale")
+	}
+	if index.DirFresh("courses", recent) {
+		t.Error("expected a directory changed just before the scan to be stale")
+	}
+	if index.DirFresh("physics", old) {
+		t.Error("expected an unknown directory to be stale")
+	}
+}
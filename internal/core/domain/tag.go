@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TagSeparator separates the levels of a nested tag, as in math/algebra/groups
+const TagSeparator = "/"
+
+// NormalizeTag trims spaces and stray separators from a tag
+func NormalizeTag(tag string) string {
+	parts := strings.Split(tag, TagSeparator)
+	kept := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, TagSeparator)
+}
+
+// ValidateTag checks that a tag can be written to a tags header
+func ValidateTag(tag string) error {
+	if NormalizeTag(tag) == "" {
+		return fmt.Errorf("tag cannot be empty")
+	}
+	if strings.Contains(tag, ",") {
+		return fmt.Errorf("tag cannot contain a comma: %s", tag)
+	}
+	return nil
+}
+
+// TagMatches reports whether a tag is the filter tag or nested under it
+// Matching is case-insensitive, so math matches Math and math/algebra
+func TagMatches(tag, filter string) bool {
+	tag, filter = strings.ToLower(NormalizeTag(tag)), strings.ToLower(NormalizeTag(filter))
+	return tag == filter || strings.HasPrefix(tag, filter+TagSeparator)
+}
+
+// RenameTag moves a tag from one place in the hierarchy to another
+// Tags nested under from move along, so renaming math to maths turns
+// math/algebra into maths/algebra. Other tags are returned unchanged
+func RenameTag(tag, from, to string) (string, bool) {
+	if !TagMatches(tag, from) {
+		return tag, false
+	}
+	rest := NormalizeTag(tag)[len(NormalizeTag(from)):]
+	return NormalizeTag(to) + rest, true
+}
+
+// TagNode is a tag in the tag hierarchy
+type TagNode struct {
+	Name     string // Last level, e.g. groups
+	Path     string // Full tag, e.g. math/algebra/groups
+	Notes    int    // Notes with exactly this tag
+	Total    int    // Notes with this tag or one nested under it
+	Children []*TagNode
+}
+
+// BuildTagTree arranges the tags of notes into a hierarchy, sorted by name
+// Each element of noteTags is the tags of one note
+func BuildTagTree(noteTags [][]string) []*TagNode {
+	nodes := make(map[string]*TagNode) // Lowercased path -> node
+	var roots []*TagNode
+
+	node := func(path string) *TagNode {
+		key := strings.ToLower(path)
+		if n, ok := nodes[key]; ok {
+			return n
+		}
+		n := &TagNode{Name: path[strings.LastIndex(path, TagSeparator)+1:], Path: path}
+		nodes[key] = n
+		if i := strings.LastIndex(path, TagSeparator); i >= 0 {
+			parent := nodes[strings.ToLower(path[:i])]
+			parent.Children = append(parent.Children, n)
+		} else {
+			roots = append(roots, n)
+		}
+		return n
+	}
+
+	for _, tags := range noteTags {
+		counted := make(map[*TagNode]bool)
+		seen := make(map[string]bool)
+		for _, tag := range tags {
+			tag = NormalizeTag(tag)
+			if tag == "" || seen[strings.ToLower(tag)] {
+				continue
+			}
+			seen[strings.ToLower(tag)] = true
+
+			// Create every level, so the parent exists before its child
+			levels := strings.Split(tag, TagSeparator)
+			for i := range levels {
+				n := node(strings.Join(levels[:i+1], TagSeparator))
+				if !counted[n] {
+					counted[n] = true
+					n.Total++
+				}
+			}
+			nodes[strings.ToLower(tag)].Notes++
+		}
+	}
+
+	sortTagNodes(roots)
+	return roots
+}
+
+// sortTagNodes sorts tag nodes and their children by name
+func sortTagNodes(nodes []*TagNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, n := range nodes {
+		sortTagNodes(n.Children)
+	}
+}
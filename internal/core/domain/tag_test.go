@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTagMatches(t *testing.T) {
+	tests := []struct {
+		tag, filter string
+		want        bool
+	}{
+		{"math", "math", true},
+		{"Math/Algebra", "math", true},
+		{"math/algebra/groups", "math/algebra", true},
+		{"math/algebra", "math/algebra/", true},
+		{"mathematics", "math", false},
+		{"math", "math/algebra", false},
+		{"physics/math", "math", false},
+	}
+
+	for _, tt := range tests {
+		if got := TagMatches(tt.tag, tt.filter); got != tt.want {
+			t.Errorf("TagMatches(%q, %q) = %v, want %v", tt.tag, tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tests := []struct {
+		tag, from, to string
+		want          string
+		renamed       bool
+	}{
+		{"math", "math", "maths", "maths", true},
+		{"math/algebra/groups", "math", "maths", "maths/algebra/groups", true},
+		{"math/algebra", "math/algebra", "algebra", "algebra", true},
+		{"mathematics", "math", "maths", "mathematics", false},
+		{"physics", "math", "maths", "physics", false},
+	}
+
+	for _, tt := range tests {
+		got, renamed := RenameTag(tt.tag, tt.from, tt.to)
+		if got != tt.want || renamed != tt.renamed {
+			t.Errorf("RenameTag(%q, %q, %q) = %q, %v; want %q, %v", tt.tag, tt.from, tt.to, got, renamed, tt.want, tt.renamed)
+		}
+	}
+}
+
+func TestBuildTagTree(t *testing.T) {
+	tree := BuildTagTree([][]string{
+		{"math/algebra/groups", "physics"},
+		{"math/algebra", "math/analysis"},
+		{"math", "Math"},
+	})
+
+	type flat struct {
+		Path         string
+		Notes, Total int
+	}
+	var got []flat
+	var walk func(nodes []*TagNode)
+	walk = func(nodes []*TagNode) {
+		for _, n := range nodes {
+			got = append(got, flat{n.Path, n.Notes, n.Total})
+			walk(n.Children)
+		}
+	}
+	walk(tree)
+
+	want := []flat{
+		{"math", 1, 3},
+		{"math/algebra", 1, 2},
+		{"math/algebra/groups", 1, 1},
+		{"math/analysis", 1, 1},
+		{"physics", 1, 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BuildTagTree() = %+v, want %+v", got, want)
+	}
+}
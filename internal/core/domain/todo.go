@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// commentTodoPattern matches "% TODO: ..." comments
+var commentTodoPattern = regexp.MustCompile(`^%+\s*TODO:\s*(.*)`)
+
+// Todo is a task found in note content
+type Todo struct {
+	Text  string
+	Line  int  // 1-based line of the task
+	Latex bool // \todo{...} rather than a "% TODO:" comment
+
+	// Location of the \todo{...} command in the content (Latex only)
+	Start int
+	End   int
+}
+
+// ExtractTodos finds \todo{...} commands and "% TODO:" comments, in order of appearance
+// \todo in comments or verbatim environments does not count
+func ExtractTodos(content string) []Todo {
+	var todos []Todo
+
+	for _, inv := range latexscan.FindCommands(content, "todo") {
+		todos = append(todos, Todo{
+			Text:  strings.Join(strings.Fields(inv.Arg.Text), " "),
+			Line:  inv.Line,
+			Latex: true,
+			Start: inv.Start,
+			End:   inv.End,
+		})
+	}
+
+	for _, comment := range latexscan.Comments(content) {
+		if matches := commentTodoPattern.FindStringSubmatch(comment.Text); matches != nil {
+			todos = append(todos, Todo{
+				Text: strings.TrimSpace(matches[1]),
+				Line: comment.Line,
+			})
+		}
+	}
+
+	sort.SliceStable(todos, func(i, j int) bool {
+		return todos[i].Line < todos[j].Line
+	})
+	return todos
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestExtractTodos(t *testing.T) {
+	content := `\todo{fix \textbf{this}}
+% \todo{commented out}
+text % TODO: write the proof
+\begin{verbatim}
+\todo{in a listing}
+\end{verbatim}
+\todo[inline]{spans
+two lines}`
+
+	todos := ExtractTodos(content)
+	want := []Todo{
+		{Text: `fix \textbf{this}`, Line: 1, Latex: true},
+		{Text: "write the proof", Line: 3},
+		{Text: "spans two lines", Line: 7, Latex: true},
+	}
+
+	if len(todos) != len(want) {
+		t.Fatalf("ExtractTodos() returned %d todos, want %d: %+v", len(todos), len(want), todos)
+	}
+	for i, w := range want {
+		got := todos[i]
+		if got.Text != w.Text || got.Line != w.Line || got.Latex != w.Latex {
+			t.Errorf("todo %d = %+v, want %+v", i, got, w)
+		}
+	}
+
+	if first := todos[0]; content[first.Start:first.End] != `\todo{fix \textbf{this}}` {
+		t.Errorf("unexpected todo location %q", content[first.Start:first.End])
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TrashItem is a deleted note kept in the trash until it is restored or emptied
+type TrashItem struct {
+	ID        string    `json:"id"` // Folder of the item inside the trash
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
+	Filename  string    `json:"filename"` // Original filename relative to the notes directory
+	DeletedAt time.Time `json:"deleted_at"`
+
+	// Assets only this note used, trashed along with it
+	Assets []Asset `json:"assets,omitempty"`
+}
+
+// MatchesRef reports whether a trash item is named by ref: its ID, slug, or
+// the slug without its notebook
+func (t TrashItem) MatchesRef(ref string) bool {
+	return ref == t.ID || ref == t.Slug || ref == ParseFilename(path.Base(t.Filename))
+}
+
+// ParseAge parses an age such as "30d", "2w" or "12h"
+// Days and weeks are added to the units of time.ParseDuration
+func ParseAge(age string) (time.Duration, error) {
+	age = strings.TrimSpace(age)
+	units := map[string]time.Duration{
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(age, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age: %s", age)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age: %s (use e.g. 30d, 2w or 12h)", age)
+	}
+	return d, nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		age     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{age: "30d", want: 30 * 24 * time.Hour},
+		{age: "2w", want: 14 * 24 * time.Hour},
+		{age: "12h", want: 12 * time.Hour},
+		{age: "90m", want: 90 * time.Minute},
+		{age: "0d", want: 0},
+		{age: "-1d", wantErr: true},
+		{age: "d", wantErr: true},
+		{age: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseAge(tt.age)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseAge(%q) should fail", tt.age)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", tt.age, got, err, tt.want)
+		}
+	}
+}
+
+func TestTrashItem_MatchesRef(t *testing.T) {
+	item := TrashItem{ID: "20250101-120000-courses-limits", Slug: "courses/limits", Filename: "courses/20250101-limits.tex"}
+
+	for _, ref := range []string{"20250101-120000-courses-limits", "courses/limits", "limits"} {
+		if !item.MatchesRef(ref) {
+			t.Errorf("expected %q to match", ref)
+		}
+	}
+	if item.MatchesRef("other") {
+		t.Error("expected other slugs not to match")
+	}
+}
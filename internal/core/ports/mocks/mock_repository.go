@@ -3,10 +3,16 @@ package mocks
 import (
 	"context"
 	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
 )
 
 // MockRepository is a mock implementation of the Repository interface for testing
@@ -14,6 +20,13 @@ type MockRepository struct {
 	mu      sync.RWMutex
 	notes   map[string]*domain.NoteBody
 	headers map[string]*domain.NoteHeader
+	trash   map[string]mockTrashItem
+}
+
+// mockTrashItem is a trashed note with its content
+type mockTrashItem struct {
+	item domain.TrashItem
+	note *domain.NoteBody
 }
 
 // NewMockRepository creates a new mock repository
@@ -21,6 +34,7 @@ func NewMockRepository() *MockRepository {
 	return &MockRepository{
 		notes:   make(map[string]*domain.NoteBody),
 		headers: make(map[string]*domain.NoteHeader),
+		trash:   make(map[string]mockTrashItem),
 	}
 }
 
@@ -41,6 +55,8 @@ func (m *MockRepository) Save(ctx context.Context, note *domain.NoteBody) error
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// Saving touches the note, like writing the file would
+	note.Header.ModTime = time.Now()
 	m.notes[note.Header.Slug] = note
 	m.headers[note.Header.Slug] = &note.Header
 	return nil
@@ -127,6 +143,113 @@ func (m *MockRepository) Rename(ctx context.Context, oldSlug string, newTitle st
 	return nil
 }
 
+// Move puts a note in another notebook, keeping its filename and slug
+func (m *MockRepository) Move(ctx context.Context, slug string, notebook string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	note, ok := m.notes[slug]
+	if !ok {
+		return "", fmt.Errorf("note not found: %s", slug)
+	}
+	if err := domain.ValidateNotebook(notebook); err != nil {
+		return "", err
+	}
+	notebook = domain.NormalizeNotebook(notebook)
+	if note.Header.Notebook == notebook {
+		return "", fmt.Errorf("note is already in notebook '%s'", notebook)
+	}
+
+	moved := &domain.NoteBody{Header: note.Header, Content: note.Content}
+	moved.Header.Notebook = notebook
+	moved.Header.Filename = path.Join(notebook, path.Base(note.Header.Filename))
+
+	m.notes[slug] = moved
+	m.headers[slug] = &moved.Header
+	return moved.Header.Filename, nil
+}
+
+// Trash moves a note to the trash
+func (m *MockRepository) Trash(ctx context.Context, slug string, assets []domain.Asset) (*domain.TrashItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	note, ok := m.notes[slug]
+	if !ok {
+		return nil, fmt.Errorf("note not found: %s", slug)
+	}
+
+	item := domain.TrashItem{
+		ID:        fmt.Sprintf("%d-%s", len(m.trash)+1, slug),
+		Slug:      slug,
+		Title:     note.Header.Title,
+		Filename:  note.Header.Filename,
+		DeletedAt: time.Now(),
+		Assets:    assets,
+	}
+	m.trash[item.ID] = mockTrashItem{item: item, note: note}
+	delete(m.notes, slug)
+	delete(m.headers, slug)
+	return &item, nil
+}
+
+// ListTrash returns the trashed notes, most recently deleted first
+func (m *MockRepository) ListTrash(ctx context.Context) ([]domain.TrashItem, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := make([]domain.TrashItem, 0, len(m.trash))
+	for _, trashed := range m.trash {
+		items = append(items, trashed.item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	return items, nil
+}
+
+// Restore puts a trashed note back
+func (m *MockRepository) Restore(ctx context.Context, id string) (*domain.TrashItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	trashed, ok := m.trash[id]
+	if !ok {
+		return nil, fmt.Errorf("not in the trash: %s", id)
+	}
+	if _, exists := m.notes[trashed.item.Slug]; exists {
+		return nil, fmt.Errorf("note with slug '%s' already exists", trashed.item.Slug)
+	}
+
+	m.notes[trashed.item.Slug] = trashed.note
+	m.headers[trashed.item.Slug] = &trashed.note.Header
+	delete(m.trash, id)
+	return &trashed.item, nil
+}
+
+// Purge deletes a trashed note for good
+func (m *MockRepository) Purge(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.trash[id]; !ok {
+		return fmt.Errorf("not in the trash: %s", id)
+	}
+	delete(m.trash, id)
+	return nil
+}
+
+// SetDeletedAt changes when a trashed note was deleted
+func (m *MockRepository) SetDeletedAt(id string, deletedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if trashed, ok := m.trash[id]; ok {
+		trashed.item.DeletedAt = deletedAt
+		m.trash[id] = trashed
+	}
+}
+
 // --- MockTemplateRepository ---
 
 type MockTemplateRepository struct {
@@ -173,21 +296,35 @@ func (m *MockTemplateRepository) Create(ctx context.Context, t *domain.TemplateB
 type MockCompiler struct {
 	mu           sync.Mutex
 	calls        []string
+	options      []domain.CompileOptions
 	shouldFail   bool
 	failError    error
 	outputPrefix string
+	hanging      map[string]bool // Inputs that never finish until ctx is done
 }
 
 func NewMockCompiler() *MockCompiler {
 	return &MockCompiler{
 		outputPrefix: "/fake/cache/",
+		hanging:      make(map[string]bool),
 	}
 }
 
-func (m *MockCompiler) Compile(ctx context.Context, inputPath string, env []string) error {
+func (m *MockCompiler) Compile(ctx context.Context, inputPath string, opts domain.CompileOptions) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 	m.calls = append(m.calls, inputPath)
+	m.options = append(m.options, opts)
+	hang := m.hanging[filepath.Base(inputPath)]
+	m.mu.Unlock()
+
+	// Simulate a stuck engine (e.g. an infinite macro loop)
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.shouldFail {
 		if m.failError != nil {
 			return m.failError
@@ -197,10 +334,45 @@ func (m *MockCompiler) Compile(ctx context.Context, inputPath string, env []stri
 	return nil
 }
 
+func (m *MockCompiler) CompileWithOutput(ctx context.Context, inputPath string, opts domain.CompileOptions) *domain.CompileResult {
+	err := m.Compile(ctx, inputPath, opts)
+	result := &domain.CompileResult{
+		Engine:  "mock",
+		Success: err == nil,
+		Parsed:  &latexparser.ParseResult{HasPDF: err == nil},
+		PDFPath: strings.TrimSuffix(inputPath, ".tex") + ".pdf",
+	}
+	if err != nil {
+		result.Output = err.Error()
+		result.ErrorCount = 1
+		result.Parsed.Errors = []latexparser.Issue{
+			{Level: latexparser.LevelError, Message: err.Error()},
+		}
+	}
+	return result
+}
+
 func (m *MockCompiler) GetOutputPath(slug string) string {
 	return m.outputPrefix + slug + ".pdf"
 }
 
+func (m *MockCompiler) SetOutputPrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outputPrefix = prefix
+}
+
+func (m *MockCompiler) Clean(ctx context.Context, slug string) error {
+	return nil
+}
+
+// SetHanging makes compiling the given input file block until the context is done
+func (m *MockCompiler) SetHanging(filename string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hanging[filename] = true
+}
+
 func (m *MockCompiler) SetShouldFail(fail bool, err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -216,10 +388,19 @@ func (m *MockCompiler) GetCalls() []string {
 	return calls
 }
 
+func (m *MockCompiler) GetOptions() []domain.CompileOptions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	options := make([]domain.CompileOptions, len(m.options))
+	copy(options, m.options)
+	return options
+}
+
 func (m *MockCompiler) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.calls = nil
+	m.options = nil
 	m.shouldFail = false
 	m.failError = nil
 }
@@ -232,6 +413,7 @@ type MockPreprocessor struct {
 	shouldFail bool
 	failError  error
 	mockPath   string
+	slugDir    string // When set, each slug gets <slugDir>/<slug>.tex
 }
 
 func NewMockPreprocessor() *MockPreprocessor {
@@ -250,6 +432,9 @@ func (m *MockPreprocessor) Process(slug string) (string, error) {
 		}
 		return "", fmt.Errorf("preprocessing failed for %s", slug)
 	}
+	if m.slugDir != "" {
+		return filepath.Join(m.slugDir, slug+".tex"), nil
+	}
 	return m.mockPath, nil
 }
 
@@ -266,6 +451,13 @@ func (m *MockPreprocessor) SetMockPath(path string) {
 	m.mockPath = path
 }
 
+// SetSlugPaths makes Process return a distinct <dir>/<slug>.tex path per note
+func (m *MockPreprocessor) SetSlugPaths(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.slugDir = dir
+}
+
 func (m *MockPreprocessor) GetCalls() []string {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -351,3 +543,177 @@ func (m *MockAssetRepository) Delete(ctx context.Context, filename string) error
 	delete(m.assets, filename)
 	return nil
 }
+
+// MockBuildLogRepository keeps build records in memory
+type MockBuildLogRepository struct {
+	mu      sync.Mutex
+	records []domain.BuildRecord
+}
+
+func NewMockBuildLogRepository() *MockBuildLogRepository {
+	return &MockBuildLogRepository{}
+}
+
+func (m *MockBuildLogRepository) Save(ctx context.Context, record *domain.BuildRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, *record)
+	return nil
+}
+
+// List returns records newest first
+func (m *MockBuildLogRepository) List(ctx context.Context, slug string, limit int) ([]domain.BuildRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var results []domain.BuildRecord
+	for i := len(m.records) - 1; i >= 0; i-- {
+		if slug == "" || m.records[i].Slug == slug {
+			results = append(results, m.records[i])
+		}
+		if limit > 0 && len(results) == limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+func (m *MockBuildLogRepository) Get(ctx context.Context, slug string, id string) (*domain.BuildRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, record := range m.records {
+		if record.Slug == slug && record.ID == id {
+			return &record, nil
+		}
+	}
+	return nil, fmt.Errorf("build not found: %s", id)
+}
+
+// --- MockJournal ---
+
+// MockJournal keeps operations in memory; it records the captured paths, not their content
+type MockJournal struct {
+	mu       sync.Mutex
+	ops      []domain.Operation
+	current  *domain.Operation
+	modified map[string]bool
+	reverted []string
+}
+
+func NewMockJournal() *MockJournal {
+	return &MockJournal{modified: make(map[string]bool)}
+}
+
+func (m *MockJournal) Begin(command string, args []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.commit()
+	m.current = &domain.Operation{Command: command, Args: args, Time: time.Now()}
+}
+
+func (m *MockJournal) Capture(paths ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.current == nil {
+		return nil
+	}
+	for _, p := range paths {
+		m.current.Files = append(m.current.Files, domain.FileImage{Path: filepath.ToSlash(p), Existed: true})
+	}
+	return nil
+}
+
+func (m *MockJournal) Commit() (*domain.Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.commit(), nil
+}
+
+func (m *MockJournal) commit() *domain.Operation {
+	op := m.current
+	m.current = nil
+	if op == nil || len(op.Files) == 0 {
+		return nil
+	}
+	op.ID = fmt.Sprintf("op-%d", len(m.ops)+1)
+	m.ops = append(m.ops, *op)
+	return op
+}
+
+// List returns operations newest first
+func (m *MockJournal) List(ctx context.Context) ([]domain.Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ops []domain.Operation
+	for i := len(m.ops) - 1; i >= 0; i-- {
+		ops = append(ops, m.ops[i])
+	}
+	return ops, nil
+}
+
+func (m *MockJournal) Modified(ctx context.Context, id string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range m.ops {
+		if op.ID != id {
+			continue
+		}
+		var modified []string
+		for _, file := range op.Files {
+			if m.modified[file.Path] {
+				modified = append(modified, file.Path)
+			}
+		}
+		return modified, nil
+	}
+	return nil, fmt.Errorf("operation not found: %s", id)
+}
+
+func (m *MockJournal) Revert(ctx context.Context, id string) (*domain.Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.ops {
+		if m.ops[i].ID != id {
+			continue
+		}
+		if m.ops[i].Undone() {
+			return nil, fmt.Errorf("operation %s was already undone", id)
+		}
+		now := time.Now()
+		m.ops[i].UndoneAt = &now
+		m.reverted = append(m.reverted, id)
+		op := m.ops[i]
+		return &op, nil
+	}
+	return nil, fmt.Errorf("operation not found: %s", id)
+}
+
+func (m *MockJournal) Forget(ctx context.Context, until time.Time, paths ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	forget := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		forget[filepath.ToSlash(p)] = true
+	}
+	kept := m.ops[:0]
+	for _, op := range m.ops {
+		if op.Time.After(until) || !slices.ContainsFunc(op.Files, func(f domain.FileImage) bool { return forget[f.Path] }) {
+			kept = append(kept, op)
+		}
+	}
+	m.ops = kept
+	return nil
+}
+
+// SetModified marks a file as changed since the operations that captured it
+func (m *MockJournal) SetModified(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.modified[path] = true
+}
+
+// GetReverted returns the IDs of the reverted operations, in order
+func (m *MockJournal) GetReverted() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.reverted...)
+}
@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 )
@@ -21,9 +22,55 @@ type Repository interface {
 	Exists(ctx context.Context, slug string) bool
 
 	// Delete removes a note by slug
+	// File-backed repositories move it to the trash (see TrashRepository)
 	Delete(ctx context.Context, slug string) error
 }
 
+// TrashRepository defines the port for deleted notes kept so they can be restored
+type TrashRepository interface {
+	// Trash moves a note, and the assets orphaned with it, to the trash
+	Trash(ctx context.Context, slug string, assets []domain.Asset) (*domain.TrashItem, error)
+
+	// ListTrash returns the trashed notes, most recently deleted first
+	ListTrash(ctx context.Context) ([]domain.TrashItem, error)
+
+	// Restore moves a trashed note and its assets back where they were
+	Restore(ctx context.Context, id string) (*domain.TrashItem, error)
+
+	// Purge deletes a trashed note and its assets for good
+	Purge(ctx context.Context, id string) error
+}
+
+// Journal defines the port for recording operations, so they can be undone
+// Adapters capture a file right before changing it; only the first capture of
+// a file in an operation is kept, so it holds the state before the operation
+type Journal interface {
+	// Begin starts recording an operation, committing the one in progress
+	Begin(command string, args []string)
+
+	// Capture records the before-image of files about to change
+	// Outside an operation, or for files outside the vault, it does nothing
+	Capture(paths ...string) error
+
+	// Commit finishes the operation in progress
+	// It returns nil if the operation changed no files
+	Commit() (*domain.Operation, error)
+
+	// List returns the recorded operations, most recent first
+	List(ctx context.Context) ([]domain.Operation, error)
+
+	// Modified returns the files of an operation that changed since it ran
+	Modified(ctx context.Context, id string) ([]string, error)
+
+	// Revert writes the before-images of an operation back and marks it undone
+	Revert(ctx context.Context, id string) (*domain.Operation, error)
+
+	// Forget drops the operations started no later than until that touched any of
+	// the files, with their before-images. It is used for data deleted for good,
+	// which must not linger in the journal
+	Forget(ctx context.Context, until time.Time, paths ...string) error
+}
+
 // TemplateRepository defines the port for template operations
 type TemplateRepository interface {
 	// List returns all available templates
@@ -50,11 +97,30 @@ type Preprocessor interface {
 type Compiler interface {
 	// Compile compiles a specific source file to PDF
 	// inputPath: absolute path to the .tex file (usually in cache)
-	// env: additional environment variables (e.g., TEXINPUTS)
-	Compile(ctx context.Context, inputPath string, env []string) error
+	// opts: per-note engine, flags and environment overrides
+	Compile(ctx context.Context, inputPath string, opts domain.CompileOptions) error
+
+	// CompileWithOutput compiles like Compile but returns the raw log and parsed issues
+	CompileWithOutput(ctx context.Context, inputPath string, opts domain.CompileOptions) *domain.CompileResult
 
 	// GetOutputPath returns the path to the compiled PDF
 	GetOutputPath(slug string) string
+
+	// Clean removes auxiliary files produced for a note
+	Clean(ctx context.Context, slug string) error
+}
+
+// BuildLogRepository defines the port for persisting build history
+type BuildLogRepository interface {
+	// Save stores a build record together with its raw log
+	Save(ctx context.Context, record *domain.BuildRecord) error
+
+	// List returns the most recent builds, newest first
+	// slug: restrict to one note (empty for all notes); limit <= 0 means no limit
+	List(ctx context.Context, slug string, limit int) ([]domain.BuildRecord, error)
+
+	// Get retrieves a single build including its raw log
+	Get(ctx context.Context, slug string, id string) (*domain.BuildRecord, error)
 }
 
 // EditorLauncher defines the port for launching external editors
@@ -19,6 +19,7 @@ import (
 type AttachmentService struct {
 	vault     *vault.Vault
 	assetRepo ports.AssetRepository
+	journal   ports.Journal
 }
 
 func NewAttachmentService(v *vault.Vault, repo ports.AssetRepository) *AttachmentService {
@@ -28,6 +29,11 @@ func NewAttachmentService(v *vault.Vault, repo ports.AssetRepository) *Attachmen
 	}
 }
 
+// SetJournal records the assets the service stores, so operations can be undone
+func (s *AttachmentService) SetJournal(journal ports.Journal) {
+	s.journal = journal
+}
+
 // Store saves a file and its metadata
 // Returns: filename, isDuplicate, error
 func (s *AttachmentService) Store(ctx context.Context, srcPath string, name string, description string) (string, bool, error) {
@@ -79,6 +85,11 @@ func (s *AttachmentService) Store(ctx context.Context, srcPath string, name stri
 	}
 
 	// 5. Copy File
+	if s.journal != nil {
+		if err := s.journal.Capture(destPath); err != nil {
+			return "", false, err
+		}
+	}
 	dst, err := os.Create(destPath)
 	if err != nil {
 		return "", false, err
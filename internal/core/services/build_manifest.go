@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// graphicsExtensions are tried in order when \includegraphics omits the extension
+var graphicsExtensions = []string{".pdf", ".png", ".jpg", ".jpeg", ".eps"}
+
+// buildPlan describes which notes build-all has to compile
+type buildPlan struct {
+	stale   []domain.NoteHeader
+	skipped []domain.NoteHeader
+	entries map[string]domain.ManifestEntry // Fresh hashes for every note
+
+	// Notes that link to each note; they are stale whenever it is
+	dependents map[string][]string
+}
+
+// planBuild hashes every note and selects the stale ones plus their dependents
+// Without a vault there is nowhere to keep a manifest, so everything is stale
+func (s *BuildService) planBuild(ctx context.Context, headers []domain.NoteHeader, manifest *domain.BuildManifest, force bool) *buildPlan {
+	plan := &buildPlan{
+		entries: make(map[string]domain.ManifestEntry),
+	}
+
+	if s.vault == nil || manifest == nil {
+		plan.stale = headers
+		return plan
+	}
+
+	// 1. Hash every note against the current titles of the notes it links to
+	titles := make(map[string]string, len(headers))
+	for _, h := range headers {
+		titles[h.Slug] = h.Title
+	}
+
+	for _, h := range headers {
+		note, err := s.noteRepo.Get(ctx, h.Slug)
+		if err != nil {
+			// Unreadable notes get an empty hash so they are always retried
+			plan.entries[h.Slug] = domain.ManifestEntry{}
+			continue
+		}
+		plan.entries[h.Slug] = s.hashNote(note.Content, titles)
+	}
+
+	// 2. Mark notes whose hash changed or whose PDF is gone
+	stale := make(map[string]bool)
+	for _, h := range headers {
+		entry := plan.entries[h.Slug]
+		previous, exists := manifest.GetEntry(h.Slug)
+
+		if force || !exists || entry.Hash == "" || previous.Hash != entry.Hash {
+			stale[h.Slug] = true
+			continue
+		}
+
+		if _, err := os.Stat(s.compiler.GetOutputPath(h.Slug)); err != nil {
+			stale[h.Slug] = true
+		}
+	}
+
+	// 3. Propagate staleness to every note that (transitively) depends on a stale one
+	dependents := make(map[string][]string)
+	for slug, entry := range plan.entries {
+		for _, dep := range entry.Dependencies {
+			dependents[dep] = append(dependents[dep], slug)
+		}
+	}
+	plan.dependents = dependents
+
+	queue := make([]string, 0, len(stale))
+	for slug := range stale {
+		queue = append(queue, slug)
+	}
+	for len(queue) > 0 {
+		slug := queue[0]
+		queue = queue[1:]
+		for _, dependent := range dependents[slug] {
+			if !stale[dependent] {
+				stale[dependent] = true
+				queue = append(queue, dependent)
+			}
+		}
+	}
+
+	// 4. Split headers, keeping the repository order
+	for _, h := range headers {
+		if stale[h.Slug] {
+			plan.stale = append(plan.stale, h)
+			// The preprocessor cache only knows about mtimes, so drop it
+			os.Remove(s.vault.GetCachePath(h.Slug + ".tex"))
+		} else {
+			plan.skipped = append(plan.skipped, h)
+		}
+	}
+
+	return plan
+}
+
+// hashNote computes the content hash and note dependencies for one note
+func (s *BuildService) hashNote(content string, titles map[string]string) domain.ManifestEntry {
+	h := sha256.New()
+	deps := make(map[string]bool)
+
+	fmt.Fprintf(h, "source\x00%s\x00", content)
+
+	// Templates pulled in with \usepackage
+	for _, inv := range latexscan.FindCommands(content, "usepackage") {
+		for _, name := range strings.Split(inv.Arg.Text, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			path := s.vault.GetTemplatePath(name + ".sty")
+			fmt.Fprintf(h, "package\x00%s\x00%s\x00", name, hashFile(path))
+		}
+	}
+
+	// Files pulled in with \input / \include
+	for _, inv := range latexscan.FindCommands(content, "input", "include") {
+		path := s.resolveInputPath(strings.TrimSpace(inv.Arg.Text))
+		fmt.Fprintf(h, "input\x00%s\x00%s\x00", inv.Arg.Text, hashFile(path))
+
+		// Inputting another note, in any notebook, makes it a dependency
+		if rel, err := filepath.Rel(s.vault.NotesPath, path); err == nil && !strings.HasPrefix(rel, "..") {
+			slug := domain.ParseFilename(filepath.Base(path))
+			qualified := domain.QualifiedSlug(domain.NotebookOf(filepath.ToSlash(rel)), slug)
+			for _, candidate := range []string{qualified, slug} {
+				if _, isNote := titles[candidate]; isNote {
+					deps[candidate] = true
+					break
+				}
+			}
+		}
+	}
+
+	// Assets pulled in with \includegraphics
+	for _, inv := range latexscan.FindCommands(content, "includegraphics") {
+		path := s.resolveGraphicsPath(strings.TrimSpace(inv.Arg.Text))
+		fmt.Fprintf(h, "asset\x00%s\x00%s\x00", inv.Arg.Text, hashFile(path))
+	}
+
+	// Links to other notes
+	for _, link := range domain.ExtractLinks(content) {
+		target, local := link.Target.Local(s.vault.Name)
+		if !local {
+			// Notes in other vaults are not tracked; rebuild with --force after changing them
+			fmt.Fprintf(h, "vault-link\x00%s\x00", target)
+			continue
+		}
+		title, exists := titles[target.Slug]
+
+		switch link.Kind {
+		case domain.LinkNote:
+			// Titles of linked notes end up in the rendered link text
+			// Anchored links depend on the target too, since its labels decide whether they resolve
+			fmt.Fprintf(h, "link\x00%s\x00%s\x00", target, title)
+		case domain.LinkEmbed:
+			// Embedded notes are part of the output, so any change to them counts
+			fmt.Fprintf(h, "embed\x00%s\x00", target)
+		case domain.LinkRef:
+			// Legacy \ref{slug} only counts when it names a note
+			if !exists {
+				continue
+			}
+			fmt.Fprintf(h, "link\x00%s\x00%s\x00", target.Slug, title)
+		default:
+			// \input and \include were hashed as files above
+			continue
+		}
+
+		if exists {
+			deps[target.Slug] = true
+		}
+	}
+
+	dependencies := make([]string, 0, len(deps))
+	for slug := range deps {
+		dependencies = append(dependencies, slug)
+	}
+	sort.Strings(dependencies)
+
+	return domain.ManifestEntry{
+		Hash:         hex.EncodeToString(h.Sum(nil)),
+		Dependencies: dependencies,
+	}
+}
+
+// resolveInputPath mirrors how the preprocessor and TeX locate \input files
+func (s *BuildService) resolveInputPath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.vault.NotesPath, path)
+	}
+	if filepath.Ext(path) == "" {
+		path += ".tex"
+	}
+	return path
+}
+
+// resolveGraphicsPath finds the file an \includegraphics argument refers to
+func (s *BuildService) resolveGraphicsPath(path string) string {
+	var candidates []string
+	switch {
+	case filepath.IsAbs(path):
+		candidates = []string{path}
+	case strings.HasPrefix(path, "."):
+		candidates = []string{filepath.Join(s.vault.NotesPath, path)}
+	default:
+		candidates = []string{
+			s.vault.GetAssetPath(path),
+			filepath.Join(s.vault.NotesPath, path),
+		}
+	}
+
+	for _, candidate := range candidates {
+		if filepath.Ext(candidate) != "" {
+			if _, err := os.Stat(candidate); err == nil {
+				return candidate
+			}
+			continue
+		}
+		for _, ext := range graphicsExtensions {
+			if _, err := os.Stat(candidate + ext); err == nil {
+				return candidate + ext
+			}
+		}
+	}
+
+	return candidates[0]
+}
+
+// hashFile returns the SHA-256 of a file, or a marker if it cannot be read
+func hashFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "missing"
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// recordBuilds updates the manifest with the outcome of a build-all run
+// Successful builds store their new hash; failures are dropped so they retry.
+// A note whose dependents did not build in this run (cancelled before they
+// started, or left out by --query) is dropped too, so the next run rebuilds
+// it and the dependents with it
+func recordBuilds(manifest *domain.BuildManifest, plan *buildPlan, results []BuildResponse) {
+	finished := make(map[string]bool, len(results))
+	for _, result := range results {
+		finished[result.Slug] = true
+	}
+
+	now := time.Now()
+	for _, result := range results {
+		if !result.Success {
+			manifest.RemoveEntry(result.Slug)
+			continue
+		}
+		entry := plan.entries[result.Slug]
+		if entry.Hash == "" || !allFinished(plan.dependents[result.Slug], finished) {
+			manifest.RemoveEntry(result.Slug)
+			continue
+		}
+		entry.BuiltAt = now
+		manifest.SetEntry(result.Slug, entry)
+	}
+
+	// Forget notes that no longer exist
+	for slug := range manifest.Notes {
+		if _, exists := plan.entries[slug]; !exists {
+			manifest.RemoveEntry(slug)
+		}
+	}
+}
+
+// allFinished reports whether every one of the notes has a result
+func allFinished(slugs []string, finished map[string]bool) bool {
+	for _, slug := range slugs {
+		if !finished[slug] {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadBuildManifest reads the build manifest, returning an empty one if missing
+func LoadBuildManifest(path string) (*domain.BuildManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.NewBuildManifest(), nil
+		}
+		return nil, fmt.Errorf("failed to read build manifest: %w", err)
+	}
+
+	var manifest domain.BuildManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal build manifest: %w", err)
+	}
+
+	if manifest.Notes == nil {
+		manifest.Notes = make(map[string]domain.ManifestEntry)
+	}
+
+	return &manifest, nil
+}
+
+// SaveBuildManifest writes the build manifest to disk
+func SaveBuildManifest(path string, manifest *domain.BuildManifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal build manifest: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
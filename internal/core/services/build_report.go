@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+)
+
+// Report formats supported by WriteReport
+const (
+	ReportJSON  = "json"
+	ReportJUnit = "junit"
+)
+
+// ReportFormats lists the supported report formats
+var ReportFormats = []string{ReportJSON, ReportJUnit}
+
+// BuildReport is a machine-readable summary of one or more builds
+type BuildReport struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Total       int                `json:"total"`
+	Succeeded   int                `json:"succeeded"`
+	Failed      int                `json:"failed"`
+	TimedOut    int                `json:"timed_out"`
+	Skipped     int                `json:"skipped"`
+	Duration    float64            `json:"duration_seconds"`
+	Notes       []BuildReportEntry `json:"notes"`
+}
+
+// BuildReportEntry is the outcome of building a single note
+type BuildReportEntry struct {
+	Slug     string              `json:"slug"`
+	File     string              `json:"file"`
+	Success  bool                `json:"success"`
+	TimedOut bool                `json:"timed_out,omitempty"`
+	Duration float64             `json:"duration_seconds"`
+	Error    string              `json:"error,omitempty"`
+	Errors   []latexparser.Issue `json:"errors"`
+	Warnings []latexparser.Issue `json:"warnings"`
+}
+
+// Report converts build results into a report
+// Issues without a location are attributed to the note's file
+func (s *BuildService) Report(ctx context.Context, results []BuildResponse, skipped int) (*BuildReport, error) {
+	headers, err := s.noteRepo.ListHeaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	files := make(map[string]string, len(headers))
+	for _, header := range headers {
+		files[header.Slug] = path.Join("notes", header.Filename)
+	}
+
+	report := &BuildReport{
+		GeneratedAt: time.Now(),
+		Total:       len(results) + skipped,
+		Skipped:     skipped,
+		Notes:       make([]BuildReportEntry, 0, len(results)),
+	}
+
+	for _, result := range results {
+		entry := BuildReportEntry{
+			Slug:     result.Slug,
+			File:     files[result.Slug],
+			Success:  result.Success,
+			TimedOut: result.TimedOut,
+			Duration: result.Duration.Seconds(),
+			Errors:   []latexparser.Issue{},
+			Warnings: []latexparser.Issue{},
+		}
+
+		if result.Error != nil {
+			entry.Error = result.Error.Error()
+		}
+
+		if result.Parsed != nil {
+			entry.Errors = attributeIssues(result.Parsed.Errors, entry.File)
+			entry.Warnings = attributeIssues(result.Parsed.Warnings, entry.File)
+		} else if result.Error != nil {
+			entry.Errors = append(entry.Errors, latexparser.Issue{
+				Level:   latexparser.LevelError,
+				File:    entry.File,
+				Message: result.Error.Error(),
+			})
+		}
+
+		switch {
+		case result.Success:
+			report.Succeeded++
+		case result.TimedOut:
+			report.TimedOut++
+		default:
+			report.Failed++
+		}
+		report.Duration += entry.Duration
+		report.Notes = append(report.Notes, entry)
+	}
+
+	return report, nil
+}
+
+// attributeIssues copies issues, filling in the note file where the compiler gave none
+func attributeIssues(issues []latexparser.Issue, file string) []latexparser.Issue {
+	attributed := make([]latexparser.Issue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.File == "" {
+			issue.File = file
+		}
+		attributed = append(attributed, issue)
+	}
+	return attributed
+}
+
+// WriteReport writes the report in the given format
+func WriteReport(w io.Writer, report *BuildReport, format string) error {
+	switch format {
+	case ReportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case ReportJUnit:
+		return writeJUnit(w, report)
+	default:
+		return fmt.Errorf("unsupported report format: %s (supported: %s)", format, strings.Join(ReportFormats, ", "))
+	}
+}
+
+// JUnit XML structure, as understood by common test-report viewers
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// writeJUnit renders the report as a single JUnit test suite with one case per note
+func writeJUnit(w io.Writer, report *BuildReport) error {
+	suite := junitTestSuite{
+		Name:      "lx build",
+		Tests:     len(report.Notes),
+		Failures:  report.Failed + report.TimedOut,
+		Skipped:   report.Skipped,
+		Time:      formatSeconds(report.Duration),
+		Timestamp: report.GeneratedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, entry := range report.Notes {
+		testCase := junitTestCase{
+			Name:      entry.Slug,
+			Classname: "notes",
+			File:      entry.File,
+			Time:      formatSeconds(entry.Duration),
+			SystemOut: formatIssueLines(entry.Warnings),
+		}
+
+		if !entry.Success {
+			message := entry.Error
+			if message == "" {
+				message = "compilation failed"
+			}
+			testCase.Failure = &junitFailure{
+				Message: message,
+				Type:    "LaTeXError",
+				Body:    formatIssueLines(entry.Errors),
+			}
+			if entry.TimedOut {
+				testCase.Failure.Type = "Timeout"
+			}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to encode junit report: %w", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// formatIssueLines renders issues as "file:line: message" lines
+func formatIssueLines(issues []latexparser.Issue) string {
+	var sb strings.Builder
+	for _, issue := range issues {
+		if location := issue.Location(); location != "" {
+			sb.WriteString(location + ": ")
+		}
+		sb.WriteString(issue.Message + "\n")
+	}
+	return sb.String()
+}
+
+func formatSeconds(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
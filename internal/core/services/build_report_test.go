@@ -0,0 +1,107 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+func setupReport(t *testing.T) (*BuildReport, *domain.NoteHeader, *domain.NoteHeader) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mocks.NewMockPreprocessor(), nil)
+
+	good, _ := domain.NewNoteHeader("Good Note", []string{}, "")
+	bad, _ := domain.NewNoteHeader("Bad Note", []string{}, "")
+	mockRepo.Save(context.Background(), domain.NewNoteBody(good, "ok"))
+	mockRepo.Save(context.Background(), domain.NewNoteBody(bad, "\\undefined"))
+
+	goodResp, _ := svc.Execute(context.Background(), BuildRequest{Slug: good.Slug})
+	mockCompiler.SetShouldFail(true, fmt.Errorf("Undefined control sequence"))
+	badResp, _ := svc.Execute(context.Background(), BuildRequest{Slug: bad.Slug})
+
+	report, err := svc.Report(context.Background(), []BuildResponse{*goodResp, *badResp}, 3)
+	if err != nil {
+		t.Fatalf("Report failed: %v", err)
+	}
+	return report, good, bad
+}
+
+func TestBuildService_Report(t *testing.T) {
+	report, _, bad := setupReport(t)
+
+	if report.Total != 5 || report.Succeeded != 1 || report.Failed != 1 || report.Skipped != 3 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+
+	entry := report.Notes[1]
+	if entry.Slug != bad.Slug || entry.Success {
+		t.Fatalf("expected failed entry for %s, got %+v", bad.Slug, entry)
+	}
+
+	// Issues without a location are attributed to the note
+	if entry.File != "notes/"+bad.Filename {
+		t.Errorf("expected file notes/%s, got %q", bad.Filename, entry.File)
+	}
+	if len(entry.Errors) != 1 || entry.Errors[0].File != entry.File {
+		t.Errorf("expected 1 error attributed to the note, got %v", entry.Errors)
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	report, good, _ := setupReport(t)
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, report, ReportJSON); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	var decoded BuildReport
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Notes) != 2 || decoded.Notes[0].Slug != good.Slug || !decoded.Notes[0].Success {
+		t.Errorf("unexpected decoded notes: %+v", decoded.Notes)
+	}
+	if !strings.Contains(buf.String(), `"level": "error"`) {
+		t.Error("expected issue levels to be written by name")
+	}
+}
+
+func TestWriteReport_JUnit(t *testing.T) {
+	report, _, bad := setupReport(t)
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, report, ReportJUnit); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+
+	var suites junitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if suites.Tests != 2 || suites.Failures != 1 {
+		t.Errorf("expected 2 tests and 1 failure, got %d and %d", suites.Tests, suites.Failures)
+	}
+
+	failed := suites.Suites[0].Cases[1]
+	if failed.Name != bad.Slug || failed.Failure == nil {
+		t.Fatalf("expected failure for %s, got %+v", bad.Slug, failed)
+	}
+	if !strings.Contains(failed.Failure.Body, "notes/"+bad.Filename+": Undefined control sequence") {
+		t.Errorf("expected located error in failure body, got %q", failed.Failure.Body)
+	}
+}
+
+func TestWriteReport_UnsupportedFormat(t *testing.T) {
+	if err := WriteReport(&bytes.Buffer{}, &BuildReport{}, "html"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
@@ -2,13 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/kamal-hamza/lx-cli/internal/adapters/compiler"
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/metadata"
+	"github.com/kamal-hamza/lx-cli/pkg/synctex"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -18,8 +24,13 @@ type BuildService struct {
 	compiler     ports.Compiler
 	preprocessor ports.Preprocessor // Interface type to allow mocking
 	vault        *vault.Vault
+	buildLog     ports.BuildLogRepository // Optional build history
+	timeout      time.Duration            // Per-note build timeout (0 = none)
 }
 
+// ErrBuildTimeout is returned when a note takes longer than its build timeout
+var ErrBuildTimeout = errors.New("build timed out")
+
 // NewBuildService creates a new build service (Normal usage)
 func NewBuildService(noteRepo ports.Repository, compiler ports.Compiler, v *vault.Vault) *BuildService {
 	return &BuildService{
@@ -42,9 +53,20 @@ func NewBuildServiceWithPreprocessor(noteRepo ports.Repository, compiler ports.C
 	}
 }
 
+// SetBuildLog enables persistent build history
+func (s *BuildService) SetBuildLog(buildLog ports.BuildLogRepository) {
+	s.buildLog = buildLog
+}
+
+// SetTimeout sets the default per-note build timeout (0 disables it)
+func (s *BuildService) SetTimeout(timeout time.Duration) {
+	s.timeout = timeout
+}
+
 // BuildRequest represents a request to build a note
 type BuildRequest struct {
-	Slug string
+	Slug    string
+	Timeout time.Duration // Overrides the service timeout when > 0
 }
 
 // BuildResponse represents the response from building a note
@@ -52,19 +74,27 @@ type BuildResponse struct {
 	Slug       string
 	OutputPath string
 	Success    bool
+	TimedOut   bool
 	Error      error
+	Duration   time.Duration
+	Parsed     *latexparser.ParseResult // Nil if the note never reached the compiler
 }
 
 // BuildAllRequest represents a request to build all notes
 type BuildAllRequest struct {
-	MaxWorkers int // Number of concurrent workers
+	MaxWorkers int           // Number of concurrent workers
+	Force      bool          // Rebuild every note, ignoring the build manifest
+	Timeout    time.Duration // Per-note timeout; overrides the service timeout when > 0
+	Slugs      []string      // Only build these notes; nil builds every note
 }
 
 // BuildAllResponse represents the response from building all notes
 type BuildAllResponse struct {
 	Total     int
 	Succeeded int
-	Failed    int
+	Failed    int // Failed builds, excluding timeouts
+	TimedOut  int // Builds killed after exceeding the timeout
+	Skipped   int // Notes that were already up to date
 	Results   []BuildResponse
 }
 
@@ -72,16 +102,34 @@ type BuildAllResponse struct {
 type BuildResultDetails struct {
 	Slug       string
 	Success    bool
+	TimedOut   bool
 	Parsed     *latexparser.ParseResult
 	Output     string
 	OutputPath string
 	Error      error
+	Duration   time.Duration
+}
+
+// Response summarizes the detailed result as a BuildResponse
+func (d *BuildResultDetails) Response() BuildResponse {
+	response := BuildResponse{
+		Slug:     d.Slug,
+		Success:  d.Success,
+		TimedOut: d.TimedOut,
+		Error:    d.Error,
+		Duration: d.Duration,
+		Parsed:   d.Parsed,
+	}
+	if d.Success {
+		response.OutputPath = d.OutputPath
+	}
+	return response
 }
 
 // Execute builds a single note and returns basic response
 func (s *BuildService) Execute(ctx context.Context, req BuildRequest) (*BuildResponse, error) {
 	details, err := s.ExecuteWithDetails(ctx, req)
-	if err != nil {
+	if details == nil {
 		return &BuildResponse{
 			Slug:    req.Slug,
 			Success: false,
@@ -89,12 +137,8 @@ func (s *BuildService) Execute(ctx context.Context, req BuildRequest) (*BuildRes
 		}, err
 	}
 
-	return &BuildResponse{
-		Slug:       details.Slug,
-		OutputPath: details.OutputPath,
-		Success:    details.Success,
-		Error:      details.Error,
-	}, nil
+	response := details.Response()
+	return &response, err
 }
 
 // ExecuteWithDetails builds a single note and returns detailed compilation results
@@ -104,65 +148,189 @@ func (s *BuildService) ExecuteWithDetails(ctx context.Context, req BuildRequest)
 		return nil, fmt.Errorf("note not found: %s", req.Slug)
 	}
 
+	details := s.buildNote(ctx, req.Slug, s.effectiveTimeout(req.Timeout))
+	return details, details.Error
+}
+
+// effectiveTimeout returns the request timeout, falling back to the service default
+func (s *BuildService) effectiveTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	return s.timeout
+}
+
+// buildNote preprocesses and compiles one note, recording the build in the history
+// The compiler is killed if the build exceeds timeout or ctx is cancelled
+func (s *BuildService) buildNote(ctx context.Context, slug string, timeout time.Duration) *BuildResultDetails {
+	started := time.Now()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// 1. Preprocess the note
 	// This resolves links/paths and writes a compilable .tex file to the cache directory
-	preprocessedPath, err := s.preprocessor.Process(req.Slug)
+	preprocessedPath, err := s.preprocessor.Process(slug)
 	if err != nil {
-		return &BuildResultDetails{
-			Slug:    req.Slug,
-			Success: false,
-			Error:   fmt.Errorf("preprocessing failed: %w", err),
-		}, fmt.Errorf("preprocessing failed: %w", err)
-	}
-
-	// 2. Compile the preprocessed file with detailed output
-	// Use type assertion to access LatexmkCompiler's detailed results
-	var compileResult *compiler.CompileResult
-	if latexmkCompiler, ok := s.compiler.(*compiler.LatexmkCompiler); ok {
-		compileResult = latexmkCompiler.CompileWithOutput(ctx, preprocessedPath, []string{})
-	} else {
-		// Fallback: use standard Compile method
-		err := s.compiler.Compile(ctx, preprocessedPath, []string{})
-		outputPath := s.compiler.GetOutputPath(req.Slug)
-
-		compileResult = &compiler.CompileResult{
-			Success:    err == nil,
-			PDFPath:    outputPath,
-			Parsed:     &latexparser.ParseResult{HasPDF: err == nil},
-			ErrorCount: 0,
+		details := &BuildResultDetails{
+			Slug:     slug,
+			Success:  false,
+			Error:    fmt.Errorf("preprocessing failed: %w", err),
+			Duration: time.Since(started),
 		}
-		if err != nil {
-			compileResult.ErrorCount = 1
-			compileResult.Parsed.Errors = []latexparser.Issue{
-				{Level: latexparser.LevelError, Message: err.Error()},
-			}
+		s.recordBuild(ctx, details, "", started)
+		return details
+	}
+
+	// 2. Compile the preprocessed file with detailed output,
+	// honoring any build options declared in the note's header
+	opts, optionWarnings := s.compileOptions(ctx, slug)
+	compileResult := s.compiler.CompileWithOutput(ctx, preprocessedPath, opts)
+
+	// Point issues and SyncTeX data at the original note instead of the cache copy
+	s.mapToSource(preprocessedPath, compileResult.Parsed)
+
+	// Options the header declared but that were ignored are reported with the build
+	if len(optionWarnings) > 0 {
+		if compileResult.Parsed == nil {
+			compileResult.Parsed = &latexparser.ParseResult{}
 		}
+		compileResult.Parsed.Warnings = append(optionWarnings, compileResult.Parsed.Warnings...)
 	}
 
 	// 3. Build detailed result
 	details := &BuildResultDetails{
-		Slug:       req.Slug,
+		Slug:       slug,
 		Success:    compileResult.Success,
 		Parsed:     compileResult.Parsed,
 		Output:     compileResult.Output,
 		OutputPath: compileResult.PDFPath,
 		Error:      nil,
+		Duration:   time.Since(started),
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		details.Success = false
+		details.TimedOut = true
+		details.Error = fmt.Errorf("%w after %s", ErrBuildTimeout, timeout)
+	case ctx.Err() != nil:
+		details.Success = false
+		details.Error = fmt.Errorf("build cancelled: %w", ctx.Err())
+	case !compileResult.Success:
+		details.Error = compileError(compileResult.Parsed)
 	}
 
-	if !compileResult.Success {
-		details.Error = fmt.Errorf("compilation failed")
-		return details, details.Error
+	s.recordBuild(ctx, details, compileResult.Engine, started)
+	return details
+}
+
+// compileError describes a failed compilation by its first error
+func compileError(parsed *latexparser.ParseResult) error {
+	if parsed != nil && len(parsed.Errors) > 0 {
+		return fmt.Errorf("compilation failed: %s", parsed.Errors[0].Message)
+	}
+	return fmt.Errorf("compilation failed: no PDF generated")
+}
+
+// recordBuild stores a finished build in the history, if one is configured
+// History is best effort and never fails the build itself
+func (s *BuildService) recordBuild(ctx context.Context, details *BuildResultDetails, engine string, started time.Time) {
+	if s.buildLog == nil {
+		return
+	}
+
+	record := domain.NewBuildRecord(details.Slug, started)
+	record.Engine = engine
+	record.Duration = details.Duration
+	record.Success = details.Success
+	record.TimedOut = details.TimedOut
+	record.Output = details.Output
+
+	if details.Parsed != nil {
+		record.Errors = append(record.Errors, details.Parsed.Errors...)
+		record.Warnings = append(record.Warnings, details.Parsed.Warnings...)
+	} else if details.Error != nil {
+		record.Errors = append(record.Errors, latexparser.Issue{
+			Level:   latexparser.LevelError,
+			Message: details.Error.Error(),
+		})
+		record.Output = details.Error.Error()
 	}
 
-	return details, nil
+	_ = s.buildLog.Save(ctx, record)
 }
 
-// ExecuteAll builds all notes concurrently
+// mapToSource translates compiler output for a preprocessed file back to the note
+// Issues are rewritten through the preprocessor's line map, and so is the SyncTeX
+// data so that viewers jump to the note rather than the cache copy
+func (s *BuildService) mapToSource(preprocessedPath string, parsed *latexparser.ParseResult) {
+	lineMap, err := latexparser.LoadLineMap(latexparser.LineMapPath(preprocessedPath))
+	if err != nil {
+		return
+	}
+
+	if parsed != nil {
+		parsed.ApplyLineMap(lineMap)
+	}
+
+	if s.vault == nil {
+		return
+	}
+
+	synctexPath := synctex.PathForPDF(strings.TrimSuffix(preprocessedPath, ".tex") + ".pdf")
+	if _, err := os.Stat(synctexPath); err == nil {
+		// Best effort: a stale or foreign file just keeps pointing at the cache
+		_ = synctex.Rewrite(synctexPath, lineMap, s.vault.RootPath)
+	}
+}
+
+// compileOptions reads the per-note build options from the note's metadata header
+// Notes without options (or that cannot be read) use the compiler's defaults.
+// Options that were ignored, such as an unsupported engine, come back as warnings
+func (s *BuildService) compileOptions(ctx context.Context, slug string) (domain.CompileOptions, []latexparser.Issue) {
+	note, err := s.noteRepo.Get(ctx, slug)
+	if err != nil {
+		return domain.CompileOptions{}, nil
+	}
+
+	// Parse leniently: a malformed title must not block the build here
+	result, _ := metadata.NewParser(false).Parse(note.Content)
+	if result == nil || result.Metadata == nil {
+		return domain.CompileOptions{}, nil
+	}
+
+	file := note.Header.Filename
+	if s.vault != nil {
+		file = filepath.ToSlash(filepath.Join(filepath.Base(s.vault.NotesPath), file))
+	}
+	var warnings []latexparser.Issue
+	for _, warning := range result.Warnings {
+		warnings = append(warnings, latexparser.Issue{
+			Level:   latexparser.LevelWarning,
+			Kind:    latexparser.KindBuildOption,
+			File:    file,
+			Message: warning,
+		})
+	}
+
+	meta := result.Metadata
+	return domain.CompileOptions{
+		Engine:       meta.Engine,
+		ShellEscape:  meta.ShellEscape,
+		LatexmkFlags: meta.LatexmkFlags,
+		Bib:          meta.Bib,
+	}, warnings
+}
+
+// ExecuteAll builds all stale notes concurrently
 func (s *BuildService) ExecuteAll(ctx context.Context, req BuildAllRequest) (*BuildAllResponse, error) {
-	// Get all notes
-	headers, err := s.noteRepo.ListHeaders(ctx)
+	headers, plan, manifest, err := s.prepareBuildAll(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list notes: %w", err)
+		return nil, err
 	}
 
 	if len(headers) == 0 {
@@ -174,34 +342,96 @@ func (s *BuildService) ExecuteAll(ctx context.Context, req BuildAllRequest) (*Bu
 		}, nil
 	}
 
-	// Default to 4 workers if not specified
-	maxWorkers := req.MaxWorkers
-	if maxWorkers <= 0 {
-		maxWorkers = 4
+	// Create worker pool
+	results := s.buildConcurrently(ctx, plan.stale, maxWorkers(req), s.effectiveTimeout(req.Timeout))
+
+	return s.finishBuildAll(headers, plan, manifest, results), nil
+}
+
+// prepareBuildAll lists all notes and works out which of them need compiling
+func (s *BuildService) prepareBuildAll(ctx context.Context, req BuildAllRequest) ([]domain.NoteHeader, *buildPlan, *domain.BuildManifest, error) {
+	// Get all notes
+	headers, err := s.noteRepo.ListHeaders(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to list notes: %w", err)
 	}
 
-	// Create worker pool
-	results := s.buildConcurrently(ctx, headers, maxWorkers)
+	// A missing or corrupt manifest simply means everything is rebuilt
+	var manifest *domain.BuildManifest
+	if s.vault != nil {
+		manifest, err = LoadBuildManifest(s.vault.BuildManifestPath())
+		if err != nil {
+			manifest = domain.NewBuildManifest()
+		}
+	}
+
+	plan := s.planBuild(ctx, headers, manifest, req.Force)
+
+	// The plan covers every note so dependencies and the manifest stay complete;
+	// only the selected notes are built
+	if req.Slugs != nil {
+		selected := make(map[string]bool, len(req.Slugs))
+		for _, slug := range req.Slugs {
+			selected[slug] = true
+		}
+		headers = selectHeaders(headers, selected)
+		plan.stale = selectHeaders(plan.stale, selected)
+		plan.skipped = selectHeaders(plan.skipped, selected)
+	}
 
-	// Aggregate results
+	return headers, plan, manifest, nil
+}
+
+// selectHeaders keeps the headers of the selected notes
+func selectHeaders(headers []domain.NoteHeader, selected map[string]bool) []domain.NoteHeader {
+	var kept []domain.NoteHeader
+	for _, header := range headers {
+		if selected[header.Slug] {
+			kept = append(kept, header)
+		}
+	}
+	return kept
+}
+
+// finishBuildAll aggregates results and records successful builds in the manifest
+func (s *BuildService) finishBuildAll(headers []domain.NoteHeader, plan *buildPlan, manifest *domain.BuildManifest, results []BuildResponse) *BuildAllResponse {
 	response := &BuildAllResponse{
 		Total:   len(headers),
+		Skipped: len(plan.skipped),
 		Results: results,
 	}
 
 	for _, result := range results {
-		if result.Success {
+		switch {
+		case result.Success:
 			response.Succeeded++
-		} else {
+		case result.TimedOut:
+			response.TimedOut++
+		default:
 			response.Failed++
 		}
 	}
 
-	return response, nil
+	// The manifest is only an optimization: if it cannot be saved,
+	// the next run just rebuilds more than it strictly needs to
+	if manifest != nil {
+		recordBuilds(manifest, plan, results)
+		_ = SaveBuildManifest(s.vault.BuildManifestPath(), manifest)
+	}
+
+	return response
+}
+
+// maxWorkers returns the worker count for a request, defaulting to 4
+func maxWorkers(req BuildAllRequest) int {
+	if req.MaxWorkers <= 0 {
+		return 4
+	}
+	return req.MaxWorkers
 }
 
 // buildConcurrently builds notes using a worker pool
-func (s *BuildService) buildConcurrently(ctx context.Context, headers []domain.NoteHeader, maxWorkers int) []BuildResponse {
+func (s *BuildService) buildConcurrently(ctx context.Context, headers []domain.NoteHeader, maxWorkers int, timeout time.Duration) []BuildResponse {
 	// Create channels for work distribution
 	jobs := make(chan domain.NoteHeader, len(headers))
 	results := make(chan BuildResponse, len(headers))
@@ -212,7 +442,7 @@ func (s *BuildService) buildConcurrently(ctx context.Context, headers []domain.N
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			s.worker(ctx, jobs, results)
+			s.worker(ctx, jobs, results, timeout)
 		}()
 	}
 
@@ -238,7 +468,7 @@ func (s *BuildService) buildConcurrently(ctx context.Context, headers []domain.N
 }
 
 // worker is a worker goroutine that processes build jobs
-func (s *BuildService) worker(ctx context.Context, jobs <-chan domain.NoteHeader, results chan<- BuildResponse) {
+func (s *BuildService) worker(ctx context.Context, jobs <-chan domain.NoteHeader, results chan<- BuildResponse, timeout time.Duration) {
 	for header := range jobs {
 		// Check if context is cancelled
 		select {
@@ -246,59 +476,35 @@ func (s *BuildService) worker(ctx context.Context, jobs <-chan domain.NoteHeader
 			results <- BuildResponse{
 				Slug:    header.Slug,
 				Success: false,
-				Error:   ctx.Err(),
+				Error:   fmt.Errorf("build cancelled: %w", ctx.Err()),
 			}
 			continue
 		default:
 		}
 
-		// 1. Preprocess
-		preprocessedPath, err := s.preprocessor.Process(header.Slug)
-		if err != nil {
-			results <- BuildResponse{
-				Slug:    header.Slug,
-				Success: false,
-				Error:   err,
-			}
-			continue
-		}
-
-		// 2. Compile
-		err = s.compiler.Compile(ctx, preprocessedPath, []string{})
-
-		result := BuildResponse{
-			Slug: header.Slug,
-		}
-
-		if err != nil {
-			result.Success = false
-			result.Error = err
-		} else {
-			result.Success = true
-			result.OutputPath = s.compiler.GetOutputPath(header.Slug)
-		}
-
-		results <- result
+		details := s.buildNote(ctx, header.Slug, timeout)
+		results <- details.Response()
 	}
 }
 
 // BuildProgress represents the progress of a build operation
 type BuildProgress struct {
-	Current int
-	Total   int
-	Slug    string
-	Success bool
-	Error   error
+	Current  int
+	Total    int
+	Slug     string
+	Success  bool
+	TimedOut bool
+	Error    error
 }
 
-// ExecuteAllWithProgress builds all notes and reports progress
+// ExecuteAllWithProgress builds all stale notes and reports progress
+// Progress totals count only the notes that are actually compiled
 func (s *BuildService) ExecuteAllWithProgress(ctx context.Context, req BuildAllRequest, progressChan chan<- BuildProgress) (*BuildAllResponse, error) {
 	defer close(progressChan)
 
-	// Get all notes
-	headers, err := s.noteRepo.ListHeaders(ctx)
+	headers, plan, manifest, err := s.prepareBuildAll(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list notes: %w", err)
+		return nil, err
 	}
 
 	if len(headers) == 0 {
@@ -310,34 +516,14 @@ func (s *BuildService) ExecuteAllWithProgress(ctx context.Context, req BuildAllR
 		}, nil
 	}
 
-	// Default to 4 workers if not specified
-	maxWorkers := req.MaxWorkers
-	if maxWorkers <= 0 {
-		maxWorkers = 4
-	}
-
 	// Build with progress reporting
-	results := s.buildWithProgress(ctx, headers, maxWorkers, progressChan)
-
-	// Aggregate results
-	response := &BuildAllResponse{
-		Total:   len(headers),
-		Results: results,
-	}
-
-	for _, result := range results {
-		if result.Success {
-			response.Succeeded++
-		} else {
-			response.Failed++
-		}
-	}
+	results := s.buildWithProgress(ctx, plan.stale, maxWorkers(req), s.effectiveTimeout(req.Timeout), progressChan)
 
-	return response, nil
+	return s.finishBuildAll(headers, plan, manifest, results), nil
 }
 
 // buildWithProgress builds notes with progress reporting
-func (s *BuildService) buildWithProgress(ctx context.Context, headers []domain.NoteHeader, maxWorkers int, progressChan chan<- BuildProgress) []BuildResponse {
+func (s *BuildService) buildWithProgress(ctx context.Context, headers []domain.NoteHeader, maxWorkers int, timeout time.Duration, progressChan chan<- BuildProgress) []BuildResponse {
 	jobs := make(chan domain.NoteHeader, len(headers))
 	results := make(chan BuildResponse, len(headers))
 
@@ -350,7 +536,7 @@ func (s *BuildService) buildWithProgress(ctx context.Context, headers []domain.N
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			s.worker(ctx, jobs, results)
+			s.worker(ctx, jobs, results, timeout)
 		}()
 	}
 
@@ -374,11 +560,12 @@ func (s *BuildService) buildWithProgress(ctx context.Context, headers []domain.N
 
 		// Report progress
 		progressChan <- BuildProgress{
-			Current: current,
-			Total:   total,
-			Slug:    result.Slug,
-			Success: result.Success,
-			Error:   result.Error,
+			Current:  current,
+			Total:    total,
+			Slug:     result.Slug,
+			Success:  result.Success,
+			TimedOut: result.TimedOut,
+			Error:    result.Error,
 		}
 	}
 
@@ -2,11 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
 func TestBuildService_Execute_Success(t *testing.T) {
@@ -151,6 +160,241 @@ func TestBuildService_Execute_CompilationFailure(t *testing.T) {
 	}
 }
 
+func TestBuildService_ExecuteWithDetails_CompilationFailure(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	header, _ := domain.NewNoteHeader("Detailed Failure", []string{}, "Detail.md")
+	note := domain.NewNoteBody(header, "\\undefined")
+	mockRepo.Save(context.Background(), note)
+
+	mockCompiler.SetShouldFail(true, fmt.Errorf("Undefined control sequence"))
+
+	details, err := svc.ExecuteWithDetails(context.Background(), BuildRequest{Slug: header.Slug})
+
+	if err == nil {
+		t.Fatal("expected error from compilation failure")
+	}
+
+	if details == nil || details.Parsed == nil {
+		t.Fatal("expected parsed compiler output on failure")
+	}
+
+	if len(details.Parsed.Errors) != 1 {
+		t.Errorf("expected 1 parsed error, got %d", len(details.Parsed.Errors))
+	}
+
+	if details.Output == "" {
+		t.Error("expected raw compiler output to be preserved")
+	}
+}
+
+func TestBuildService_RecordsBuildHistory(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	buildLog := mocks.NewMockBuildLogRepository()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+	svc.SetBuildLog(buildLog)
+
+	header, _ := domain.NewNoteHeader("History Note", []string{}, "History.md")
+	mockRepo.Save(context.Background(), domain.NewNoteBody(header, "\\section{History}"))
+
+	// One successful and one failed build
+	if _, err := svc.Execute(context.Background(), BuildRequest{Slug: header.Slug}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mockCompiler.SetShouldFail(true, fmt.Errorf("Undefined control sequence"))
+	svc.Execute(context.Background(), BuildRequest{Slug: header.Slug})
+
+	records, _ := buildLog.List(context.Background(), header.Slug, 0)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 build records, got %d", len(records))
+	}
+
+	latest, first := records[0], records[1]
+	if !first.Success || latest.Success {
+		t.Errorf("expected success then failure, got %v then %v", first.Success, latest.Success)
+	}
+	if latest.Engine != "mock" {
+		t.Errorf("expected engine=mock, got %q", latest.Engine)
+	}
+	if len(latest.Errors) != 1 || latest.Errors[0].Message != "Undefined control sequence" {
+		t.Errorf("expected the parsed error to be recorded, got %v", latest.Errors)
+	}
+	if latest.Output == "" {
+		t.Error("expected raw compiler output to be recorded")
+	}
+}
+
+func TestBuildService_Execute_Timeout(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	mockPreprocessor.SetSlugPaths("/fake/cache")
+	buildLog := mocks.NewMockBuildLogRepository()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+	svc.SetTimeout(time.Hour)
+	svc.SetBuildLog(buildLog)
+
+	header, _ := domain.NewNoteHeader("Endless Loop", []string{}, "")
+	mockRepo.Save(context.Background(), domain.NewNoteBody(header, "\\def\\a{\\a}\\a"))
+	mockCompiler.SetHanging(header.Slug + ".tex")
+
+	// The request timeout overrides the service default
+	resp, err := svc.Execute(context.Background(), BuildRequest{Slug: header.Slug, Timeout: 20 * time.Millisecond})
+
+	if !errors.Is(err, ErrBuildTimeout) {
+		t.Fatalf("expected ErrBuildTimeout, got %v", err)
+	}
+	if resp.Success || !resp.TimedOut {
+		t.Errorf("expected a timed-out failure, got %+v", resp)
+	}
+
+	records, _ := buildLog.List(context.Background(), header.Slug, 0)
+	if len(records) != 1 || records[0].Success || !records[0].TimedOut {
+		t.Errorf("expected the build history to record a timeout, got %+v", records)
+	}
+}
+
+func TestBuildService_ExecuteAll_ReportsTimeoutsSeparately(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	mockPreprocessor.SetSlugPaths("/fake/cache")
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+	svc.SetTimeout(20 * time.Millisecond)
+
+	for _, title := range []string{"Fine", "Stuck"} {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, "content"))
+	}
+	mockCompiler.SetHanging("stuck.tex")
+
+	resp, err := svc.ExecuteAll(context.Background(), BuildAllRequest{MaxWorkers: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Succeeded != 1 || resp.TimedOut != 1 || resp.Failed != 0 {
+		t.Errorf("expected 1 succeeded, 1 timed out, 0 failed; got %d, %d, %d", resp.Succeeded, resp.TimedOut, resp.Failed)
+	}
+}
+
+func TestBuildService_ExecuteAll_Cancelled(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	mockPreprocessor.SetSlugPaths("/fake/cache")
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	header, _ := domain.NewNoteHeader("Stuck", []string{}, "")
+	mockRepo.Save(context.Background(), domain.NewNoteBody(header, "content"))
+	mockCompiler.SetHanging("stuck.tex")
+
+	// Simulate Ctrl+C while the build is running
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	resp, err := svc.ExecuteAll(ctx, BuildAllRequest{MaxWorkers: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Failed != 1 || resp.TimedOut != 0 {
+		t.Errorf("expected the cancelled build to fail without timing out, got %+v", resp)
+	}
+	if !errors.Is(resp.Results[0].Error, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", resp.Results[0].Error)
+	}
+}
+
+func TestBuildService_Execute_PerNoteOptions(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	header, _ := domain.NewNoteHeader("Minted Note", []string{}, "Minted.md")
+	content := "% title: Minted Note\n% engine: lualatex\n% shell-escape: true\n% latexmk-flags: -synctex=1\n% bib: biber\n\\documentclass{article}"
+	note := domain.NewNoteBody(header, content)
+	mockRepo.Save(context.Background(), note)
+
+	if _, err := svc.Execute(context.Background(), BuildRequest{Slug: header.Slug}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	options := mockCompiler.GetOptions()
+	if len(options) != 1 {
+		t.Fatalf("expected 1 compile call, got %d", len(options))
+	}
+
+	opts := options[0]
+	if opts.Engine != "lualatex" {
+		t.Errorf("expected engine=lualatex, got %q", opts.Engine)
+	}
+	if !opts.ShellEscape {
+		t.Error("expected shell-escape to be enabled")
+	}
+	if len(opts.LatexmkFlags) != 1 || opts.LatexmkFlags[0] != "-synctex=1" {
+		t.Errorf("expected latexmk flags [-synctex=1], got %v", opts.LatexmkFlags)
+	}
+	if opts.Bib != "biber" {
+		t.Errorf("expected bib=biber, got %q", opts.Bib)
+	}
+}
+
+func TestBuildService_Execute_ReportsIgnoredOptions(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	header, _ := domain.NewNoteHeader("Odd Note", []string{}, "Odd.md")
+	content := "% title: Odd Note\n% engine: context\n\\documentclass{article}"
+	mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+
+	details, err := svc.ExecuteWithDetails(context.Background(), BuildRequest{Slug: header.Slug})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if details.Parsed == nil || len(details.Parsed.Warnings) == 0 {
+		t.Fatalf("expected the ignored engine to be reported, got %+v", details.Parsed)
+	}
+	warning := details.Parsed.Warnings[0]
+	if warning.Kind != latexparser.KindBuildOption || !strings.Contains(warning.Message, "context") {
+		t.Errorf("expected a build-option warning about the engine, got %+v", warning)
+	}
+}
+
+func TestBuildService_Execute_DefaultOptions(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	header, _ := domain.NewNoteHeader("Plain Note", []string{}, "Plain.md")
+	note := domain.NewNoteBody(header, "% title: Plain Note\n\\documentclass{article}")
+	mockRepo.Save(context.Background(), note)
+
+	if _, err := svc.Execute(context.Background(), BuildRequest{Slug: header.Slug}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	options := mockCompiler.GetOptions()
+	if len(options) != 1 {
+		t.Fatalf("expected 1 compile call, got %d", len(options))
+	}
+
+	opts := options[0]
+	if opts.Engine != "" || opts.ShellEscape || len(opts.LatexmkFlags) != 0 || opts.Bib != "" {
+		t.Errorf("expected empty options to fall back to config, got %+v", opts)
+	}
+}
+
 func TestBuildService_ExecuteAll_Success(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	mockCompiler := mocks.NewMockCompiler()
@@ -191,6 +435,33 @@ func TestBuildService_ExecuteAll_Success(t *testing.T) {
 	}
 }
 
+func TestBuildService_ExecuteAll_OnlySelectedSlugs(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockPreprocessor := mocks.NewMockPreprocessor()
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, nil)
+
+	for _, title := range []string{"First Note", "Second Note", "Third Note"} {
+		header, _ := domain.NewNoteHeader(title, nil, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, "\\section{Content}"))
+	}
+
+	resp, err := svc.ExecuteAll(context.Background(), BuildAllRequest{
+		MaxWorkers: 2,
+		Slugs:      []string{"first-note", "third-note"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.Total != 2 || resp.Succeeded != 2 {
+		t.Errorf("expected 2 notes built, got Total=%d Succeeded=%d", resp.Total, resp.Succeeded)
+	}
+	if len(mockPreprocessor.GetCalls()) != 2 {
+		t.Errorf("expected 2 preprocessor calls, got %d", len(mockPreprocessor.GetCalls()))
+	}
+}
+
 func TestBuildService_ExecuteAllWithProgress_Success(t *testing.T) {
 	mockRepo := mocks.NewMockRepository()
 	mockCompiler := mocks.NewMockCompiler()
@@ -229,3 +500,152 @@ func TestBuildService_ExecuteAllWithProgress_Success(t *testing.T) {
 		t.Errorf("expected %d progress updates, got %d", noteCount, len(progressUpdates))
 	}
 }
+
+// setupIncrementalBuild creates a temp vault whose "PDFs" always exist,
+// so staleness is decided purely by the build manifest
+func setupIncrementalBuild(t *testing.T) (*BuildService, *mocks.MockRepository, *mocks.MockPreprocessor, *vault.Vault) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	v := &vault.Vault{
+		RootPath:      tempDir,
+		NotesPath:     filepath.Join(tempDir, "notes"),
+		TemplatesPath: filepath.Join(tempDir, "templates"),
+		AssetsPath:    filepath.Join(tempDir, "assets"),
+		CachePath:     filepath.Join(tempDir, "cache"),
+	}
+	if err := v.Initialize(); err != nil {
+		t.Fatalf("failed to initialize vault: %v", err)
+	}
+
+	mockRepo := mocks.NewMockRepository()
+	mockCompiler := mocks.NewMockCompiler()
+	mockCompiler.SetOutputPrefix(v.CachePath + string(filepath.Separator))
+	mockPreprocessor := mocks.NewMockPreprocessor()
+
+	notes := map[string]string{
+		"Alpha": "\\usepackage{style}\n\\section{Alpha}",
+		"Beta":  "\\section{Beta} see \\lxnote{alpha}",
+		"Gamma": "\\section{Gamma}",
+	}
+	for title, content := range notes {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+		os.WriteFile(v.GetCachePath(header.Slug+".pdf"), []byte("%PDF"), 0644)
+	}
+	os.WriteFile(v.GetTemplatePath("style.sty"), []byte("% v1"), 0644)
+
+	svc := NewBuildServiceWithPreprocessor(mockRepo, mockCompiler, mockPreprocessor, v)
+	return svc, mockRepo, mockPreprocessor, v
+}
+
+func TestBuildService_ExecuteAll_SkipsUpToDateNotes(t *testing.T) {
+	svc, _, mockPreprocessor, _ := setupIncrementalBuild(t)
+	ctx := context.Background()
+
+	first, err := svc.ExecuteAll(ctx, BuildAllRequest{MaxWorkers: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Succeeded != 3 || first.Skipped != 0 {
+		t.Fatalf("expected first build to compile all notes, got %+v", first)
+	}
+
+	mockPreprocessor.Reset()
+	second, err := svc.ExecuteAll(ctx, BuildAllRequest{MaxWorkers: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.Skipped != 3 || len(mockPreprocessor.GetCalls()) != 0 {
+		t.Errorf("expected all notes to be skipped, got skipped=%d built=%d",
+			second.Skipped, len(mockPreprocessor.GetCalls()))
+	}
+
+	mockPreprocessor.Reset()
+	forced, err := svc.ExecuteAll(ctx, BuildAllRequest{MaxWorkers: 2, Force: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if forced.Skipped != 0 || len(mockPreprocessor.GetCalls()) != 3 {
+		t.Errorf("expected --force to rebuild all notes, got skipped=%d built=%d",
+			forced.Skipped, len(mockPreprocessor.GetCalls()))
+	}
+}
+
+func TestBuildService_ExecuteAll_RebuildsDependents(t *testing.T) {
+	svc, _, mockPreprocessor, v := setupIncrementalBuild(t)
+	ctx := context.Background()
+
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Changing alpha's template makes alpha stale, and beta links to alpha
+	os.WriteFile(v.GetTemplatePath("style.sty"), []byte("% v2"), 0644)
+
+	mockPreprocessor.Reset()
+	resp, err := svc.ExecuteAll(ctx, BuildAllRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	built := mockPreprocessor.GetCalls()
+	sort.Strings(built)
+	if !reflect.DeepEqual(built, []string{"alpha", "beta"}) {
+		t.Errorf("expected alpha and beta to be rebuilt, got %v", built)
+	}
+	if resp.Skipped != 1 {
+		t.Errorf("expected gamma to be skipped, got Skipped=%d", resp.Skipped)
+	}
+}
+
+func TestBuildService_ExecuteAll_RebuildsEmbedsNamingTheVault(t *testing.T) {
+	svc, mockRepo, mockPreprocessor, v := setupIncrementalBuild(t)
+	v.Name = "home"
+	ctx := context.Background()
+
+	header, _ := domain.NewNoteHeader("Gamma", []string{}, "")
+	mockRepo.Save(ctx, domain.NewNoteBody(header, "\\section{Gamma} \\lxembed{home:alpha}"))
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.WriteFile(v.GetTemplatePath("style.sty"), []byte("% v2"), 0644)
+
+	mockPreprocessor.Reset()
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	built := mockPreprocessor.GetCalls()
+	sort.Strings(built)
+	if !reflect.DeepEqual(built, []string{"alpha", "beta", "gamma"}) {
+		t.Errorf("expected gamma to be rebuilt with the note it embeds, got %v", built)
+	}
+}
+
+func TestBuildService_ExecuteAll_KeepsDependentsStaleUntilBuilt(t *testing.T) {
+	svc, _, mockPreprocessor, v := setupIncrementalBuild(t)
+	ctx := context.Background()
+
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only alpha is built after its template changes, so beta is still out of date
+	os.WriteFile(v.GetTemplatePath("style.sty"), []byte("% v2"), 0644)
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{Slugs: []string{"alpha"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mockPreprocessor.Reset()
+	if _, err := svc.ExecuteAll(ctx, BuildAllRequest{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	built := mockPreprocessor.GetCalls()
+	sort.Strings(built)
+	if !reflect.DeepEqual(built, []string{"alpha", "beta"}) {
+		t.Errorf("expected beta to be rebuilt with alpha, got %v", built)
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"path"
 	"strings"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
@@ -40,6 +41,7 @@ type CreateNoteRequest struct {
 	Tags         []string
 	TemplateName string
 	DateFormat   string // New field for configurable date format
+	Notebook     string // Directory inside the notes directory, e.g. courses/math201; empty for the top level
 }
 
 // CreateNoteResponse represents the response from creating a note
@@ -55,13 +57,19 @@ func (s *CreateNoteService) Execute(ctx context.Context, req CreateNoteRequest)
 		return nil, fmt.Errorf("invalid title: %w", err)
 	}
 
+	if err := domain.ValidateNotebook(req.Notebook); err != nil {
+		return nil, fmt.Errorf("invalid notebook: %w", err)
+	}
+
 	// Create note header with configured date format
 	header, err := domain.NewNoteHeader(req.Title, req.Tags, req.DateFormat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create note header: %w", err)
 	}
+	header.Notebook = domain.NormalizeNotebook(req.Notebook)
+	header.Filename = path.Join(header.Notebook, header.Filename)
 
-	// Check if note already exists
+	// Check if note already exists, in any notebook, so slugs stay unique
 	if s.noteRepo.Exists(ctx, header.Slug) {
 		return nil, fmt.Errorf("note with slug '%s' already exists", header.Slug)
 	}
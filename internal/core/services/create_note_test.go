@@ -98,6 +98,16 @@ func TestCreateNoteService_Execute(t *testing.T) {
 			expectError: true,
 			errorMsg:    "already exists",
 		},
+		{
+			name: "notebook outside the notes directory should fail",
+			request: CreateNoteRequest{
+				Title:    "Escaping Note",
+				Notebook: "../elsewhere",
+			},
+			setupMocks:  func(nr *mocks.MockRepository, tr *mocks.MockTemplateRepository) {},
+			expectError: true,
+			errorMsg:    "invalid notebook",
+		},
 		{
 			name: "non-existent template should fail",
 			request: CreateNoteRequest{
@@ -214,6 +224,31 @@ func TestCreateNoteService_Execute(t *testing.T) {
 	}
 }
 
+func TestCreateNoteService_Notebook(t *testing.T) {
+	mockNoteRepo := mocks.NewMockRepository()
+	service := NewCreateNoteService(mockNoteRepo, mocks.NewMockTemplateRepository(), NewGitService("/tmp/test"), &config.Config{})
+	ctx := context.Background()
+
+	resp, err := service.Execute(ctx, CreateNoteRequest{Title: "Limits", Notebook: "/courses/math201/"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Note.Header.Notebook != "courses/math201" {
+		t.Errorf("Expected notebook 'courses/math201', got '%s'", resp.Note.Header.Notebook)
+	}
+	if resp.FilePath != "courses/math201/limits.tex" {
+		t.Errorf("Expected file 'courses/math201/limits.tex', got '%s'", resp.FilePath)
+	}
+	if resp.Note.Header.Slug != "limits" {
+		t.Errorf("Expected slug 'limits', got '%s'", resp.Note.Header.Slug)
+	}
+
+	// The slug is taken, whichever notebook the new note would go in
+	if _, err := service.Execute(ctx, CreateNoteRequest{Title: "Limits", Notebook: "courses/math202"}); err == nil || !contains(err.Error(), "already exists") {
+		t.Errorf("Expected duplicate slug error, got %v", err)
+	}
+}
+
 func TestCreateNoteService_SlugGeneration(t *testing.T) {
 	tests := []struct {
 		title        string
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// maxEmbedDepth limits how deeply embedded notes may embed other notes
+const maxEmbedDepth = 5
+
+// sectionCommands are the sectioning commands that delimit an embedded section
+var sectionCommands = []string{"part", "chapter", "section", "subsection", "subsubsection", "paragraph"}
+
+// sectionLevels ranks sectioning commands, outermost first
+var sectionLevels = map[string]int{
+	"part":          0,
+	"chapter":       1,
+	"section":       2,
+	"subsection":    3,
+	"subsubsection": 4,
+	"paragraph":     5,
+}
+
+// sourceLine is a line of expanded content and where it came from
+type sourceLine struct {
+	text string
+	file string // Source file, e.g. "notes/20250101-foo.tex"
+	line int
+}
+
+// expandEmbeds replaces \lxembed commands with the embedded content, recursively
+// Every returned line remembers its source so the line map can point into embedded notes
+// stack holds the slugs being expanded, to detect cycles
+func (p *Preprocessor) expandEmbeds(content, file string, firstLine int, stack []string) []sourceLine {
+	var lines []sourceLine
+
+	// Embeds in comments and verbatim environments are not expanded
+	invocations := latexscan.FindCommands(content, "lxembed")
+	lineStart := 0
+
+	for i, text := range strings.Split(content, "\n") {
+		lineNum := firstLine + i
+		lineEnd := lineStart + len(text)
+
+		// Embeds on this line; one spanning several lines is left as written
+		var matches []latexscan.Invocation
+		for len(invocations) > 0 && invocations[0].Start < lineEnd {
+			if invocations[0].End <= lineEnd {
+				matches = append(matches, invocations[0])
+			}
+			invocations = invocations[1:]
+		}
+
+		if len(matches) == 0 {
+			lines = append(lines, sourceLine{text: text, file: file, line: lineNum})
+			lineStart = lineEnd + 1
+			continue
+		}
+
+		// Text around an embed stays on its own line; embedded lines go in between
+		current := ""
+		cursor := 0
+		for _, inv := range matches {
+			current += text[cursor : inv.Start-lineStart]
+			cursor = inv.End - lineStart
+
+			target := domain.ParseNoteTarget(inv.Arg.Text)
+			embedded, marker := p.embed(target, stack)
+			if marker != "" {
+				current += marker
+				continue
+			}
+
+			lines = append(lines, sourceLine{text: current, file: file, line: lineNum})
+			lines = append(lines, embedded...)
+			current = ""
+		}
+		current += text[cursor:]
+		lines = append(lines, sourceLine{text: current, file: file, line: lineNum})
+		lineStart = lineEnd + 1
+	}
+
+	return lines
+}
+
+// embed expands a single embed target
+// On failure it returns a marker to render in place of the embed instead
+func (p *Preprocessor) embed(target domain.NoteTarget, stack []string) ([]sourceLine, string) {
+	target, local := target.Local(p.vault.Name)
+	if !local {
+		return nil, fmt.Sprintf(`\textbf{[EMBED FROM ANOTHER VAULT: %s:%s]}`, target.Vault, target.Slug)
+	}
+	for _, slug := range stack {
+		if slug == target.Slug {
+			return nil, fmt.Sprintf(`\textbf{[EMBED CYCLE: %s]}`, target.Slug)
+		}
+	}
+	if len(stack) > maxEmbedDepth {
+		return nil, fmt.Sprintf(`\textbf{[EMBED TOO DEEP: %s]}`, target.Slug)
+	}
+
+	note, err := p.repo.Get(context.Background(), target.Slug)
+	if err != nil {
+		return nil, fmt.Sprintf(`\textbf{[BROKEN EMBED: %s]}`, target.Slug)
+	}
+
+	body, firstLine, ok := embedBody(note.Content, target.Label)
+	if !ok {
+		return nil, fmt.Sprintf(`\textbf{[BROKEN EMBED: %s\#\detokenize{%s}]}`, target.Slug, target.Label)
+	}
+
+	file := filepath.ToSlash(filepath.Join(filepath.Base(p.vault.NotesPath), note.Header.Filename))
+	return p.expandEmbeds(body, file, firstLine, append(stack, target.Slug)), ""
+}
+
+// embedBody returns the part of a note to embed and the line it starts on
+// Without a label that is the document body; with one it is the environment
+// or section the label belongs to
+func embedBody(content, label string) (string, int, bool) {
+	start, end := 0, len(content)
+	environments := latexscan.FindCommands(content, "begin", "end")
+
+	if label == "" {
+		for _, inv := range environments {
+			if inv.Arg.Text != "document" {
+				continue
+			}
+			if inv.Name == "begin" {
+				start = inv.End
+			} else if inv.Start >= start {
+				end = inv.Start
+			}
+		}
+	} else {
+		pos := -1
+		for _, inv := range latexscan.FindCommands(content, "label") {
+			if strings.TrimSpace(inv.Arg.Text) == label {
+				pos = inv.Start
+				break
+			}
+		}
+		if pos < 0 {
+			return "", 0, false
+		}
+
+		var found bool
+		start, end, found = enclosingEnvironment(environments, pos)
+		if !found {
+			start, end, found = enclosingSection(content, environments, pos)
+		}
+		if !found {
+			return "", 0, false
+		}
+	}
+
+	return content[start:end], strings.Count(content[:start], "\n") + 1, true
+}
+
+// enclosingEnvironment finds the innermost environment (other than document) around pos
+func enclosingEnvironment(environments []latexscan.Invocation, pos int) (int, int, bool) {
+	var stack []latexscan.Invocation
+	next := len(environments)
+
+	for i, inv := range environments {
+		if inv.Start >= pos {
+			next = i
+			break
+		}
+		if inv.Name == "begin" {
+			stack = append(stack, inv)
+		} else if n := len(stack); n > 0 && stack[n-1].Arg.Text == inv.Arg.Text {
+			stack = stack[:n-1]
+		}
+	}
+
+	if len(stack) == 0 || stack[len(stack)-1].Arg.Text == "document" {
+		return 0, 0, false
+	}
+	env := stack[len(stack)-1]
+
+	// Find the matching \end, allowing the same environment to nest
+	depth := 1
+	for _, inv := range environments[next:] {
+		if inv.Arg.Text != env.Arg.Text {
+			continue
+		}
+		if inv.Name == "begin" {
+			depth++
+			continue
+		}
+		depth--
+		if depth == 0 {
+			return env.Start, inv.End, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// enclosingSection finds the section around pos: from its heading up to the
+// next heading of the same or a higher level, or the end of the document
+func enclosingSection(content string, environments []latexscan.Invocation, pos int) (int, int, bool) {
+	headings := latexscan.FindCommands(content, sectionCommands...)
+
+	current := -1
+	for i, inv := range headings {
+		if inv.Start > pos {
+			break
+		}
+		current = i
+	}
+	if current < 0 {
+		return 0, 0, false
+	}
+
+	start := headings[current].Start
+	level := sectionLevels[headings[current].Name]
+
+	end := len(content)
+	for _, inv := range environments {
+		if inv.Name == "end" && inv.Arg.Text == "document" && inv.Start > pos {
+			end = inv.Start
+			break
+		}
+	}
+	for _, inv := range headings[current+1:] {
+		if inv.Start >= end {
+			break
+		}
+		if sectionLevels[inv.Name] <= level {
+			end = inv.Start
+			break
+		}
+	}
+
+	return start, end, true
+}
+
+// joinLines joins expanded lines back into content
+func joinLines(lines []sourceLine) string {
+	texts := make([]string, len(lines))
+	for i, l := range lines {
+		texts[i] = l.text
+	}
+	return strings.Join(texts, "\n")
+}
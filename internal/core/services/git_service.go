@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
 // GitService handles interactions with the git CLI
@@ -37,8 +41,60 @@ func (s *GitService) Init() error {
 	return s.runGit("init")
 }
 
+// ExcludeLocal keeps folders of the working directory out of git by listing them in
+// .git/info/exclude. Unlike .gitignore, this also covers repositories created
+// before the folders existed, and is not shared with other clones
+func (s *GitService) ExcludeLocal(dirs ...string) error {
+	gitDir := filepath.Join(s.workingDir, ".git")
+	if info, err := os.Stat(gitDir); err != nil || !info.IsDir() {
+		return nil // Not a repository (or a worktree); nothing to exclude from
+	}
+
+	// 1. Read the patterns already excluded
+	path := filepath.Join(gitDir, "info", "exclude")
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read git excludes: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	// 2. Append the missing ones
+	content := string(data)
+	changed := false
+	for _, dir := range dirs {
+		pattern := "/" + dir + "/"
+		if existing[pattern] {
+			continue
+		}
+		if content != "" && !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += pattern + "\n"
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create git info directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write git excludes: %w", err)
+	}
+	return nil
+}
+
 // CommitChanges stages all files and commits them with the given message
+// Folders local to this machine, such as the trash, are never staged
 func (s *GitService) CommitChanges(message string) error {
+	if err := s.ExcludeLocal(vault.LocalDirs...); err != nil {
+		return err
+	}
+
 	// 1. Add all changes (including new files)
 	if err := s.runGit("add", "."); err != nil {
 		return fmt.Errorf("git add failed: %w", err)
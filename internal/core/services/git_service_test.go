@@ -173,3 +173,36 @@ func TestGitService_Sync(t *testing.T) {
 		t.Errorf("Remote repo did not receive the commit via Sync")
 	}
 }
+
+func TestGitService_CommitChangesSkipsLocalFolders(t *testing.T) {
+	dir, svc := setupGitEnv(t)
+
+	os.WriteFile(filepath.Join(dir, "note.tex"), []byte("note"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".trash", "item"), 0755)
+	os.WriteFile(filepath.Join(dir, ".trash", "item", "old.tex"), []byte("deleted"), 0644)
+	os.MkdirAll(filepath.Join(dir, ".journal", "op"), 0755)
+	os.WriteFile(filepath.Join(dir, ".journal", "op", "operation.json"), []byte("{}"), 0644)
+
+	if err := svc.CommitChanges("Add note"); err != nil {
+		t.Fatalf("CommitChanges failed: %v", err)
+	}
+
+	cmd := exec.Command("git", "ls-files")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files := strings.TrimSpace(string(out)); files != "note.tex" {
+		t.Errorf("expected only note.tex to be committed, got %q", files)
+	}
+
+	// Excluding again does not repeat the patterns
+	if err := svc.ExcludeLocal(".trash", ".journal"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ".git", "info", "exclude"))
+	if strings.Count(string(data), "/.trash/") != 1 {
+		t.Errorf("expected /.trash/ to be excluded once, got:\n%s", data)
+	}
+}
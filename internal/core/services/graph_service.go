@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/ports"
 	"github.com/kamal-hamza/lx-cli/pkg/config"
 )
@@ -20,6 +21,7 @@ type GraphNode struct {
 type GraphLink struct {
 	Source string
 	Target string
+	Kind   domain.LinkKind // Type of the first link found between the two
 }
 
 // GraphData contains the full graph structure
@@ -29,8 +31,9 @@ type GraphData struct {
 }
 
 type GraphService struct {
-	repo   ports.Repository
-	config *config.Config
+	repo      ports.Repository
+	config    *config.Config
+	vaultName string
 }
 
 // NewGraphService creates a new instance of GraphService with config
@@ -41,6 +44,11 @@ func NewGraphService(repo ports.Repository, cfg *config.Config) *GraphService {
 	}
 }
 
+// SetVaultName sets the name of the vault, so links naming it count as local
+func (s *GraphService) SetVaultName(name string) {
+	s.vaultName = name
+}
+
 // GenerateGraphDOT generates a DOT format string for the note graph
 func (s *GraphService) GenerateGraphDOT(ctx context.Context) (string, error) {
 	notes, err := s.repo.ListHeaders(ctx)
@@ -77,14 +85,9 @@ func (s *GraphService) GenerateGraphDOT(ctx context.Context) (string, error) {
 		safeSlug := strings.ReplaceAll(note.Slug, "-", "_")
 		sb.WriteString(fmt.Sprintf("  \"%s\" [label=\"%s\"];\n", safeSlug, note.Title))
 
-		for _, link := range note.Tags {
-			if strings.HasPrefix(link, "link:") {
-				target := strings.TrimPrefix(link, "link:")
-				if existingSlugs[target] {
-					safeTarget := strings.ReplaceAll(target, "-", "_")
-					sb.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\";\n", safeSlug, safeTarget))
-				}
-			}
+		for _, link := range s.noteLinks(ctx, note, existingSlugs) {
+			safeTarget := strings.ReplaceAll(link.Target, "-", "_")
+			sb.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\";\n", safeSlug, safeTarget))
 		}
 	}
 
@@ -120,39 +123,9 @@ func (s *GraphService) GetGraph(ctx context.Context, forceRefresh bool) (GraphDa
 		existingSlugs[note.Slug] = true
 	}
 
-	// Second pass: create links
-	// Look for various link patterns in the content
+	// Second pass: create links between existing notes
 	for _, note := range notes {
-		// Get the full note to analyze links
-		fullNote, err := s.repo.Get(ctx, note.Slug)
-		if err != nil {
-			continue // Skip if we can't read the note
-		}
-
-		// Extract links from content
-		linkedSlugs := extractLinksFromContent(fullNote.Content)
-		for _, targetSlug := range linkedSlugs {
-			// Only create link if target exists
-			if existingSlugs[targetSlug] {
-				links = append(links, GraphLink{
-					Source: note.Slug,
-					Target: targetSlug,
-				})
-			}
-		}
-
-		// Also check tags for link: prefix
-		for _, tag := range note.Tags {
-			if strings.HasPrefix(tag, "link:") {
-				targetSlug := strings.TrimPrefix(tag, "link:")
-				if existingSlugs[targetSlug] {
-					links = append(links, GraphLink{
-						Source: note.Slug,
-						Target: targetSlug,
-					})
-				}
-			}
-		}
+		links = append(links, s.noteLinks(ctx, note, existingSlugs)...)
 	}
 
 	return GraphData{
@@ -161,53 +134,27 @@ func (s *GraphService) GetGraph(ctx context.Context, forceRefresh bool) (GraphDa
 	}, nil
 }
 
-// extractLinksFromContent extracts note references from LaTeX content
-func extractLinksFromContent(content string) []string {
-	var links []string
-	seen := make(map[string]bool)
-
-	// Look for common LaTeX reference patterns
-	patterns := []string{
-		`\ref{`,
-		`\cite{`,
-		`\input{`,
-		`\include{`,
+// noteLinks returns one graph link per existing note that a note links to or embeds
+func (s *GraphService) noteLinks(ctx context.Context, note domain.NoteHeader, existingSlugs map[string]bool) []GraphLink {
+	content := ""
+	if fullNote, err := s.repo.Get(ctx, note.Slug); err == nil {
+		content = fullNote.Content
 	}
 
-	for _, pattern := range patterns {
-		idx := 0
-		for {
-			idx = strings.Index(content[idx:], pattern)
-			if idx == -1 {
-				break
-			}
-			idx += len(pattern)
-
-			// Find the closing brace
-			end := strings.Index(content[idx:], "}")
-			if end == -1 {
-				break
-			}
-
-			ref := content[idx : idx+end]
-			// Clean up the reference to get just the slug
-			ref = strings.TrimSpace(ref)
-			ref = strings.TrimSuffix(ref, ".tex")
-
-			// Extract just the filename if it's a path
-			if strings.Contains(ref, "/") {
-				parts := strings.Split(ref, "/")
-				ref = parts[len(parts)-1]
-			}
-
-			if ref != "" && !seen[ref] {
-				links = append(links, ref)
-				seen[ref] = true
-			}
-
-			idx += end + 1
+	var links []GraphLink
+	seen := make(map[string]bool)
+	for _, link := range domain.NoteLinks(note, content) {
+		local, ok := link.Target.Local(s.vaultName)
+		target := local.Slug
+		if !ok || target == note.Slug || !existingSlugs[target] || seen[target] {
+			continue
 		}
+		seen[target] = true
+		links = append(links, GraphLink{
+			Source: note.Slug,
+			Target: target,
+			Kind:   link.Kind,
+		})
 	}
-
 	return links
 }
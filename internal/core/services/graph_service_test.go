@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"reflect"
 	"testing"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
@@ -146,3 +147,48 @@ func TestGraphService_GetGraph_BrokenLink(t *testing.T) {
 		t.Errorf("expected 0 links (broken link ignored), got %d", len(graph.Links))
 	}
 }
+
+func TestGraphService_GetGraph_TypedLinks(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	svc := NewGraphService(mockRepo, &config.Config{})
+
+	notes := []struct {
+		title   string
+		tags    []string
+		content string
+	}{
+		{"Target", []string{}, "Content"},
+		{"Linker", []string{}, `See \lxnote{target} and \ref{target}`},
+		{"Embedder", []string{}, `\lxembed{target#def:x}`},
+		{"Tagger", []string{"link:target"}, "Content"},
+	}
+	for _, n := range notes {
+		header, _ := domain.NewNoteHeader(n.title, n.tags, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, n.content))
+	}
+
+	graph, err := svc.GetGraph(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kinds := make(map[string]domain.LinkKind)
+	for _, link := range graph.Links {
+		if link.Target != "target" {
+			t.Errorf("unexpected link %+v", link)
+		}
+		if _, dup := kinds[link.Source]; dup {
+			t.Errorf("expected one link per note pair, got another from %s", link.Source)
+		}
+		kinds[link.Source] = link.Kind
+	}
+
+	want := map[string]domain.LinkKind{
+		"linker":   domain.LinkNote,
+		"embedder": domain.LinkEmbed,
+		"tagger":   domain.LinkTag,
+	}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("link kinds = %v, want %v", kinds, want)
+	}
+}
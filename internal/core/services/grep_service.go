@@ -9,6 +9,9 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
 // GrepService handles full-text search operations
@@ -41,22 +44,19 @@ func (s *GrepService) Execute(ctx context.Context, query string) ([]GrepMatch, e
 	// 1. Identify search root
 	notesPath := filepath.Join(s.vaultRoot, "notes")
 
-	// 2. Collect files
-	var files []string
-	entries, err := os.ReadDir(notesPath)
+	// 2. Collect files, including the notes inside notebooks
+	files, err := vault.ListNoteFiles(notesPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read notes directory: %w", err)
 	}
+	slugs := domain.AssignSlugs(files)
 
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".tex") {
-			files = append(files, filepath.Join(notesPath, entry.Name()))
-		}
-	}
+	// 3. Worker Pool, cancelled once enough matches are collected
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	// 3. Worker Pool
 	numWorkers := runtime.NumCPU()
-	jobs := make(chan string, len(files))
+	jobs := make(chan int, len(files))
 	results := make(chan []GrepMatch, len(files))
 	var wg sync.WaitGroup
 
@@ -72,14 +72,16 @@ func (s *GrepService) Execute(ctx context.Context, query string) ([]GrepMatch, e
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for path := range jobs {
-				select {
-				case <-ctx.Done():
+			for i := range jobs {
+				if ctx.Err() != nil {
 					return
-				default:
 				}
 
-				matches := s.scanFile(path, queryInternal, searchAll)
+				matches := s.scanFile(filepath.Join(notesPath, filepath.FromSlash(files[i])), queryInternal, searchAll)
+				for j := range matches {
+					matches[j].Slug = slugs[i]
+					matches[j].Filename = files[i]
+				}
 				if len(matches) > 0 {
 					results <- matches
 				}
@@ -88,8 +90,8 @@ func (s *GrepService) Execute(ctx context.Context, query string) ([]GrepMatch, e
 	}
 
 	// Send jobs
-	for _, f := range files {
-		jobs <- f
+	for i := range files {
+		jobs <- i
 	}
 	close(jobs)
 
@@ -100,17 +102,20 @@ func (s *GrepService) Execute(ctx context.Context, query string) ([]GrepMatch, e
 	}()
 
 	// 4. Collect results
+	// Both channels are buffered per file, so workers never block on a caller that stopped reading
 	var allMatches []GrepMatch
 	for fileMatches := range results {
 		allMatches = append(allMatches, fileMatches...)
 		if s.maxResults > 0 && len(allMatches) >= s.maxResults {
-			allMatches = allMatches[:s.maxResults]
-			// We can break here, but we need to drain the channel or cancel workers
-			// For simplicity in this short snippet, we just return
-			return allMatches, nil
+			cancel()
+			return allMatches[:s.maxResults], nil
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return allMatches, nil
 }
 
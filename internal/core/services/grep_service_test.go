@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -405,3 +406,68 @@ func TestGrepService_Execute_OnlyTexFiles(t *testing.T) {
 		t.Errorf("expected 2 matches from .tex files only, got %d", len(matches))
 	}
 }
+
+func TestGrepService_Execute_MaxResults(t *testing.T) {
+	vaultRoot := t.TempDir()
+	notesPath := filepath.Join(vaultRoot, "notes")
+	if err := os.MkdirAll(notesPath, 0755); err != nil {
+		t.Fatalf("failed to create notes directory: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		name := filepath.Join(notesPath, fmt.Sprintf("20240101-note-%d.tex", i))
+		if err := os.WriteFile(name, []byte("match\nmatch\n"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %v", err)
+		}
+	}
+
+	svc := NewGrepService(vaultRoot, false, 5)
+	matches, err := svc.Execute(context.Background(), "match")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(matches) != 5 {
+		t.Errorf("expected results to stop at 5, got %d", len(matches))
+	}
+}
+
+func TestGrepService_Execute_Notebooks(t *testing.T) {
+	vaultRoot := t.TempDir()
+	for name, content := range map[string]string{
+		"notes/limits.tex":                  "epsilon delta",
+		"notes/courses/math201/limits.tex":  "epsilon again",
+		"notes/courses/math201/series.tex":  "epsilon sums",
+		"notes/.trash/20240101-old.tex":     "epsilon deleted",
+		"notes/courses/math201/figure.tikz": "epsilon drawing",
+	} {
+		path := filepath.Join(vaultRoot, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, err := NewGrepService(vaultRoot, false, 0).Execute(context.Background(), "epsilon")
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	found := make(map[string]string)
+	for _, m := range matches {
+		found[m.Slug] = m.Filename
+	}
+	want := map[string]string{
+		"limits":                 "limits.tex",
+		"courses/math201/limits": "courses/math201/limits.tex",
+		"series":                 "courses/math201/series.tex",
+	}
+	if len(found) != len(want) {
+		t.Fatalf("expected %v, got %v", want, found)
+	}
+	for slug, filename := range want {
+		if found[slug] != filename {
+			t.Errorf("slug %s: expected file %s, got %q", slug, filename, found[slug])
+		}
+	}
+}
@@ -2,116 +2,297 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
 )
 
 type IndexerService struct {
 	noteRepo  ports.Repository
 	indexPath string
+	search    *SearchService
+	math      *MathService
+	vaultName string
 }
 
+// Index files kept next to the graph index
+const (
+	SearchIndexFile = "search-index.json" // Full-text index
+	MathIndexFile   = "math-index.json"   // Formulas
+)
+
 func NewIndexerService(noteRepo ports.Repository, indexPath string) *IndexerService {
 	return &IndexerService{
 		noteRepo:  noteRepo,
 		indexPath: indexPath,
+		search:    NewSearchService(noteRepo, filepath.Join(filepath.Dir(indexPath), SearchIndexFile)),
+		math:      NewMathService(filepath.Join(filepath.Dir(indexPath), MathIndexFile)),
 	}
 }
 
-type ReindexRequest struct{}
+// SetVaultName sets the name of the indexed vault, so links naming it count as local
+func (s *IndexerService) SetVaultName(name string) {
+	s.vaultName = name
+}
+
+type ReindexRequest struct {
+	// Paths limits the reindex to these note files, e.g. the ones a file watcher saw change
+	// When empty, every note is checked against its recorded mtime and hash
+	Paths []string
+
+	// Full discards the existing index and reprocesses every note
+	Full bool
+}
 
 type ReindexResponse struct {
 	TotalNotes       int
 	TotalConnections int
+	Updated          int // Notes added or reprocessed
+	Removed          int
 	Duration         string
 }
 
-// Regex patterns
-var linkPattern = regexp.MustCompile(`\\(?:input|include|ref|cref|cite)\{([^}]+)\}`)
-var assetPattern = regexp.MustCompile(`\\includegraphics(?:\[.*?\])?\{([^}]+)\}`)
-
+// Execute updates the index, reprocessing only notes that were added, changed or removed
 func (s *IndexerService) Execute(ctx context.Context, req ReindexRequest) (*ReindexResponse, error) {
+	// 1. Start from the existing index unless it, the search or the math index is
+	// missing, outdated or a full rebuild was asked for
 	index := domain.NewIndex()
+	incremental := false
+	if !req.Full && !s.search.Stale() && !s.math.Stale() {
+		if existing, err := s.LoadIndex(); err == nil && existing.Version == domain.IndexVersion && existing.Notes != nil {
+			index = existing
+			incremental = true
+		}
+	}
 
-	headers, err := s.noteRepo.ListHeaders(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list notes: %w", err)
+	// 2. Collect the notes to check and the ones that are gone
+	notes := make(map[string]*domain.NoteBody)
+	var headers []domain.NoteHeader
+	var removed []string
+
+	rescan := !incremental || len(req.Paths) == 0
+	if !rescan {
+		seen := make(map[string]bool)
+		for _, path := range req.Paths {
+			slug := domain.ParseFilename(filepath.Base(path))
+			if seen[slug] {
+				continue
+			}
+			seen[slug] = true
+
+			note, err := s.noteRepo.Get(ctx, slug)
+			if err != nil {
+				if !s.noteRepo.Exists(ctx, slug) {
+					if index.HasNote(slug) {
+						removed = append(removed, slug)
+					}
+					continue
+				}
+				// The slug is shared by notes in several notebooks
+				rescan = true
+				break
+			}
+
+			// A slug that moved to another file means notebooks now share it,
+			// which changes the slugs of other notes too
+			if previous, exists := index.GetNote(slug); exists && previous.Filename != note.Header.Filename {
+				rescan = true
+				break
+			}
+			notes[slug] = note
+			headers = append(headers, note.Header)
+		}
 	}
 
-	for _, header := range headers {
-		note, err := s.noteRepo.Get(ctx, header.Slug)
+	if rescan {
+		var err error
+		notes = make(map[string]*domain.NoteBody)
+		removed = nil
+		headers, err = s.noteRepo.ListHeaders(ctx)
 		if err != nil {
+			return nil, fmt.Errorf("failed to list notes: %w", err)
+		}
+
+		present := make(map[string]bool, len(headers))
+		for _, header := range headers {
+			present[header.Slug] = true
+		}
+		for slug := range index.Notes {
+			if !present[slug] {
+				removed = append(removed, slug)
+			}
+		}
+	}
+
+	// 3. Reprocess notes whose file changed, remembering whose backlinks are affected
+	affected := make(map[string]bool)
+	var changed []*domain.NoteBody
+
+	for _, header := range headers {
+		previous, exists := index.GetNote(header.Slug)
+		sameFile := exists && previous.Filename == header.Filename
+		if sameFile && !header.ModTime.IsZero() && previous.ModTime.Equal(header.ModTime) {
 			continue
 		}
 
-		outgoingLinks := s.extractLinks(note.Content, header.Slug)
-		assets := s.extractAssets(note.Content)
+		note, ok := notes[header.Slug]
+		if !ok {
+			var err error
+			note, err = s.noteRepo.Get(ctx, header.Slug)
+			if err != nil {
+				continue
+			}
+		}
+
+		// A touched file with the same content only needs its mtime refreshed
+		hash := hashContent(note.Content)
+		if sameFile && previous.Hash == hash {
+			previous.ModTime = note.Header.ModTime
+			index.AddNote(header.Slug, previous)
+			continue
+		}
 
-		entry := domain.IndexEntry{
-			Title:         header.Title,
-			Date:          header.Date,
-			Tags:          header.Tags,
-			Filename:      header.Filename,
-			OutgoingLinks: outgoingLinks,
-			Backlinks:     []string{},
-			Assets:        assets, // <--- Captured here
+		entry := s.buildEntry(note, hash)
+		if exists {
+			entry.Backlinks = previous.Backlinks
+			entry.EmbeddedIn = previous.EmbeddedIn
+			markTargets(affected, previous)
 		}
+		markTargets(affected, entry)
+		affected[header.Slug] = true
 
 		index.AddNote(header.Slug, entry)
+		changed = append(changed, note)
 	}
 
-	s.calculateBacklinks(index)
+	for _, slug := range removed {
+		if previous, exists := index.GetNote(slug); exists {
+			markTargets(affected, previous)
+		}
+		index.RemoveNote(slug)
+	}
+
+	// 4. Recompute backlinks, for every note on a rebuild or just the affected ones otherwise
+	if incremental {
+		s.updateBacklinks(index, affected)
+	} else {
+		s.calculateBacklinks(index)
+	}
 	index.UpdateLastIndexed()
 
 	if err := s.saveIndex(index); err != nil {
 		return nil, fmt.Errorf("failed to save index: %w", err)
 	}
+	if err := s.search.Update(changed, removed, !incremental); err != nil {
+		return nil, fmt.Errorf("failed to save search index: %w", err)
+	}
+	if err := s.math.Update(changed, removed, !incremental); err != nil {
+		return nil, fmt.Errorf("failed to save math index: %w", err)
+	}
 
 	return &ReindexResponse{
 		TotalNotes:       index.Count(),
 		TotalConnections: index.CountConnections(),
+		Updated:          len(changed),
+		Removed:          len(removed),
 	}, nil
 }
 
-func (s *IndexerService) extractLinks(content string, sourceSlug string) []string {
-	matches := linkPattern.FindAllStringSubmatch(content, -1)
-	linkMap := make(map[string]bool)
-
-	for _, match := range matches {
-		if len(match) > 1 {
-			target := match[1]
-			slug := s.normalizeLink(target)
-			if slug != "" && slug != sourceSlug {
-				linkMap[slug] = true
-			}
+// buildEntry extracts the metadata and connections of a single note
+func (s *IndexerService) buildEntry(note *domain.NoteBody, hash string) domain.IndexEntry {
+	header := note.Header
+	edges := s.extractEdges(domain.NoteLinks(header, note.Content), header.Slug)
+
+	return domain.IndexEntry{
+		Title:         header.Title,
+		Date:          header.Date,
+		Tags:          header.Tags,
+		Filename:      header.Filename,
+		ModTime:       header.ModTime,
+		Hash:          hash,
+		Edges:         edges,
+		OutgoingLinks: edgeTargets(edges, false),
+		Backlinks:     []string{},
+		Embeds:        edgeTargets(edges, true),
+		Assets:        s.extractAssets(note.Content),
+		Labels:        domain.ExtractLabels(note.Content),
+		Todos:         len(domain.ExtractTodos(note.Content)),
+	}
+}
+
+// markTargets adds the notes an entry links to or embeds to the affected set
+func markTargets(affected map[string]bool, entry domain.IndexEntry) {
+	for _, slug := range entry.OutgoingLinks {
+		affected[slug] = true
+	}
+	for _, slug := range entry.Embeds {
+		affected[slug] = true
+	}
+}
+
+// hashContent returns the hex SHA-256 of a note's content
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// extractEdges turns links into unique typed edges, ignoring links to the note itself
+func (s *IndexerService) extractEdges(links []domain.Link, sourceSlug string) []domain.IndexEdge {
+	seen := make(map[domain.IndexEdge]bool)
+	var edges []domain.IndexEdge
+
+	for _, link := range links {
+		target, local := link.Target.Local(s.vaultName)
+		if !local {
+			continue
+		}
+		edge := domain.IndexEdge{Target: target.Slug, Kind: link.Kind}
+		if edge.Target != "" && edge.Target != sourceSlug && !seen[edge] {
+			seen[edge] = true
+			edges = append(edges, edge)
 		}
 	}
+	return edges
+}
+
+// edgeTargets returns the unique targets of either the embed edges or all other edges
+func edgeTargets(edges []domain.IndexEdge, embeds bool) []string {
+	seen := make(map[string]bool)
+	var targets []string
 
-	var links []string
-	for link := range linkMap {
-		links = append(links, link)
+	for _, edge := range edges {
+		if (edge.Kind == domain.LinkEmbed) != embeds || seen[edge.Target] {
+			continue
+		}
+		seen[edge.Target] = true
+		targets = append(targets, edge.Target)
 	}
-	return links
+	return targets
+}
+
+// extractLinks returns the notes linked from content, embeds excluded
+func (s *IndexerService) extractLinks(content string, sourceSlug string) []string {
+	return edgeTargets(s.extractEdges(domain.ExtractLinks(content), sourceSlug), false)
 }
 
 // extractAssets scans for \includegraphics{filename}
 func (s *IndexerService) extractAssets(content string) []string {
-	matches := assetPattern.FindAllStringSubmatch(content, -1)
 	assetMap := make(map[string]bool)
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			// Normalize path separators
-			asset := filepath.ToSlash(match[1])
-			assetMap[asset] = true
-		}
+	for _, inv := range latexscan.FindCommands(content, "includegraphics") {
+		// Normalize path separators
+		asset := filepath.ToSlash(strings.TrimSpace(inv.Arg.Text))
+		assetMap[asset] = true
 	}
 
 	var assets []string
@@ -122,28 +303,13 @@ func (s *IndexerService) extractAssets(content string) []string {
 }
 
 func (s *IndexerService) normalizeLink(link string) string {
-	link = strings.ReplaceAll(link, "\\", "/")
-	link = filepath.Base(link)
-	link = strings.TrimSuffix(link, ".tex")
-
-	if len(link) > 9 && link[8] == '-' {
-		isDate := true
-		for i := 0; i < 8; i++ {
-			if link[i] < '0' || link[i] > '9' {
-				isDate = false
-				break
-			}
-		}
-		if isDate {
-			link = link[9:]
-		}
-	}
-	return strings.TrimSpace(link)
+	return domain.SlugFromReference(link)
 }
 
 func (s *IndexerService) calculateBacklinks(index *domain.Index) {
 	for slug, entry := range index.Notes {
 		entry.Backlinks = []string{}
+		entry.EmbeddedIn = nil
 		index.AddNote(slug, entry)
 	}
 
@@ -154,6 +320,38 @@ func (s *IndexerService) calculateBacklinks(index *domain.Index) {
 				index.AddNote(targetSlug, target)
 			}
 		}
+
+		for _, targetSlug := range entry.Embeds {
+			if target, exists := index.GetNote(targetSlug); exists {
+				target.EmbeddedIn = append(target.EmbeddedIn, sourceSlug)
+				index.AddNote(targetSlug, target)
+			}
+		}
+	}
+}
+
+// updateBacklinks recomputes backlinks and embedders for the given notes only
+func (s *IndexerService) updateBacklinks(index *domain.Index, targets map[string]bool) {
+	for targetSlug := range targets {
+		target, exists := index.GetNote(targetSlug)
+		if !exists {
+			continue
+		}
+
+		target.Backlinks = []string{}
+		target.EmbeddedIn = nil
+		for sourceSlug, source := range index.Notes {
+			if slices.Contains(source.OutgoingLinks, targetSlug) {
+				target.Backlinks = append(target.Backlinks, sourceSlug)
+			}
+			if slices.Contains(source.Embeds, targetSlug) {
+				target.EmbeddedIn = append(target.EmbeddedIn, sourceSlug)
+			}
+		}
+		sort.Strings(target.Backlinks)
+		sort.Strings(target.EmbeddedIn)
+
+		index.AddNote(targetSlug, target)
 	}
 }
 
@@ -1,7 +1,13 @@
 package services
 
 import (
+	"context"
+	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
 )
 
 func TestExtractLinks(t *testing.T) {
@@ -192,3 +198,126 @@ func TestNormalizeLink(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexer_RecordsEmbeds(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	notes := map[string]string{
+		"Definitions": "\\section{Graphs}\\label{def:graph}",
+		"Lecture":     "\\lxembed{definitions#def:graph} and \\lxembed{definitions}",
+	}
+	for title, content := range notes {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+	}
+
+	indexer := NewIndexerService(mockRepo, filepath.Join(t.TempDir(), "index.json"))
+	if _, err := indexer.Execute(context.Background(), ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	index, err := indexer.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+
+	lecture, _ := index.GetNote("lecture")
+	if !reflect.DeepEqual(lecture.Embeds, []string{"definitions"}) {
+		t.Errorf("expected lecture to embed definitions once, got %v", lecture.Embeds)
+	}
+
+	definitions, _ := index.GetNote("definitions")
+	if !reflect.DeepEqual(definitions.EmbeddedIn, []string{"lecture"}) {
+		t.Errorf("expected definitions to be embedded in lecture, got %v", definitions.EmbeddedIn)
+	}
+	if len(definitions.Backlinks) != 0 {
+		t.Errorf("embeds should not count as backlinks, got %v", definitions.Backlinks)
+	}
+	if !reflect.DeepEqual(definitions.Labels, []string{"def:graph"}) {
+		t.Errorf("expected labels to be indexed, got %v", definitions.Labels)
+	}
+}
+
+func TestIndexer_Incremental(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := mocks.NewMockRepository()
+	save := func(title, content string) *domain.NoteBody {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		note := domain.NewNoteBody(header, content)
+		mockRepo.Save(ctx, note)
+		return note
+	}
+
+	save("Alpha", "alpha")
+	save("Beta", "\\ref{alpha}")
+	gamma := save("Gamma", "gamma")
+
+	indexer := NewIndexerService(mockRepo, filepath.Join(t.TempDir(), "index.json"))
+	first, err := indexer.Execute(ctx, ReindexRequest{})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if first.Updated != 3 {
+		t.Fatalf("expected first run to index all notes, got %d", first.Updated)
+	}
+
+	// Nothing changed: nothing is reprocessed
+	second, _ := indexer.Execute(ctx, ReindexRequest{})
+	if second.Updated != 0 || second.Removed != 0 {
+		t.Errorf("expected no work, got updated=%d removed=%d", second.Updated, second.Removed)
+	}
+
+	// Gamma starts linking to alpha; beta is deleted
+	gamma.Content = "\\ref{alpha}"
+	mockRepo.Save(ctx, gamma)
+	mockRepo.Delete(ctx, "beta")
+
+	third, _ := indexer.Execute(ctx, ReindexRequest{Paths: []string{"notes/gamma.tex", "notes/beta.tex"}})
+	if third.Updated != 1 || third.Removed != 1 || third.TotalNotes != 2 {
+		t.Errorf("expected 1 update and 1 removal, got %+v", third)
+	}
+
+	index, _ := indexer.LoadIndex()
+	alpha, _ := index.GetNote("alpha")
+	if !reflect.DeepEqual(alpha.Backlinks, []string{"gamma"}) {
+		t.Errorf("expected alpha's backlinks to follow the change, got %v", alpha.Backlinks)
+	}
+
+	// Saving the same content only refreshes the mtime
+	mockRepo.Save(ctx, gamma)
+	fourth, _ := indexer.Execute(ctx, ReindexRequest{})
+	if fourth.Updated != 0 {
+		t.Errorf("expected unchanged content to be skipped, got %d updates", fourth.Updated)
+	}
+}
+
+func TestIndexer_LinksNamingTheVaultAreLocal(t *testing.T) {
+	mockRepo := mocks.NewMockRepository()
+	notes := map[string]string{
+		"Definitions": "\\section{Graphs}",
+		"Lecture":     "\\lxembed{home:definitions} \\lxnote{research:definitions}",
+	}
+	for title, content := range notes {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+	}
+
+	indexer := NewIndexerService(mockRepo, filepath.Join(t.TempDir(), "index.json"))
+	indexer.SetVaultName("home")
+	if _, err := indexer.Execute(context.Background(), ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	index, err := indexer.LoadIndex()
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+
+	lecture, _ := index.GetNote("lecture")
+	if !reflect.DeepEqual(lecture.Embeds, []string{"definitions"}) {
+		t.Errorf("expected lecture to embed definitions, got %v", lecture.Embeds)
+	}
+	definitions, _ := index.GetNote("definitions")
+	if len(definitions.Backlinks) != 0 {
+		t.Errorf("links to another vault should not count as backlinks, got %v", definitions.Backlinks)
+	}
+}
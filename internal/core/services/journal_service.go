@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+)
+
+// JournalService undoes journaled operations and lists them
+type JournalService struct {
+	journal ports.Journal
+}
+
+// NewJournalService creates a new journal service
+func NewJournalService(journal ports.Journal) *JournalService {
+	return &JournalService{journal: journal}
+}
+
+// Undo reverts the most recent operation that has not been undone yet
+// Unless force is set, it refuses when a file changed since the operation,
+// as undoing would throw those changes away
+func (s *JournalService) Undo(ctx context.Context, force bool) (*domain.Operation, error) {
+	// 1. Find the operation
+	ops, err := s.journal.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	op, ok := domain.LastUndoable(ops)
+	if !ok {
+		return nil, fmt.Errorf("nothing to undo")
+	}
+
+	// 2. Check nothing changed since
+	if !force {
+		modified, err := s.journal.Modified(ctx, op.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(modified) > 0 {
+			return nil, fmt.Errorf("changed since '%s': %s (use --force to undo anyway)",
+				op.Description(), strings.Join(modified, ", "))
+		}
+	}
+
+	// 3. Revert
+	reverted, err := s.journal.Revert(ctx, op.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo '%s': %w", op.Description(), err)
+	}
+	return reverted, nil
+}
+
+// History returns the most recent operations, newest first
+// A limit of 0 returns every recorded operation
+func (s *JournalService) History(ctx context.Context, limit int) ([]domain.Operation, error) {
+	ops, err := s.journal.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if limit > 0 && len(ops) > limit {
+		ops = ops[:limit]
+	}
+	return ops, nil
+}
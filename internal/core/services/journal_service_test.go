@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+func recordOperation(journal *mocks.MockJournal, command string, paths ...string) {
+	journal.Begin(command, nil)
+	journal.Capture(paths...)
+	journal.Commit()
+}
+
+func TestJournalService_Undo(t *testing.T) {
+	ctx := context.Background()
+	journal := mocks.NewMockJournal()
+	recordOperation(journal, "lx new", "notes/a.tex")
+	recordOperation(journal, "lx tag rename", "notes/a.tex", "notes/b.tex")
+
+	svc := NewJournalService(journal)
+
+	// Each undo walks one operation further back
+	op, err := svc.Undo(ctx, false)
+	if err != nil || op.Command != "lx tag rename" {
+		t.Fatalf("expected to undo the tag rename, got %v (%v)", op, err)
+	}
+	op, err = svc.Undo(ctx, false)
+	if err != nil || op.Command != "lx new" {
+		t.Fatalf("expected to undo the new note, got %v (%v)", op, err)
+	}
+	if _, err := svc.Undo(ctx, false); err == nil {
+		t.Error("expected nothing left to undo")
+	}
+
+	if got := journal.GetReverted(); !reflect.DeepEqual(got, []string{"op-2", "op-1"}) {
+		t.Errorf("unexpected reverts: %v", got)
+	}
+}
+
+func TestJournalService_UndoRefusesChangedFiles(t *testing.T) {
+	ctx := context.Background()
+	journal := mocks.NewMockJournal()
+	recordOperation(journal, "lx migrate", "notes/a.tex", "notes/b.tex")
+	journal.SetModified("notes/b.tex")
+
+	svc := NewJournalService(journal)
+
+	_, err := svc.Undo(ctx, false)
+	if err == nil || !strings.Contains(err.Error(), "notes/b.tex") {
+		t.Fatalf("expected the changed file to block the undo, got %v", err)
+	}
+	if len(journal.GetReverted()) != 0 {
+		t.Error("expected nothing to be reverted")
+	}
+
+	if _, err := svc.Undo(ctx, true); err != nil {
+		t.Fatalf("expected --force to undo anyway: %v", err)
+	}
+}
+
+func TestJournalService_History(t *testing.T) {
+	ctx := context.Background()
+	journal := mocks.NewMockJournal()
+	recordOperation(journal, "lx new", "notes/a.tex")
+	recordOperation(journal, "lx move", "notes/a.tex", "notes/x/a.tex")
+	recordOperation(journal, "lx list") // Changed nothing, so it is not recorded
+
+	svc := NewJournalService(journal)
+
+	ops, err := svc.History(ctx, 0)
+	if err != nil || len(ops) != 2 || ops[0].Command != "lx move" {
+		t.Fatalf("expected two operations, newest first, got %v (%v)", ops, err)
+	}
+	if ops, _ := svc.History(ctx, 1); len(ops) != 1 {
+		t.Errorf("expected the limit to apply, got %d operations", len(ops))
+	}
+}
@@ -13,7 +13,9 @@ import (
 
 // ListService handles listing and filtering notes
 type ListService struct {
-	noteRepo ports.Repository
+	noteRepo      ports.Repository
+	indexer       *IndexerService
+	savedSearches map[string]string
 }
 
 // NewListService creates a new list service
@@ -23,9 +25,22 @@ func NewListService(noteRepo ports.Repository) *ListService {
 	}
 }
 
+// SetIndexer sets the indexer whose index structured queries are evaluated against
+// Without one, queries only see the note headers (no links or todos)
+func (s *ListService) SetIndexer(indexer *IndexerService) {
+	s.indexer = indexer
+}
+
+// SetSavedSearches sets the saved searches that queries can refer to as @name
+func (s *ListService) SetSavedSearches(saved map[string]string) {
+	s.savedSearches = saved
+}
+
 // ListRequest represents a request to list notes
 type ListRequest struct {
+	Query     string // Structured query, see domain.ParseQuery (optional)
 	TagFilter string // Filter by specific tag (optional)
+	Notebook  string // Filter by notebook, including notebooks nested in it (optional)
 	SortBy    string // "date", "title" (default: date)
 	Reverse   bool   // Reverse sort order
 }
@@ -49,6 +64,23 @@ func (s *ListService) Execute(ctx context.Context, req ListRequest) (*ListRespon
 		headers = s.filterByTag(headers, req.TagFilter)
 	}
 
+	// Apply notebook filter if specified
+	if req.Notebook != "" {
+		headers = s.filterByNotebook(headers, req.Notebook)
+	}
+
+	// Apply query if specified
+	if strings.TrimSpace(req.Query) != "" {
+		query, err := s.parseQuery(req.Query)
+		if err != nil {
+			return nil, err
+		}
+		headers, err = s.filterByQuery(ctx, headers, query)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Sort
 	headers = s.sortHeaders(headers, req.SortBy, req.Reverse)
 
@@ -58,19 +90,87 @@ func (s *ListService) Execute(ctx context.Context, req ListRequest) (*ListRespon
 	}, nil
 }
 
+// filterByTag keeps notes with the tag or a tag nested under it
 func (s *ListService) filterByTag(headers []domain.NoteHeader, tag string) []domain.NoteHeader {
 	var filtered []domain.NoteHeader
 	for _, header := range headers {
-		for _, t := range header.Tags {
-			if strings.EqualFold(t, tag) {
-				filtered = append(filtered, header)
-				break
-			}
+		if header.HasTag(tag) {
+			filtered = append(filtered, header)
+		}
+	}
+	return filtered
+}
+
+// filterByNotebook keeps notes in the notebook or a notebook nested in it
+func (s *ListService) filterByNotebook(headers []domain.NoteHeader, notebook string) []domain.NoteHeader {
+	var filtered []domain.NoteHeader
+	for _, header := range headers {
+		if header.InNotebook(notebook) {
+			filtered = append(filtered, header)
 		}
 	}
 	return filtered
 }
 
+// parseQuery expands saved searches and parses the result
+func (s *ListService) parseQuery(input string) (*domain.Query, error) {
+	expanded, err := domain.ExpandSavedSearches(input, s.savedSearches)
+	if err != nil {
+		return nil, err
+	}
+	return domain.ParseQuery(expanded)
+}
+
+// filterByQuery keeps the notes matching a parsed query
+func (s *ListService) filterByQuery(ctx context.Context, headers []domain.NoteHeader, query *domain.Query) ([]domain.NoteHeader, error) {
+	index, err := s.loadIndex(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []domain.NoteHeader
+	for _, header := range headers {
+		// Headers are fresher than the index for metadata; the index adds connections
+		entry, _ := index.GetNote(header.Slug)
+		entry.Title = header.Title
+		entry.Date = header.Date
+		entry.Tags = header.Tags
+
+		if query.Match(header.Slug, entry) {
+			filtered = append(filtered, header)
+		}
+	}
+	return filtered, nil
+}
+
+// loadIndex returns the index, first updating it if any note changed since it was written
+func (s *ListService) loadIndex(ctx context.Context, headers []domain.NoteHeader) (*domain.Index, error) {
+	if s.indexer == nil {
+		return domain.NewIndex(), nil
+	}
+
+	index, err := s.indexer.LoadIndex()
+	if err == nil && index.Version == domain.IndexVersion && indexCurrent(index, headers) {
+		return index, nil
+	}
+
+	if _, err := s.indexer.Execute(ctx, ReindexRequest{}); err != nil {
+		return nil, fmt.Errorf("failed to update index: %w", err)
+	}
+	return s.indexer.LoadIndex()
+}
+
+// indexCurrent reports whether every note is indexed at its current modification time
+func indexCurrent(index *domain.Index, headers []domain.NoteHeader) bool {
+	for _, header := range headers {
+		entry, exists := index.GetNote(header.Slug)
+		if !exists || !entry.ModTime.Equal(header.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *ListService) sortHeaders(headers []domain.NoteHeader, sortBy string, reverse bool) []domain.NoteHeader {
 	sort.Slice(headers, func(i, j int) bool {
 		var less bool
@@ -99,7 +199,9 @@ type SearchResponse struct {
 	Total int
 }
 
-// Search performs fuzzy search on notes
+// Search finds notes matching a query
+// Plain text is fuzzy matched against titles, slugs and tags; queries with
+// fields or operators (tag:physics -tag:draft) are evaluated as filters
 func (s *ListService) Search(ctx context.Context, req SearchRequest) (*SearchResponse, error) {
 	// Get all note headers
 	headers, err := s.noteRepo.ListHeaders(ctx)
@@ -115,6 +217,23 @@ func (s *ListService) Search(ctx context.Context, req SearchRequest) (*SearchRes
 		}, nil
 	}
 
+	// Saved searches must exist, unlike the fields of free text that merely looks like a query
+	if _, err := domain.ExpandSavedSearches(req.Query, s.savedSearches); err != nil {
+		return nil, err
+	}
+
+	// Structured queries filter; anything else, including text that does not parse, is fuzzy matched
+	if query, err := s.parseQuery(req.Query); err == nil && !query.IsPlainText() {
+		matches, err := s.filterByQuery(ctx, headers, query)
+		if err != nil {
+			return nil, err
+		}
+		return &SearchResponse{
+			Notes: matches,
+			Total: len(matches),
+		}, nil
+	}
+
 	// Filter by query with fuzzy matching
 	matches := s.fuzzySearch(headers, req.Query)
 
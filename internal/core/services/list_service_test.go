@@ -2,6 +2,9 @@ package services
 
 import (
 	"context"
+	"path"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -584,3 +587,171 @@ func TestFuzzySearch_CaseInsensitive(t *testing.T) {
 		}
 	}
 }
+
+func TestListService_Query(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockRepository()
+	save := func(title string, tags []string, content string) {
+		header, _ := domain.NewNoteHeader(title, tags, "")
+		repo.Save(ctx, domain.NewNoteBody(header, content))
+	}
+
+	save("Linear Algebra", []string{"math"}, "vectors")
+	save("Graph Theory", []string{"math", "draft"}, "\\lxnote{linear-algebra}{see} \\todo{prove}")
+	save("Mechanics", []string{"physics"}, "forces")
+
+	svc := NewListService(repo)
+	svc.SetIndexer(NewIndexerService(repo, filepath.Join(t.TempDir(), "index.json")))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"tag:math -tag:draft", []string{"linear-algebra"}},
+		{"links-to:linear-algebra", []string{"graph-theory"}},
+		{"has:todo", []string{"graph-theory"}},
+		{"orphan:true", []string{"mechanics"}},
+		{"tag:physics OR title:graph", []string{"graph-theory", "mechanics"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			resp, err := svc.Execute(ctx, ListRequest{Query: tt.query, SortBy: "title"})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			var slugs []string
+			for _, note := range resp.Notes {
+				slugs = append(slugs, note.Slug)
+			}
+			if !reflect.DeepEqual(slugs, tt.want) {
+				t.Errorf("query %q: got %v, want %v", tt.query, slugs, tt.want)
+			}
+		})
+	}
+
+	if _, err := svc.Execute(ctx, ListRequest{Query: "colour:red"}); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	// Search filters structured queries and falls back to fuzzy matching otherwise
+	resp, _ := svc.Search(ctx, SearchRequest{Query: "has:todo"})
+	if resp.Total != 1 || resp.Notes[0].Slug != "graph-theory" {
+		t.Errorf("expected structured search to filter, got %v", resp.Notes)
+	}
+	if _, err := svc.Search(ctx, SearchRequest{Query: "Mechanics: intro"}); err != nil {
+		t.Errorf("expected unparsable text to be fuzzy matched, got %v", err)
+	}
+}
+
+func TestListService_SavedSearches(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockRepository()
+	for _, title := range []string{"Linear Algebra", "Group Theory", "Mechanics"} {
+		tags := []string{"math"}
+		if title == "Mechanics" {
+			tags = []string{"physics"}
+		}
+		header, _ := domain.NewNoteHeader(title, tags, "")
+		repo.Save(ctx, domain.NewNoteBody(header, "content"))
+	}
+
+	svc := NewListService(repo)
+	svc.SetIndexer(NewIndexerService(repo, filepath.Join(t.TempDir(), "index.json")))
+	svc.SetSavedSearches(map[string]string{
+		"math":    "tag:math",
+		"algebra": "@math title:algebra",
+	})
+
+	resp, err := svc.Execute(ctx, ListRequest{Query: "@algebra", SortBy: "title"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if resp.Total != 1 || resp.Notes[0].Slug != "linear-algebra" {
+		t.Errorf("expected @algebra to match linear-algebra, got %v", resp.Notes)
+	}
+
+	searchResp, err := svc.Search(ctx, SearchRequest{Query: "@math -title:group"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if searchResp.Total != 1 || searchResp.Notes[0].Slug != "linear-algebra" {
+		t.Errorf("expected @math -title:group to match linear-algebra, got %v", searchResp.Notes)
+	}
+
+	if _, err := svc.Execute(ctx, ListRequest{Query: "@missing"}); err == nil {
+		t.Error("expected an error for an unknown saved search")
+	}
+}
+
+func TestListService_NestedTags(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockRepository()
+	for title, tags := range map[string][]string{
+		"Groups":  {"math/algebra/groups"},
+		"Limits":  {"math/analysis"},
+		"Optics":  {"physics"},
+		"Numbers": {"math"},
+	} {
+		header, _ := domain.NewNoteHeader(title, tags, "")
+		repo.Save(ctx, domain.NewNoteBody(header, "content"))
+	}
+
+	svc := NewListService(repo)
+	svc.SetIndexer(NewIndexerService(repo, filepath.Join(t.TempDir(), "index.json")))
+
+	for _, req := range []ListRequest{
+		{TagFilter: "math/algebra", SortBy: "title"},
+		{Query: "tag:math/algebra", SortBy: "title"},
+	} {
+		resp, err := svc.Execute(ctx, req)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if resp.Total != 1 || resp.Notes[0].Slug != "groups" {
+			t.Errorf("%+v: expected only groups, got %v", req, resp.Notes)
+		}
+	}
+
+	resp, _ := svc.Execute(ctx, ListRequest{TagFilter: "Math"})
+	if resp.Total != 3 {
+		t.Errorf("expected a parent tag to include its children, got %d notes", resp.Total)
+	}
+}
+
+func TestListService_Notebook(t *testing.T) {
+	ctx := context.Background()
+	repo := mocks.NewMockRepository()
+	for title, notebook := range map[string]string{
+		"Limits":    "courses/math201",
+		"Integrals": "courses/math202",
+		"Optics":    "physics",
+		"Inbox":     "",
+	} {
+		header, _ := domain.NewNoteHeader(title, nil, "")
+		header.Notebook = notebook
+		header.Filename = path.Join(notebook, header.Filename)
+		repo.Save(ctx, domain.NewNoteBody(header, "content"))
+	}
+
+	svc := NewListService(repo)
+	svc.SetIndexer(NewIndexerService(repo, filepath.Join(t.TempDir(), "index.json")))
+
+	for _, req := range []ListRequest{
+		{Notebook: "courses", SortBy: "title"},
+		{Query: "notebook:courses", SortBy: "title"},
+	} {
+		resp, err := svc.Execute(ctx, req)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if resp.Total != 2 || resp.Notes[0].Slug != "integrals" || resp.Notes[1].Slug != "limits" {
+			t.Errorf("%+v: expected integrals and limits, got %v", req, resp.Notes)
+		}
+	}
+
+	resp, _ := svc.Execute(ctx, ListRequest{Notebook: "courses/math201/"})
+	if resp.Total != 1 || resp.Notes[0].Slug != "limits" {
+		t.Errorf("expected only limits, got %v", resp.Notes)
+	}
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// MathService maintains the on-disk index of formulas and finds formulas in it
+type MathService struct {
+	indexPath string
+}
+
+// NewMathService creates a new math search service
+func NewMathService(indexPath string) *MathService {
+	return &MathService{
+		indexPath: indexPath,
+	}
+}
+
+// MathRequest is a formula to look for
+type MathRequest struct {
+	Query string // LaTeX math, matched after normalization anywhere inside a formula
+	Limit int    // 0 means no limit
+}
+
+// MathMatch is a formula containing the query
+type MathMatch struct {
+	Slug  string
+	Line  int
+	Env   string
+	Label string
+	Text  string
+}
+
+// Update reindexes the formulas of the given notes and drops removed ones
+// With rebuild, the existing index is discarded first
+func (s *MathService) Update(notes []*domain.NoteBody, removed []string, rebuild bool) error {
+	index := domain.NewMathIndex()
+	if !rebuild {
+		existing, err := s.LoadIndex()
+		if err != nil {
+			return err
+		}
+		index = existing
+	}
+
+	for _, slug := range removed {
+		index.RemoveNote(slug)
+	}
+	for _, note := range notes {
+		index.SetNote(note.Header.Slug, extractMath(note.Content))
+	}
+
+	index.UpdateLastIndexed()
+	return s.saveIndex(index)
+}
+
+// Stale reports whether the index is missing or was written by another version
+func (s *MathService) Stale() bool {
+	if _, err := os.Stat(s.indexPath); err != nil {
+		return true
+	}
+	index, err := s.LoadIndex()
+	return err != nil || index.Version != domain.MathIndexVersion || index.Notes == nil
+}
+
+// Search returns the formulas containing the query, by note and line
+func (s *MathService) Search(ctx context.Context, req MathRequest) ([]MathMatch, error) {
+	query := latexscan.NormalizeMath(req.Query)
+	if query == "" {
+		return nil, fmt.Errorf("math query is empty")
+	}
+
+	index, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(index.Notes))
+	for slug := range index.Notes {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	var matches []MathMatch
+	for _, slug := range slugs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for _, entry := range index.Notes[slug] {
+			if !strings.Contains(entry.Normalized, query) {
+				continue
+			}
+			matches = append(matches, MathMatch{
+				Slug:  slug,
+				Line:  entry.Line,
+				Env:   entry.Env,
+				Label: entry.Label,
+				Text:  entry.Text,
+			})
+			if req.Limit > 0 && len(matches) == req.Limit {
+				return matches, nil
+			}
+		}
+	}
+
+	return matches, nil
+}
+
+// extractMath returns the formulas of a note
+func extractMath(content string) []domain.MathEntry {
+	var entries []domain.MathEntry
+	for _, block := range latexscan.MathBlocks(content) {
+		normalized := latexscan.NormalizeMath(block.Text)
+		if normalized == "" {
+			continue
+		}
+		entries = append(entries, domain.MathEntry{
+			Line:       block.Line,
+			Env:        block.Env,
+			Label:      block.Label,
+			Text:       strings.Join(strings.Fields(block.Text), " "),
+			Normalized: normalized,
+		})
+	}
+	return entries
+}
+
+func (s *MathService) saveIndex(index *domain.MathIndex) error {
+	if err := os.MkdirAll(filepath.Dir(s.indexPath), 0755); err != nil {
+		return fmt.Errorf("failed to create math index directory: %w", err)
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal math index: %w", err)
+	}
+
+	return os.WriteFile(s.indexPath, data, 0644)
+}
+
+// LoadIndex reads the math index, returning an empty one if it does not exist yet
+func (s *MathService) LoadIndex() (*domain.MathIndex, error) {
+	data, err := os.ReadFile(s.indexPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.NewMathIndex(), nil
+		}
+		return nil, fmt.Errorf("failed to read math index: %w", err)
+	}
+
+	var index domain.MathIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal math index: %w", err)
+	}
+
+	return &index, nil
+}
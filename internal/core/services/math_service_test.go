@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMathService_Search(t *testing.T) {
+	mockRepo, indexer, _ := setupSearch(t, map[string]string{
+		"Maxwell":  "\\begin{equation}\n  \\nabla \\times \\mathbf{E} = 0 \\label{eq:curl}\n\\end{equation}",
+		"Integral": "We have $\\int_{0}^{\\infty} e^{-x}\\,dx = 1$ and $a \\le b$.",
+		"Prose":    "No math here, just a \\$5 price.",
+	})
+	dir := filepath.Dir(indexer.indexPath)
+	math := NewMathService(filepath.Join(dir, MathIndexFile))
+
+	tests := []struct {
+		query string
+		want  []MathMatch
+	}{
+		{`\nabla\times`, []MathMatch{{Slug: "maxwell", Line: 2, Env: "equation", Label: "eq:curl", Text: `\nabla \times \mathbf{E} = 0 \label{eq:curl}`}}},
+		{`\int_0^\infty`, []MathMatch{{Slug: "integral", Line: 1, Env: "$", Text: `\int_{0}^{\infty} e^{-x}\,dx = 1`}}},
+		{`a \leq b`, []MathMatch{{Slug: "integral", Line: 1, Env: "$", Text: `a \le b`}}},
+		{`\sum`, nil},
+	}
+
+	for _, tt := range tests {
+		matches, err := math.Search(context.Background(), MathRequest{Query: tt.query})
+		if err != nil {
+			t.Fatalf("Search(%q) failed: %v", tt.query, err)
+		}
+		if len(matches) != len(tt.want) {
+			t.Errorf("Search(%q) = %+v, want %+v", tt.query, matches, tt.want)
+			continue
+		}
+		for i := range matches {
+			if matches[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %+v, want %+v", tt.query, matches[i], tt.want[i])
+			}
+		}
+	}
+
+	if _, err := math.Search(context.Background(), MathRequest{Query: "  "}); err == nil {
+		t.Error("expected an error for an empty query")
+	}
+
+	// Removing a note drops its formulas on the next reindex
+	mockRepo.Delete(context.Background(), "maxwell")
+	if _, err := indexer.Execute(context.Background(), ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if matches, _ := math.Search(context.Background(), MathRequest{Query: `\nabla`}); len(matches) != 0 {
+		t.Errorf("expected removed note to be dropped, got %+v", matches)
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
 	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
 	"github.com/kamal-hamza/lx-cli/pkg/vault"
 )
 
@@ -18,8 +21,12 @@ type Preprocessor struct {
 	vault               *vault.Vault
 	enableCache         bool
 	cacheExpirationMins int
+	openVault           VaultOpener
 }
 
+// VaultOpener opens another vault by name, for \lxnote{vault:slug} links
+type VaultOpener func(name string) (*vault.Vault, ports.Repository, error)
+
 func NewPreprocessor(repo ports.Repository, v *vault.Vault, enableCache bool, cacheExpirationMins int) *Preprocessor {
 	return &Preprocessor{
 		repo:                repo,
@@ -29,6 +36,12 @@ func NewPreprocessor(repo ports.Repository, v *vault.Vault, enableCache bool, ca
 	}
 }
 
+// SetVaultOpener enables links to notes in other vaults
+// Without it, \lxnote{vault:slug} is rendered as a broken link
+func (p *Preprocessor) SetVaultOpener(open VaultOpener) {
+	p.openVault = open
+}
+
 // Process creates a temporary compilable version of the note with resolved links
 // Returns the absolute path to the preprocessed file in the cache
 func (p *Preprocessor) Process(slug string) (string, error) {
@@ -65,43 +78,89 @@ func (p *Preprocessor) Process(slug string) (string, error) {
 	}
 
 	// 3. Process Content
-	content = p.resolveReferences(content, slugMap)
+	// Embeds are expanded first; every line remembers where it came from
+	// The rewrites after that are all inline, so they keep the line structure
+	sourceFile := filepath.ToSlash(filepath.Join(filepath.Base(p.vault.NotesPath), note.Header.Filename))
+	lines := p.expandEmbeds(content, sourceFile, 1, []string{slug})
+	content = joinLines(lines)
+	content = p.resolveReferences(content, slug, slugMap, p.labelLookup(), p.crossVaultLinks(slug))
 	content = p.resolveInputs(content)
 	content = p.resolveGraphics(content)
-	content = p.ensureHyperref(content)
+	content, injectedLine := p.ensureHyperref(content)
+	content = p.ensureNamedDestinations(content)
 
 	// 4. Write to Cache
 	// We write to the cache directory so we don't clutter the notes folder
-
+	// Qualified notebook/slug notes get a subdirectory, so their PDFs cannot collide
+	if err := os.MkdirAll(filepath.Dir(tempPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
 	if err := os.WriteFile(tempPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write preprocessed file: %w", err)
 	}
 
+	// 5. Write the line map so compiler issues can point at the original note
+	lineMap := buildLineMap(tempPath, lines, injectedLine)
+	if err := lineMap.Save(latexparser.LineMapPath(tempPath)); err != nil {
+		return "", err
+	}
+
 	return tempPath, nil
 }
 
-// resolveReferences converts \lxnote{slug} and \ref{slug} (deprecated) to \href{./slug.pdf}{Title}
-func (p *Preprocessor) resolveReferences(content string, slugMap map[string]string) string {
-	// Primary: \lxnote[optional text]{slug} or \lxnote{slug}
-	// Regex explanation:
-	//   \\lxnote       : Matches literal "\lxnote"
-	//   (?:\[(.*?)\])? : Non-capturing group for optional [text].
-	//                    (.*?) captures the content lazily into submatch 1.
-	//   \{([^}]+)\}    : Matches {slug}. Captures slug into submatch 2.
-	lxnoteRegex := regexp.MustCompile(`\\lxnote(?:\[(.*?)\])?\{([^}]+)\}`)
+// buildLineMap maps the preprocessed file back to the note and the notes it embeds
+// injectedLine is the generated line holding the injected hyperref (0 if none)
+func buildLineMap(generatedPath string, lines []sourceLine, injectedLine int) *latexparser.LineMap {
+	lineMap := latexparser.NewLineMap(generatedPath)
+
+	for i, l := range lines {
+		if i+1 == injectedLine {
+			lineMap.Append(1, "", 0)
+		}
+		lineMap.Append(1, l.file, l.line)
+	}
+	if injectedLine > len(lines) {
+		lineMap.Append(1, "", 0)
+	}
 
-	content = lxnoteRegex.ReplaceAllStringFunc(content, func(match string) string {
-		submatches := lxnoteRegex.FindStringSubmatch(match)
-		// submatches[0] = full match
-		// submatches[1] = optional text (might be empty)
-		// submatches[2] = slug
+	return lineMap
+}
 
-		if len(submatches) < 3 {
-			return match
+// labelLookup returns a function reporting the labels a note defines
+// Notes are loaded lazily and at most once per preprocessing run
+func (p *Preprocessor) labelLookup() func(slug string) map[string]bool {
+	cache := make(map[string]map[string]bool)
+	return func(slug string) map[string]bool {
+		if labels, ok := cache[slug]; ok {
+			return labels
+		}
+		labels := make(map[string]bool)
+		if note, err := p.repo.Get(context.Background(), slug); err == nil {
+			for _, label := range domain.ExtractLabels(note.Content) {
+				labels[label] = true
+			}
 		}
+		cache[slug] = labels
+		return labels
+	}
+}
 
-		customText := strings.TrimSpace(submatches[1])
-		targetSlug := strings.TrimSpace(submatches[2])
+// resolveReferences converts \lxnote{slug} and \ref{slug} (deprecated) to \href{./slug.pdf}{Title}
+// \lxnote{slug#label} links to the named destination of \label{label} inside the target note
+// Commands in comments and verbatim environments are left alone
+// \lxnote{vault:slug} links to the PDF of a note in another vault
+func (p *Preprocessor) resolveReferences(content string, sourceSlug string, slugMap map[string]string, labelsOf func(slug string) map[string]bool, crossVault func(target domain.NoteTarget, customText string) string) string {
+	// Primary: \lxnote[optional text]{slug} or \lxnote{slug}
+	content = latexscan.ReplaceCommands(content, func(inv latexscan.Invocation) string {
+		customText := ""
+		if len(inv.Optional) > 0 {
+			customText = strings.TrimSpace(inv.Optional[0].Text)
+		}
+		target, local := domain.ParseNoteTarget(inv.Arg.Text).Local(p.vault.Name)
+		if !local {
+			return crossVault(target, customText)
+		}
+		targetSlug := target.Slug
 
 		// 1. Resolve Target Title
 		targetTitle, exists := slugMap[targetSlug]
@@ -109,6 +168,11 @@ func (p *Preprocessor) resolveReferences(content string, slugMap map[string]stri
 			return fmt.Sprintf(`\textbf{[BROKEN LINK: %s]}`, targetSlug)
 		}
 
+		// The anchor must be a label defined in the target note
+		if target.Label != "" && !labelsOf(targetSlug)[target.Label] {
+			return fmt.Sprintf(`\textbf{[BROKEN ANCHOR: %s\#\detokenize{%s}]}`, targetSlug, target.Label)
+		}
+
 		// 2. Determine Display Text
 		// If user provided [custom text], use it. Otherwise, use the note's Title.
 		displayText := targetTitle
@@ -118,49 +182,113 @@ func (p *Preprocessor) resolveReferences(content string, slugMap map[string]stri
 
 		// 3. Generate Hyperref
 		// We use relative paths so it works in the PDF viewer
-		return fmt.Sprintf(`\href{./%s.pdf}{%s}`, targetSlug, displayText)
-	})
+		// Anchors use the named destination the target's \label produces (see ensureNamedDestinations)
+		if target.Label != "" {
+			return fmt.Sprintf(`\href{%s\#%s}{%s}`, pdfLink(sourceSlug, targetSlug), target.Label, displayText)
+		}
+		return fmt.Sprintf(`\href{%s}{%s}`, pdfLink(sourceSlug, targetSlug), displayText)
+	}, "lxnote")
 
 	// Legacy: \ref{slug} - deprecated, only converts if it matches a note slug
 	// This provides backward compatibility but will be removed in a future version
-	refRegex := regexp.MustCompile(`\\ref\{([^}]+)\}`)
-	content = refRegex.ReplaceAllStringFunc(content, func(match string) string {
-		submatch := refRegex.FindStringSubmatch(match)
-		if len(submatch) < 2 {
-			return match
-		}
-		targetSlug := submatch[1]
+	content = latexscan.ReplaceCommands(content, func(inv latexscan.Invocation) string {
+		targetSlug := inv.Arg.Text
 
 		// Check if the reference target matches a known note slug
 		if title, exists := slugMap[targetSlug]; exists {
 			// It's a note! Replace with clickable PDF link
 			// We use relative paths ./slug.pdf so the links work in the PDF viewer
-			return fmt.Sprintf(`\href{./%s.pdf}{%s}`, targetSlug, title)
+			return fmt.Sprintf(`\href{%s}{%s}`, pdfLink(sourceSlug, targetSlug), title)
 		}
 
 		// It's not a note (likely a standard internal label like \label{fig:x}), leave it alone
-		return match
-	})
+		return inv.Source(content)
+	}, "ref")
 
 	return content
 }
 
+// crossVaultLinks returns a function rendering links to notes in other vaults
+// Each vault is opened at most once per preprocessing run
+func (p *Preprocessor) crossVaultLinks(sourceSlug string) func(target domain.NoteTarget, customText string) string {
+	type openedVault struct {
+		vault *vault.Vault
+		repo  ports.Repository
+		err   error
+	}
+	opened := make(map[string]openedVault)
+
+	return func(target domain.NoteTarget, customText string) string {
+		broken := fmt.Sprintf(`\textbf{[BROKEN LINK: %s:%s]}`, target.Vault, target.Slug)
+		if p.openVault == nil {
+			return broken
+		}
+
+		// 1. Find the note in the other vault
+		other, ok := opened[target.Vault]
+		if !ok {
+			v, repo, err := p.openVault(target.Vault)
+			other = openedVault{vault: v, repo: repo, err: err}
+			opened[target.Vault] = other
+		}
+		if other.err != nil {
+			return broken
+		}
+		note, err := other.repo.Get(context.Background(), target.Slug)
+		if err != nil {
+			return broken
+		}
+		if target.Label != "" && !slices.Contains(domain.ExtractLabels(note.Content), target.Label) {
+			return fmt.Sprintf(`\textbf{[BROKEN ANCHOR: %s:%s\#\detokenize{%s}]}`, target.Vault, target.Slug, target.Label)
+		}
+
+		// 2. Link to its PDF in the other vault's cache
+		displayText := note.Header.Title
+		if customText != "" {
+			displayText = customText
+		}
+		link := crossVaultPDFLink(filepath.Join(p.vault.CachePath, filepath.FromSlash(sourceSlug)), filepath.Join(other.vault.CachePath, filepath.FromSlash(note.Header.Slug)))
+		if target.Label != "" {
+			return fmt.Sprintf(`\href{%s\#%s}{%s}`, link, target.Label, displayText)
+		}
+		return fmt.Sprintf(`\href{%s}{%s}`, link, displayText)
+	}
+}
+
+// crossVaultPDFLink is the path to a PDF in another vault, relative to the linking PDF
+// Both paths are without the .pdf extension
+func crossVaultPDFLink(sourcePath, targetPath string) string {
+	rel, err := filepath.Rel(filepath.Dir(sourcePath), targetPath)
+	if err != nil {
+		// On another drive there is no relative path
+		return filepath.ToSlash(targetPath) + ".pdf"
+	}
+	return filepath.ToSlash(rel) + ".pdf"
+}
+
+// pdfLink is the path to the PDF of a note, relative to the PDF of the note linking to it
+// "./slug.pdf" for notes side by side in the cache
+func pdfLink(sourceSlug, targetSlug string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(sourceSlug)), filepath.FromSlash(targetSlug))
+	if err != nil {
+		rel = targetSlug
+	}
+	rel = filepath.ToSlash(rel) + ".pdf"
+	if strings.HasPrefix(rel, "../") {
+		return rel
+	}
+	return "./" + rel
+}
+
 // resolveInputs converts relative \input{...} paths to absolute paths
 func (p *Preprocessor) resolveInputs(content string) string {
 	// Matches \input{filename} or \include{filename}
-	inputRegex := regexp.MustCompile(`\\(input|include)\{([^}]+)\}`)
-
-	return inputRegex.ReplaceAllStringFunc(content, func(match string) string {
-		parts := inputRegex.FindStringSubmatch(match)
-		if len(parts) < 3 {
-			return match
-		}
-		command := parts[1]
-		path := parts[2]
+	return latexscan.ReplaceCommands(content, func(inv latexscan.Invocation) string {
+		path := inv.Arg.Text
 
 		// If path is already absolute, leave it
 		if filepath.IsAbs(path) {
-			return match
+			return inv.Source(content)
 		}
 
 		// Resolve relative path against the Notes directory
@@ -168,28 +296,19 @@ func (p *Preprocessor) resolveInputs(content string) string {
 		// Ensure we use forward slashes for LaTeX compatibility
 		absPath = filepath.ToSlash(absPath)
 
-		return fmt.Sprintf(`\%s{%s}`, command, absPath)
-	})
+		return fmt.Sprintf(`\%s{%s}`, inv.Name, absPath)
+	}, "input", "include")
 }
 
 // resolveGraphics fixes relative paths in \includegraphics
 func (p *Preprocessor) resolveGraphics(content string) string {
 	// Matches \includegraphics[options]{path}
-	// The optional argument [.*?] is lazy
-	imgRegex := regexp.MustCompile(`\\includegraphics(\[.*?\])?\{([^}]+)\}`)
-
-	return imgRegex.ReplaceAllStringFunc(content, func(match string) string {
-		parts := imgRegex.FindStringSubmatch(match)
-		if len(parts) < 3 {
-			return match
-		}
-
-		opts := parts[1] // e.g. [width=0.5\textwidth] or empty string
-		path := parts[2]
+	return latexscan.ReplaceCommands(content, func(inv latexscan.Invocation) string {
+		path := inv.Arg.Text
 
 		// If path is absolute, leave it
 		if filepath.IsAbs(path) {
-			return match
+			return inv.Source(content)
 		}
 
 		// If path is relative (starts with . or ..), resolve it against NotesPath
@@ -198,50 +317,66 @@ func (p *Preprocessor) resolveGraphics(content string) string {
 		if strings.HasPrefix(path, ".") {
 			absPath := filepath.Join(p.vault.NotesPath, path)
 			absPath = filepath.ToSlash(absPath)
-			return fmt.Sprintf(`\includegraphics%s{%s}`, opts, absPath)
+			// Keep the options (e.g. [width=0.5\textwidth]) as written
+			return content[inv.Start:inv.Arg.Start] + absPath + content[inv.Arg.End:inv.End]
 		}
 
 		// If just a filename, assume it might be in assets/
 		// (Optional: You could check if file exists in assets/ here)
 
-		return match
-	})
+		return inv.Source(content)
+	}, "includegraphics")
 }
 
-// ensureHyperref injects the hyperref package if missing
-func (p *Preprocessor) ensureHyperref(content string) string {
-	// Check if hyperref is already loaded in the note itself
-	if strings.Contains(content, "{hyperref}") {
+// ensureNamedDestinations makes hyperref name PDF destinations after \label names,
+// so other notes can link to "slug.pdf#label"
+// The setting is added on the \begin{document} line to keep the line structure
+func (p *Preprocessor) ensureNamedDestinations(content string) string {
+	if len(domain.ExtractLabels(content)) == 0 {
 		return content
 	}
 
-	// Check if any loaded templates contain hyperref
-	// Extract all \usepackage{...} statements
-	usepackageRegex := regexp.MustCompile(`\\usepackage\{([^}]+)\}`)
-	matches := usepackageRegex.FindAllStringSubmatch(content, -1)
+	for _, inv := range latexscan.FindCommands(content, "begin") {
+		if inv.Arg.Text == "document" {
+			return content[:inv.Start] + `\ifdefined\hypersetup\hypersetup{destlabel=true}\fi` + content[inv.Start:]
+		}
+	}
+
+	return content
+}
+
+// ensureHyperref injects the hyperref package if missing
+// It returns the new content and the 1-based line the package was injected on (0 if not injected)
+func (p *Preprocessor) ensureHyperref(content string) (string, int) {
+	// Check if hyperref is already loaded, by the note itself or by one of its templates
+	for _, inv := range latexscan.FindCommands(content, "usepackage") {
+		for _, packageName := range strings.Split(inv.Arg.Text, ",") {
+			packageName = strings.TrimSpace(packageName)
+			if packageName == "hyperref" {
+				return content, 0
+			}
 
-	for _, match := range matches {
-		if len(match) > 1 {
-			packageName := match[1]
 			// Check if this is a .sty file in the templates directory
 			templatePath := filepath.Join(p.vault.TemplatesPath, packageName+".sty")
 			if templateContent, err := os.ReadFile(templatePath); err == nil {
 				// If the template file contains hyperref, don't inject it
 				if strings.Contains(string(templateContent), "hyperref") {
-					return content
+					return content, 0
 				}
 			}
 		}
 	}
 
-	// Inject after \documentclass (with or without optional parameters)
-	docClassRegex := regexp.MustCompile(`(\\documentclass(?:\[.*?\])?\{.*?\})`)
+	hyperref := "\\usepackage[colorlinks=true,linkcolor=blue,urlcolor=blue,filecolor=blue]{hyperref}"
 
-	if docClassRegex.MatchString(content) {
+	// Inject after \documentclass (with or without optional parameters)
+	if classes := latexscan.FindCommands(content, "documentclass"); len(classes) > 0 {
 		// Add with standard options for nice links
-		return docClassRegex.ReplaceAllString(content, "$1\n\\usepackage[colorlinks=true,linkcolor=blue,urlcolor=blue,filecolor=blue]{hyperref}")
+		end := classes[0].End
+		line := strings.Count(content[:end], "\n") + 2
+		return content[:end] + "\n" + hyperref + content[end:], line
 	}
 
 	// Fallback: prepend to file
-	return "\\usepackage[colorlinks=true,linkcolor=blue,urlcolor=blue,filecolor=blue]{hyperref}\n" + content
+	return hyperref + "\n" + content, 1
 }
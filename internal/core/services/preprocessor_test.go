@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+	"github.com/kamal-hamza/lx-cli/pkg/vault"
+)
+
+// setupPreprocessor creates a temp vault with the given notes (title -> content)
+func setupPreprocessor(t *testing.T, notes map[string]string) *Preprocessor {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	v := &vault.Vault{
+		RootPath:      tempDir,
+		NotesPath:     filepath.Join(tempDir, "notes"),
+		TemplatesPath: filepath.Join(tempDir, "templates"),
+		AssetsPath:    filepath.Join(tempDir, "assets"),
+		CachePath:     filepath.Join(tempDir, "cache"),
+	}
+	if err := v.Initialize(); err != nil {
+		t.Fatalf("failed to initialize vault: %v", err)
+	}
+
+	mockRepo := mocks.NewMockRepository()
+	for title, content := range notes {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+	}
+
+	return NewPreprocessor(mockRepo, v, false, 0)
+}
+
+func processNote(t *testing.T, p *Preprocessor, slug string) string {
+	t.Helper()
+
+	path, err := p.Process(slug)
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read preprocessed file: %v", err)
+	}
+	return string(content)
+}
+
+func TestPreprocessor_ResolvesAnchoredLinks(t *testing.T) {
+	p := setupPreprocessor(t, map[string]string{
+		"Graph Theory": "\\begin{document}\n\\section{Euler}\\label{thm:euler}\n\\end{document}",
+		"Source":       "\\begin{document}\n\\lxnote{graph-theory#thm:euler}\n\\lxnote[see here]{graph-theory#thm:missing}\n\\end{document}",
+	})
+
+	source := processNote(t, p, "source")
+	if !strings.Contains(source, `\href{./graph-theory.pdf\#thm:euler}{Graph Theory}`) {
+		t.Errorf("expected anchored link, got:\n%s", source)
+	}
+	if !strings.Contains(source, `[BROKEN ANCHOR: graph-theory\#\detokenize{thm:missing}]`) {
+		t.Errorf("expected broken anchor marker, got:\n%s", source)
+	}
+
+	// The target names its destinations after its labels, without shifting lines
+	target := processNote(t, p, "graph-theory")
+	if !strings.Contains(target, `\hypersetup{destlabel=true}\fi\begin{document}`) {
+		t.Errorf("expected named destinations to be enabled, got:\n%s", target)
+	}
+	if strings.Contains(source, "destlabel") {
+		t.Error("notes without labels should not enable named destinations")
+	}
+}
+
+func TestPreprocessor_ExpandsEmbeds(t *testing.T) {
+	p := setupPreprocessor(t, map[string]string{
+		"Definitions": "\\documentclass{article}\n\\begin{document}\n\\begin{definition}\\label{def:graph}\nA graph is a pair.\n\\end{definition}\n\\section{Trees}\\label{sec:trees}\nA tree is acyclic.\n\\section{Other}\nUnrelated.\n\\end{document}",
+		"Source":      "\\documentclass{article}\n\\begin{document}\n\\lxembed{definitions#def:graph}\n\\lxembed{definitions#sec:trees}\n% \\lxembed{definitions}\n\\end{document}",
+	})
+
+	source := processNote(t, p, "source")
+	if !strings.Contains(source, "\\begin{definition}\\label{def:graph}\nA graph is a pair.\n\\end{definition}") {
+		t.Errorf("expected labelled environment to be embedded, got:\n%s", source)
+	}
+	if !strings.Contains(source, "A tree is acyclic.") || strings.Contains(source, "Unrelated.") {
+		t.Errorf("expected only the labelled section to be embedded, got:\n%s", source)
+	}
+	if !strings.Contains(source, "% \\lxembed{definitions}") {
+		t.Error("embeds in comments should be left alone")
+	}
+
+	// Errors in embedded lines point at the embedded note
+	lineMap, err := latexparser.LoadLineMap(latexparser.LineMapPath(filepath.Join(p.vault.CachePath, "source.tex")))
+	if err != nil {
+		t.Fatalf("failed to load line map: %v", err)
+	}
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if line == "A graph is a pair." {
+			file, sourceLine, ok := lineMap.Lookup(i + 1)
+			if !ok || !strings.HasSuffix(file, "definitions.tex") || sourceLine != 4 {
+				t.Errorf("expected embedded line to map to definitions.tex:4, got %s:%d", file, sourceLine)
+			}
+		}
+	}
+}
+
+func TestPreprocessor_EmbedCycles(t *testing.T) {
+	p := setupPreprocessor(t, map[string]string{
+		"Alpha": "\\begin{document}\nalpha \\lxembed{beta}\n\\end{document}",
+		"Beta":  "\\begin{document}\nbeta \\lxembed{alpha} \\lxembed{missing}\n\\end{document}",
+	})
+
+	alpha := processNote(t, p, "alpha")
+	if !strings.Contains(alpha, "beta") || !strings.Contains(alpha, `[EMBED CYCLE: alpha]`) {
+		t.Errorf("expected cycle to be cut, got:\n%s", alpha)
+	}
+	if !strings.Contains(alpha, `[BROKEN EMBED: missing]`) {
+		t.Errorf("expected broken embed marker, got:\n%s", alpha)
+	}
+}
+
+func TestPreprocessor_SkipsCommentsAndVerbatim(t *testing.T) {
+	listing := "\\begin{lstlisting}\n\\lxnote{graph-theory} \\input{chapter}\n\\end{lstlisting}"
+	p := setupPreprocessor(t, map[string]string{
+		"Graph Theory": "\\begin{document}\n\\end{document}",
+		"Source":       "\\begin{document}\n% \\lxnote{graph-theory}\n" + listing + "\n\\lxnote{graph-theory}\n\\end{document}",
+	})
+
+	source := processNote(t, p, "source")
+	if !strings.Contains(source, "% \\lxnote{graph-theory}") {
+		t.Errorf("expected commented link to be left alone, got:\n%s", source)
+	}
+	if !strings.Contains(source, listing) {
+		t.Errorf("expected listing to be left alone, got:\n%s", source)
+	}
+	if strings.Count(source, `\href{./graph-theory.pdf}{Graph Theory}`) != 1 {
+		t.Errorf("expected exactly one resolved link, got:\n%s", source)
+	}
+}
+
+func TestPreprocessor_CrossVaultLinks(t *testing.T) {
+	p := setupPreprocessor(t, map[string]string{
+		"Source": "\\begin{document}\n\\lxnote{research:graph-theory#thm:euler}\n\\lxnote[here]{research:graph-theory}\n\\lxnote{research:missing}\n\\lxnote{unknown:graph-theory}\n\\lxembed{research:graph-theory}\n\\end{document}",
+	})
+
+	// The other vault sits next to this one
+	otherRoot := filepath.Join(filepath.Dir(p.vault.RootPath), "research")
+	other := &vault.Vault{Name: "research", RootPath: otherRoot, CachePath: filepath.Join(otherRoot, "cache")}
+	otherRepo := mocks.NewMockRepository()
+	header, _ := domain.NewNoteHeader("Graph Theory", []string{}, "")
+	otherRepo.Save(context.Background(), domain.NewNoteBody(header, "\\section{Euler}\\label{thm:euler}"))
+
+	p.SetVaultOpener(func(name string) (*vault.Vault, ports.Repository, error) {
+		if name != "research" {
+			return nil, nil, fmt.Errorf("unknown vault: %s", name)
+		}
+		return other, otherRepo, nil
+	})
+
+	source := processNote(t, p, "source")
+	rel, _ := filepath.Rel(p.vault.CachePath, filepath.Join(other.CachePath, "graph-theory"))
+	link := filepath.ToSlash(rel) + ".pdf"
+
+	for _, want := range []string{
+		`\href{` + link + `\#thm:euler}{Graph Theory}`,
+		`\href{` + link + `}{here}`,
+		`[BROKEN LINK: research:missing]`,
+		`[BROKEN LINK: unknown:graph-theory]`,
+		`[EMBED FROM ANOTHER VAULT: research:graph-theory]`,
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("expected %s, got:\n%s", want, source)
+		}
+	}
+}
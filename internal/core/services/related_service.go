@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Weights of the three signals in the related score; they sum to 1
+const (
+	similarityWeight = 0.6
+	sharedTagsWeight = 0.2
+	coCitationWeight = 0.2
+)
+
+// stopWords are common English words that say nothing about a note's topic
+var stopWords = map[string]bool{
+	"the": true, "of": true, "and": true, "or": true, "an": true, "to": true, "in": true,
+	"on": true, "at": true, "by": true, "for": true, "with": true, "from": true, "as": true,
+	"is": true, "are": true, "be": true, "was": true, "were": true, "has": true, "have": true,
+	"it": true, "its": true, "this": true, "that": true, "these": true, "those": true,
+	"we": true, "if": true, "then": true, "so": true, "not": true, "no": true, "when": true,
+	"which": true, "every": true, "all": true, "any": true, "there": true, "can": true,
+}
+
+// minSuggestionSimilarity is the text similarity a note needs to be suggested as a link
+const minSuggestionSimilarity = 0.1
+
+// RelatedService ranks notes by how close their topics are, using the search and graph indexes
+type RelatedService struct {
+	indexer *IndexerService
+	search  *SearchService
+}
+
+// NewRelatedService creates a new related notes service
+func NewRelatedService(indexer *IndexerService, search *SearchService) *RelatedService {
+	return &RelatedService{
+		indexer: indexer,
+		search:  search,
+	}
+}
+
+// RelatedRequest asks for the notes related to one note
+type RelatedRequest struct {
+	Slug  string
+	Limit int // 0 means no limit
+}
+
+// RelatedNote is a note related to another, best first
+type RelatedNote struct {
+	Slug       string
+	Title      string
+	Score      float64  // Weighted combination of the signals below, from 0 to 1
+	Similarity float64  // TF-IDF cosine similarity of the note texts
+	SharedTags []string // Tags both notes have
+	CoCited    int      // Notes linking to both
+	Linked     bool     // The note already links to this one
+}
+
+// Related returns the notes closest to a note by text, tags and co-citation
+// The indexes are read as they are; callers reindex first if they need fresh results
+func (s *RelatedService) Related(ctx context.Context, req RelatedRequest) ([]RelatedNote, error) {
+	index, err := s.indexer.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+	source, exists := index.GetNote(req.Slug)
+	if !exists {
+		return nil, fmt.Errorf("note not found in index: %s", req.Slug)
+	}
+
+	// 1. Text similarity
+	similarity, err := s.similarities(req.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Co-citation: every note linking here also links to its other targets
+	coCited := make(map[string]int)
+	for _, citing := range source.Backlinks {
+		entry, ok := index.GetNote(citing)
+		if !ok {
+			continue
+		}
+		for _, target := range uniqueStrings(entry.OutgoingLinks) {
+			if target != req.Slug {
+				coCited[target]++
+			}
+		}
+	}
+
+	// 3. Combine the signals for every other note
+	var related []RelatedNote
+	for slug, entry := range index.Notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if slug == req.Slug {
+			continue
+		}
+
+		note := RelatedNote{
+			Slug:       slug,
+			Title:      entry.Title,
+			Similarity: similarity[slug],
+			SharedTags: sharedTags(source.Tags, entry.Tags),
+			CoCited:    coCited[slug],
+			Linked:     slices.Contains(source.OutgoingLinks, slug) || slices.Contains(source.Embeds, slug),
+		}
+
+		tagScore := 0.0
+		if union := len(uniqueStrings(append(slices.Clone(source.Tags), entry.Tags...))); union > 0 {
+			tagScore = float64(len(note.SharedTags)) / float64(union)
+		}
+		coCitationScore := 0.0
+		if note.CoCited > 0 {
+			coCitationScore = float64(note.CoCited) / math.Sqrt(float64(len(source.Backlinks)*max(len(entry.Backlinks), 1)))
+		}
+
+		note.Score = similarityWeight*note.Similarity + sharedTagsWeight*tagScore + coCitationWeight*min(coCitationScore, 1)
+		if note.Score > 0 {
+			related = append(related, note)
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Score != related[j].Score {
+			return related[i].Score > related[j].Score
+		}
+		return related[i].Slug < related[j].Slug
+	})
+	if req.Limit > 0 && len(related) > req.Limit {
+		related = related[:req.Limit]
+	}
+
+	return related, nil
+}
+
+// SuggestLinks returns similar notes that the note does not link to yet
+func (s *RelatedService) SuggestLinks(ctx context.Context, req RelatedRequest) ([]RelatedNote, error) {
+	related, err := s.Related(ctx, RelatedRequest{Slug: req.Slug})
+	if err != nil {
+		return nil, err
+	}
+
+	var suggestions []RelatedNote
+	for _, note := range related {
+		if note.Linked || note.Similarity < minSuggestionSimilarity {
+			continue
+		}
+		suggestions = append(suggestions, note)
+		if req.Limit > 0 && len(suggestions) == req.Limit {
+			break
+		}
+	}
+
+	return suggestions, nil
+}
+
+// similarities computes the TF-IDF cosine similarity of a note to every other note
+// Term weights are (1 + log tf) * log(N / df), so words in every note count for nothing;
+// stop words are skipped for the same reason
+func (s *RelatedService) similarities(slug string) (map[string]float64, error) {
+	index, err := s.search.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	total := float64(index.Count())
+	dots := make(map[string]float64)
+	norms := make(map[string]float64)
+	sourceNorm := 0.0
+
+	for term, postings := range index.Postings {
+		idf := math.Log(total / float64(len(postings)))
+		if idf == 0 || stopWords[term] {
+			continue
+		}
+
+		// The source note's weight for this term, if it has it
+		sourceWeight := 0.0
+		for _, posting := range postings {
+			if posting.Slug == slug {
+				sourceWeight = (1 + math.Log(float64(posting.Freq))) * idf
+				break
+			}
+		}
+		sourceNorm += sourceWeight * sourceWeight
+
+		for _, posting := range postings {
+			weight := (1 + math.Log(float64(posting.Freq))) * idf
+			norms[posting.Slug] += weight * weight
+			if sourceWeight != 0 && posting.Slug != slug {
+				dots[posting.Slug] += sourceWeight * weight
+			}
+		}
+	}
+
+	similarity := make(map[string]float64, len(dots))
+	for other, dot := range dots {
+		similarity[other] = dot / math.Sqrt(sourceNorm*norms[other])
+	}
+	return similarity, nil
+}
+
+// sharedTags returns the tags of a that b also has, in a's order
+func sharedTags(a, b []string) []string {
+	var shared []string
+	for _, tag := range uniqueStrings(a) {
+		if slices.Contains(b, tag) {
+			shared = append(shared, tag)
+		}
+	}
+	return shared
+}
+
+// uniqueStrings returns the distinct values in order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
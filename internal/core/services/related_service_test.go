@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+func setupRelated(t *testing.T, notes []struct {
+	title   string
+	tags    []string
+	content string
+}) *RelatedService {
+	mockRepo := mocks.NewMockRepository()
+	for _, n := range notes {
+		header, _ := domain.NewNoteHeader(n.title, n.tags, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, n.content))
+	}
+
+	dir := t.TempDir()
+	indexer := NewIndexerService(mockRepo, filepath.Join(dir, "index.json"))
+	if _, err := indexer.Execute(context.Background(), ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	return NewRelatedService(indexer, NewSearchService(mockRepo, filepath.Join(dir, SearchIndexFile)))
+}
+
+func TestRelatedService_Related(t *testing.T) {
+	svc := setupRelated(t, []struct {
+		title   string
+		tags    []string
+		content string
+	}{
+		{"Compactness", []string{"topology"}, "Every open cover of a compact space has a finite subcover."},
+		{"Heine Borel", []string{"analysis"}, "A subset of the reals is compact when closed and bounded; every open cover has a finite subcover."},
+		{"Hausdorff Spaces", []string{"topology"}, "Points are separated by disjoint neighbourhoods."},
+		{"Groups", []string{"algebra"}, "A group has an identity and inverses."},
+		{"Survey", []string{"algebra"}, "\\lxnote{compactness}{a} and \\lxnote{hausdorff-spaces}{b}"},
+	})
+
+	related, err := svc.Related(context.Background(), RelatedRequest{Slug: "compactness"})
+	if err != nil {
+		t.Fatalf("Related failed: %v", err)
+	}
+
+	bySlug := make(map[string]RelatedNote)
+	for _, r := range related {
+		bySlug[r.Slug] = r
+	}
+
+	// Shared words count without shared tags or links
+	if bySlug["heine-borel"].Similarity <= 0 {
+		t.Errorf("expected heine-borel to be similar by text, got %+v", bySlug["heine-borel"])
+	}
+
+	// A shared tag and a shared citation outweigh a few shared words
+	if related[0].Slug != "hausdorff-spaces" {
+		t.Errorf("expected hausdorff-spaces first, got %+v", related[0])
+	}
+	hausdorff := bySlug["hausdorff-spaces"]
+	if !reflect.DeepEqual(hausdorff.SharedTags, []string{"topology"}) || hausdorff.CoCited != 1 {
+		t.Errorf("expected hausdorff-spaces to share a tag and a citation, got %+v", hausdorff)
+	}
+	if _, ok := bySlug["groups"]; ok {
+		t.Error("expected unrelated notes to be left out")
+	}
+
+	limited, _ := svc.Related(context.Background(), RelatedRequest{Slug: "compactness", Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("expected limit to apply, got %d results", len(limited))
+	}
+
+	if _, err := svc.Related(context.Background(), RelatedRequest{Slug: "missing"}); err == nil {
+		t.Error("expected an error for an unknown note")
+	}
+}
+
+func TestRelatedService_SuggestLinks(t *testing.T) {
+	svc := setupRelated(t, []struct {
+		title   string
+		tags    []string
+		content string
+	}{
+		{"Eigenvalues", nil, "Eigenvalues of a matrix solve the characteristic polynomial. \\lxnote{determinants}{see}"},
+		{"Determinants", nil, "The determinant of a matrix is the characteristic polynomial at zero."},
+		{"Diagonalization", nil, "A matrix with distinct eigenvalues is diagonalizable."},
+		{"Poetry", nil, "Roses are red."},
+	})
+
+	suggestions, err := svc.SuggestLinks(context.Background(), RelatedRequest{Slug: "eigenvalues"})
+	if err != nil {
+		t.Fatalf("SuggestLinks failed: %v", err)
+	}
+
+	var slugs []string
+	for _, s := range suggestions {
+		slugs = append(slugs, s.Slug)
+	}
+	if !reflect.DeepEqual(slugs, []string{"diagonalization"}) {
+		t.Errorf("expected only the unlinked similar note, got %v", slugs)
+	}
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/latexscan"
+)
+
+// BM25 parameters: term frequency saturation and length normalization
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+const (
+	// titleWeight counts title and tag words as if they appeared this many times
+	titleWeight = 3
+
+	// maxSnippets is the number of matching lines shown per result
+	maxSnippets = 3
+)
+
+// SearchService maintains the on-disk inverted index and ranks notes against queries
+type SearchService struct {
+	noteRepo  ports.Repository
+	indexPath string
+}
+
+// NewSearchService creates a new search service
+func NewSearchService(noteRepo ports.Repository, indexPath string) *SearchService {
+	return &SearchService{
+		noteRepo:  noteRepo,
+		indexPath: indexPath,
+	}
+}
+
+// FullTextRequest is a ranked full-text query
+type FullTextRequest struct {
+	Query string
+	Limit int // 0 means no limit
+}
+
+// SearchResult is a note matching a query, best first
+type SearchResult struct {
+	Slug     string
+	Title    string
+	Filename string
+	Score    float64
+	Snippets []SearchSnippet
+}
+
+// SearchSnippet is a line of a note containing query terms
+type SearchSnippet struct {
+	LineNum    int
+	Content    string   // The line, without leading whitespace
+	Highlights [][2]int // Byte ranges of the matched words in Content
+}
+
+// Highlight returns the snippet with every matched word passed through mark
+func (s SearchSnippet) Highlight(mark func(string) string) string {
+	var sb strings.Builder
+	cursor := 0
+	for _, h := range s.Highlights {
+		sb.WriteString(s.Content[cursor:h[0]])
+		sb.WriteString(mark(s.Content[h[0]:h[1]]))
+		cursor = h[1]
+	}
+	sb.WriteString(s.Content[cursor:])
+	return sb.String()
+}
+
+// Update reindexes the given notes and drops removed ones
+// With rebuild, the existing index is discarded first
+func (s *SearchService) Update(notes []*domain.NoteBody, removed []string, rebuild bool) error {
+	index := domain.NewSearchIndex()
+	if !rebuild {
+		existing, err := s.LoadIndex()
+		if err != nil {
+			return err
+		}
+		index = existing
+	}
+
+	// 1. Drop the old postings of changed and removed notes
+	stale := make(map[string]bool, len(notes)+len(removed))
+	for _, note := range notes {
+		stale[note.Header.Slug] = true
+	}
+	for _, slug := range removed {
+		stale[slug] = true
+	}
+	index.RemoveDocuments(stale)
+
+	// 2. Add the new postings
+	for _, note := range notes {
+		index.AddDocument(note.Header.Slug, documentTerms(note))
+	}
+
+	index.UpdateLastIndexed()
+	return s.saveIndex(index)
+}
+
+// Stale reports whether the index is missing or was written by another version
+func (s *SearchService) Stale() bool {
+	if _, err := os.Stat(s.indexPath); err != nil {
+		return true
+	}
+	index, err := s.LoadIndex()
+	return err != nil || index.Version != domain.SearchIndexVersion || index.Documents == nil
+}
+
+// Search returns the notes matching any query term, ranked by BM25
+func (s *SearchService) Search(ctx context.Context, req FullTextRequest) ([]SearchResult, error) {
+	queryTerms := uniqueTerms(latexscan.SplitTerms(req.Query))
+	if len(queryTerms) == 0 {
+		return nil, fmt.Errorf("search query has no words")
+	}
+
+	index, err := s.LoadIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Score every note containing a query term
+	scores := make(map[string]float64)
+	total := float64(index.Count())
+	avgLength := index.AverageLength()
+
+	for _, term := range queryTerms {
+		postings := index.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (total-df+0.5)/(df+0.5))
+
+		for _, posting := range postings {
+			tf := float64(posting.Freq)
+			norm := 1 - bm25B + bm25B*float64(index.Documents[posting.Slug].Length)/avgLength
+			scores[posting.Slug] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	// 2. Rank, breaking ties by slug so results are stable
+	slugs := make([]string, 0, len(scores))
+	for slug := range scores {
+		slugs = append(slugs, slug)
+	}
+	sort.Slice(slugs, func(i, j int) bool {
+		if scores[slugs[i]] != scores[slugs[j]] {
+			return scores[slugs[i]] > scores[slugs[j]]
+		}
+		return slugs[i] < slugs[j]
+	})
+	if req.Limit > 0 && len(slugs) > req.Limit {
+		slugs = slugs[:req.Limit]
+	}
+
+	// 3. Load the top notes for their titles and snippets
+	wanted := make(map[string]bool, len(queryTerms))
+	for _, term := range queryTerms {
+		wanted[term] = true
+	}
+
+	results := make([]SearchResult, 0, len(slugs))
+	for _, slug := range slugs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		note, err := s.noteRepo.Get(ctx, slug)
+		if err != nil {
+			// The index is behind the vault; skip notes that are gone
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Slug:     slug,
+			Title:    note.Header.Title,
+			Filename: note.Header.Filename,
+			Score:    scores[slug],
+			Snippets: buildSnippets(note.Content, wanted),
+		})
+	}
+
+	return results, nil
+}
+
+// documentTerms counts the searchable words of a note
+func documentTerms(note *domain.NoteBody) map[string]int {
+	freqs := make(map[string]int)
+	for _, term := range latexscan.Terms(note.Content) {
+		freqs[term.Text]++
+	}
+
+	// Titles and tags describe the whole note, so they weigh more than body text
+	for _, term := range latexscan.SplitTerms(note.Header.Title + " " + strings.Join(note.Header.Tags, " ")) {
+		freqs[term.Text] += titleWeight
+	}
+
+	return freqs
+}
+
+// uniqueTerms returns the distinct words of a query in order
+func uniqueTerms(terms []latexscan.Term) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, term := range terms {
+		if !seen[term.Text] {
+			seen[term.Text] = true
+			words = append(words, term.Text)
+		}
+	}
+	return words
+}
+
+// buildSnippets picks the lines matching the most distinct query words
+func buildSnippets(content string, wanted map[string]bool) []SearchSnippet {
+	lines := strings.Split(content, "\n")
+	lineStarts := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		lineStarts[i] = offset
+		offset += len(line) + 1
+	}
+
+	// 1. Group matched words by line
+	type lineMatch struct {
+		line     int
+		ranges   [][2]int
+		distinct map[string]bool
+	}
+	var matches []*lineMatch
+	byLine := make(map[int]*lineMatch)
+
+	for _, term := range latexscan.Terms(content) {
+		if !wanted[term.Text] {
+			continue
+		}
+		m, ok := byLine[term.Line]
+		if !ok {
+			m = &lineMatch{line: term.Line, distinct: make(map[string]bool)}
+			byLine[term.Line] = m
+			matches = append(matches, m)
+		}
+		start := lineStarts[term.Line-1]
+		m.ranges = append(m.ranges, [2]int{term.Start - start, term.End - start})
+		m.distinct[term.Text] = true
+	}
+
+	// 2. Keep the best lines, shown in document order
+	sort.SliceStable(matches, func(i, j int) bool {
+		return len(matches[i].distinct) > len(matches[j].distinct)
+	})
+	if len(matches) > maxSnippets {
+		matches = matches[:maxSnippets]
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].line < matches[j].line
+	})
+
+	snippets := make([]SearchSnippet, 0, len(matches))
+	for _, m := range matches {
+		line := lines[m.line-1]
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := len(line) - len(trimmed)
+
+		snippet := SearchSnippet{LineNum: m.line, Content: strings.TrimRight(trimmed, " \t\r")}
+		for _, r := range m.ranges {
+			snippet.Highlights = append(snippet.Highlights, [2]int{r[0] - indent, r[1] - indent})
+		}
+		snippets = append(snippets, snippet)
+	}
+
+	return snippets
+}
+
+func (s *SearchService) saveIndex(index *domain.SearchIndex) error {
+	if err := os.MkdirAll(filepath.Dir(s.indexPath), 0755); err != nil {
+		return fmt.Errorf("failed to create search index directory: %w", err)
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to marshal search index: %w", err)
+	}
+
+	return os.WriteFile(s.indexPath, data, 0644)
+}
+
+// LoadIndex reads the search index, returning an empty one if it does not exist yet
+func (s *SearchService) LoadIndex() (*domain.SearchIndex, error) {
+	data, err := os.ReadFile(s.indexPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return domain.NewSearchIndex(), nil
+		}
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+
+	var index domain.SearchIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search index: %w", err)
+	}
+
+	return &index, nil
+}
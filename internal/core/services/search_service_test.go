@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+func setupSearch(t *testing.T, notes map[string]string) (*mocks.MockRepository, *IndexerService, *SearchService) {
+	mockRepo := mocks.NewMockRepository()
+	for title, content := range notes {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		mockRepo.Save(context.Background(), domain.NewNoteBody(header, content))
+	}
+
+	dir := t.TempDir()
+	indexer := NewIndexerService(mockRepo, filepath.Join(dir, "index.json"))
+	if _, err := indexer.Execute(context.Background(), ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	return mockRepo, indexer, NewSearchService(mockRepo, filepath.Join(dir, SearchIndexFile))
+}
+
+func TestSearchService_RanksByBM25(t *testing.T) {
+	_, _, search := setupSearch(t, map[string]string{
+		"Compactness":  "A space is compact when every cover is finite. Compact sets are closed.",
+		"Metric Space": "Every metric space is Hausdorff. Compact metric spaces are complete.",
+		"Groups":       "A group has an identity.",
+	})
+
+	results, err := search.Search(context.Background(), FullTextRequest{Query: "compact"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	// The title match and higher term frequency put compactness first
+	if results[0].Slug != "compactness" || results[1].Slug != "metric-space" {
+		t.Errorf("unexpected ranking: %s, %s", results[0].Slug, results[1].Slug)
+	}
+	if results[0].Score <= results[1].Score {
+		t.Errorf("expected descending scores, got %f and %f", results[0].Score, results[1].Score)
+	}
+
+	limited, _ := search.Search(context.Background(), FullTextRequest{Query: "compact", Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("expected limit to apply, got %d results", len(limited))
+	}
+}
+
+func TestSearchService_IgnoresCommandsKeepsMath(t *testing.T) {
+	_, _, search := setupSearch(t, map[string]string{
+		"Integrals": "\\section{Basics}\\label{sec:riemann}\nWe have $\\int f = \\alpha$.\n% hidden remark",
+	})
+
+	for _, query := range []string{"section", "riemann", "hidden"} {
+		if results, _ := search.Search(context.Background(), FullTextRequest{Query: query}); len(results) != 0 {
+			t.Errorf("expected %q not to match, got %v", query, results)
+		}
+	}
+
+	results, _ := search.Search(context.Background(), FullTextRequest{Query: "alpha"})
+	if len(results) != 1 {
+		t.Fatalf("expected math to be searchable, got %d results", len(results))
+	}
+
+	snippet := results[0].Snippets[0]
+	if snippet.LineNum != 2 {
+		t.Errorf("expected snippet on line 2, got %d", snippet.LineNum)
+	}
+	highlighted := snippet.Highlight(func(s string) string { return "[" + s + "]" })
+	if highlighted != "We have $\\int f = \\[alpha]$." {
+		t.Errorf("unexpected highlight: %q", highlighted)
+	}
+}
+
+func TestSearchService_FollowsIncrementalReindex(t *testing.T) {
+	ctx := context.Background()
+	mockRepo, indexer, search := setupSearch(t, map[string]string{
+		"Alpha": "apples",
+		"Beta":  "bananas",
+	})
+
+	alpha, _ := mockRepo.Get(ctx, "alpha")
+	alpha.Content = "cherries"
+	mockRepo.Save(ctx, alpha)
+	mockRepo.Delete(ctx, "beta")
+
+	if _, err := indexer.Execute(ctx, ReindexRequest{}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	for query, want := range map[string]int{"apples": 0, "bananas": 0, "cherries": 1} {
+		results, _ := search.Search(ctx, FullTextRequest{Query: query})
+		if len(results) != want {
+			t.Errorf("query %q: expected %d results, got %d", query, want, len(results))
+		}
+	}
+
+	index, _ := search.LoadIndex()
+	if _, stale := index.Postings["apples"]; stale || index.Count() != 1 {
+		t.Errorf("expected stale postings to be removed, got %v", index.Postings)
+	}
+}
+
+func TestSearchService_EmptyQuery(t *testing.T) {
+	_, _, search := setupSearch(t, map[string]string{"Alpha": "apples"})
+	if _, err := search.Search(context.Background(), FullTextRequest{Query: "  ?! "}); err == nil {
+		t.Error("expected an error for a query without words")
+	}
+}
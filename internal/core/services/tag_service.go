@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+	"github.com/kamal-hamza/lx-cli/pkg/metadata"
+)
+
+// TagService edits tags across the whole vault
+type TagService struct {
+	noteRepo ports.Repository
+}
+
+// NewTagService creates a new tag service
+func NewTagService(noteRepo ports.Repository) *TagService {
+	return &TagService{
+		noteRepo: noteRepo,
+	}
+}
+
+// RenameTagRequest moves a tag, and the tags nested under it, to a new name
+type RenameTagRequest struct {
+	From string
+	To   string
+
+	// Merge allows To to be in use already; notes ending up with a tag twice keep one
+	Merge bool
+}
+
+// TagChange is the tags of one note before and after an edit
+type TagChange struct {
+	Slug     string
+	Filename string
+	Before   []string
+	After    []string
+}
+
+// RenameTagResponse lists the notes that were rewritten
+type RenameTagResponse struct {
+	Changes []TagChange
+}
+
+// Rename rewrites the tags header of every note using a tag
+// Either every note is rewritten or, if saving one fails, none is
+func (s *TagService) Rename(ctx context.Context, req RenameTagRequest) (*RenameTagResponse, error) {
+	from, to := domain.NormalizeTag(req.From), domain.NormalizeTag(req.To)
+
+	// 1. Validate
+	for _, tag := range []string{req.From, req.To} {
+		if err := domain.ValidateTag(tag); err != nil {
+			return nil, err
+		}
+	}
+	if from == to {
+		return nil, fmt.Errorf("tag is already named %s", to)
+	}
+
+	headers, err := s.noteRepo.ListHeaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	found := false
+	for _, header := range headers {
+		for _, tag := range header.Tags {
+			if domain.TagMatches(tag, from) {
+				found = true
+			} else if domain.TagMatches(tag, to) && !req.Merge {
+				return nil, fmt.Errorf("tag %s is already in use; merge the tags instead", to)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("tag not found: %s", from)
+	}
+
+	// 2. Prepare every change before writing anything
+	var originals []*domain.NoteBody
+	var updated []*domain.NoteBody
+	resp := &RenameTagResponse{}
+
+	for _, header := range headers {
+		if !header.HasTag(from) {
+			continue
+		}
+
+		note, err := s.noteRepo.Get(ctx, header.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Slug, err)
+		}
+
+		after := renameTags(note.Header.Tags, from, to)
+		renamed := &domain.NoteBody{Header: note.Header, Content: metadata.UpdateTags(note.Content, after)}
+		renamed.Header.Tags = after
+
+		originals = append(originals, &domain.NoteBody{Header: note.Header, Content: note.Content})
+		updated = append(updated, renamed)
+		resp.Changes = append(resp.Changes, TagChange{
+			Slug:     header.Slug,
+			Filename: header.Filename,
+			Before:   note.Header.Tags,
+			After:    after,
+		})
+	}
+
+	// 3. Write, putting back the notes already written if one fails
+	for i, note := range updated {
+		if err := s.noteRepo.Save(ctx, note); err != nil {
+			for j := 0; j < i; j++ {
+				_ = s.noteRepo.Save(ctx, originals[j])
+			}
+			return nil, fmt.Errorf("failed to save %s, no notes were changed: %w", note.Header.Slug, err)
+		}
+	}
+
+	return resp, nil
+}
+
+// Tree returns the tags of the vault as a hierarchy
+func (s *TagService) Tree(ctx context.Context) ([]*domain.TagNode, error) {
+	headers, err := s.noteRepo.ListHeaders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notes: %w", err)
+	}
+
+	noteTags := make([][]string, 0, len(headers))
+	for _, header := range headers {
+		noteTags = append(noteTags, header.Tags)
+	}
+	return domain.BuildTagTree(noteTags), nil
+}
+
+// renameTags renames the matching tags of a note, dropping duplicates it creates
+func renameTags(tags []string, from, to string) []string {
+	seen := make(map[string]bool, len(tags))
+	renamed := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, _ = domain.RenameTag(tag, from, to)
+		if key := strings.ToLower(tag); !seen[key] {
+			seen[key] = true
+			renamed = append(renamed, tag)
+		}
+	}
+	return renamed
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+// failingSaveRepository fails to save one note
+type failingSaveRepository struct {
+	*mocks.MockRepository
+	failSlug string
+}
+
+func (r *failingSaveRepository) Save(ctx context.Context, note *domain.NoteBody) error {
+	if note.Header.Slug == r.failSlug {
+		return fmt.Errorf("disk full")
+	}
+	return r.MockRepository.Save(ctx, note)
+}
+
+func setupTags(t *testing.T) *mocks.MockRepository {
+	repo := mocks.NewMockRepository()
+	for title, tags := range map[string][]string{
+		"Groups":   {"math/algebra/groups", "exam"},
+		"Rings":    {"math/algebra", "algebra"},
+		"Limits":   {"math/analysis"},
+		"Optics":   {"physics"},
+		"Algebras": {"algebra"},
+	} {
+		header, _ := domain.NewNoteHeader(title, tags, "")
+		content := fmt.Sprintf("%% title: %s\n%% tags: %s\n\\documentclass{article}", title, strings.Join(tags, ", "))
+		repo.Save(context.Background(), domain.NewNoteBody(header, content))
+	}
+	return repo
+}
+
+func noteTags(t *testing.T, repo *mocks.MockRepository, slug string) []string {
+	note, err := repo.Get(context.Background(), slug)
+	if err != nil {
+		t.Fatalf("Get(%s) failed: %v", slug, err)
+	}
+	return note.Header.Tags
+}
+
+func TestTagService_Rename(t *testing.T) {
+	repo := setupTags(t)
+	svc := NewTagService(repo)
+
+	resp, err := svc.Rename(context.Background(), RenameTagRequest{From: "math/algebra", To: "math/abstract-algebra"})
+	if err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if len(resp.Changes) != 2 {
+		t.Errorf("expected 2 notes changed, got %d", len(resp.Changes))
+	}
+
+	if got := noteTags(t, repo, "groups"); !reflect.DeepEqual(got, []string{"math/abstract-algebra/groups", "exam"}) {
+		t.Errorf("unexpected tags for groups: %v", got)
+	}
+	note, _ := repo.Get(context.Background(), "rings")
+	if !strings.Contains(note.Content, "% tags: math/abstract-algebra, algebra\n") {
+		t.Errorf("expected the tags header to be rewritten, got %q", note.Content)
+	}
+
+	// Renaming onto a tag in use needs a merge
+	if _, err := svc.Rename(context.Background(), RenameTagRequest{From: "algebra", To: "math/abstract-algebra"}); err == nil {
+		t.Error("expected renaming onto an existing tag to fail")
+	}
+	if _, err := svc.Rename(context.Background(), RenameTagRequest{From: "missing", To: "other"}); err == nil {
+		t.Error("expected renaming an unknown tag to fail")
+	}
+}
+
+func TestTagService_Merge(t *testing.T) {
+	repo := setupTags(t)
+	svc := NewTagService(repo)
+
+	resp, err := svc.Rename(context.Background(), RenameTagRequest{From: "algebra", To: "math/algebra", Merge: true})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+	if len(resp.Changes) != 2 {
+		t.Errorf("expected 2 notes changed, got %d", len(resp.Changes))
+	}
+
+	// The note that had both keeps one
+	if got := noteTags(t, repo, "rings"); !reflect.DeepEqual(got, []string{"math/algebra"}) {
+		t.Errorf("unexpected tags for rings: %v", got)
+	}
+	if got := noteTags(t, repo, "algebras"); !reflect.DeepEqual(got, []string{"math/algebra"}) {
+		t.Errorf("unexpected tags for algebras: %v", got)
+	}
+}
+
+func TestTagService_Rename_RollsBackOnFailure(t *testing.T) {
+	mockRepo := setupTags(t)
+	svc := NewTagService(&failingSaveRepository{MockRepository: mockRepo, failSlug: "rings"})
+
+	if _, err := svc.Rename(context.Background(), RenameTagRequest{From: "math", To: "maths"}); err == nil {
+		t.Fatal("expected the failed save to be reported")
+	}
+
+	for _, slug := range []string{"groups", "rings", "limits"} {
+		for _, tag := range noteTags(t, mockRepo, slug) {
+			if strings.HasPrefix(tag, "maths") {
+				t.Errorf("expected %s to be left unchanged, got %v", slug, noteTags(t, mockRepo, slug))
+			}
+		}
+	}
+}
+
+func TestTagService_Tree(t *testing.T) {
+	svc := NewTagService(setupTags(t))
+
+	tree, err := svc.Tree(context.Background())
+	if err != nil {
+		t.Fatalf("Tree failed: %v", err)
+	}
+
+	var roots []string
+	for _, node := range tree {
+		roots = append(roots, fmt.Sprintf("%s:%d", node.Path, node.Total))
+	}
+	if !reflect.DeepEqual(roots, []string{"algebra:2", "exam:1", "math:3", "physics:1"}) {
+		t.Errorf("unexpected roots: %v", roots)
+	}
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports"
+)
+
+// TrashService deletes notes into the trash and brings them back
+type TrashService struct {
+	trash     ports.TrashRepository
+	assetRepo ports.AssetRepository
+	indexer   *IndexerService
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(trash ports.TrashRepository, assetRepo ports.AssetRepository, indexer *IndexerService) *TrashService {
+	return &TrashService{
+		trash:     trash,
+		assetRepo: assetRepo,
+		indexer:   indexer,
+	}
+}
+
+// OrphanedAssets returns the assets a note uses that no other note uses
+// It relies on the index; without one, no asset is considered orphaned
+func (s *TrashService) OrphanedAssets(ctx context.Context, slug string) []string {
+	index, err := s.indexer.LoadIndex()
+	if err != nil {
+		return nil
+	}
+	entry, exists := index.GetNote(slug)
+	if !exists {
+		return nil
+	}
+
+	var orphans []string
+	for _, asset := range entry.Assets {
+		usedElsewhere := false
+		for otherSlug, otherEntry := range index.Notes {
+			if otherSlug != slug && slices.Contains(otherEntry.Assets, asset) {
+				usedElsewhere = true
+				break
+			}
+		}
+		if !usedElsewhere {
+			orphans = append(orphans, asset)
+		}
+	}
+	return orphans
+}
+
+// Delete moves a note to the trash, together with the given assets
+// The assets leave the asset manifest; their records are kept in the trash
+func (s *TrashService) Delete(ctx context.Context, slug string, assets []string) (*domain.TrashItem, error) {
+	// 1. Keep the manifest records, so restoring brings back descriptions too
+	records := make([]domain.Asset, 0, len(assets))
+	for _, filename := range assets {
+		record := domain.Asset{Filename: filename}
+		if asset, err := s.assetRepo.Get(ctx, filename); err == nil && asset != nil {
+			record = *asset
+		}
+		records = append(records, record)
+	}
+
+	// 2. Move the note and assets
+	item, err := s.trash.Trash(ctx, slug, records)
+	if item == nil {
+		return nil, fmt.Errorf("failed to delete note: %w", err)
+	}
+
+	// 3. Drop the trashed assets from the manifest, even if some could not be moved
+	for _, asset := range item.Assets {
+		_ = s.assetRepo.Delete(ctx, asset.Filename)
+	}
+	if err != nil {
+		return item, fmt.Errorf("failed to trash every asset: %w", err)
+	}
+	return item, nil
+}
+
+// List returns the trashed notes, most recently deleted first
+func (s *TrashService) List(ctx context.Context) ([]domain.TrashItem, error) {
+	items, err := s.trash.ListTrash(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	return items, nil
+}
+
+// Restore brings back a trashed note and its assets
+// ref is a trash ID or a slug; for a slug, the most recent deletion is restored
+func (s *TrashService) Restore(ctx context.Context, ref string) (*domain.TrashItem, error) {
+	items, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range items {
+		if !candidate.MatchesRef(ref) {
+			continue
+		}
+
+		item, err := s.trash.Restore(ctx, candidate.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", ref, err)
+		}
+		for _, asset := range item.Assets {
+			if err := s.assetRepo.Save(ctx, asset); err != nil {
+				return item, fmt.Errorf("failed to restore asset record %s: %w", asset.Filename, err)
+			}
+		}
+		return item, nil
+	}
+
+	return nil, fmt.Errorf("not in the trash: %s", ref)
+}
+
+// Empty deletes the notes trashed longer ago than olderThan for good
+// With olderThan 0 the whole trash is emptied. Returns the deleted items
+func (s *TrashService) Empty(ctx context.Context, olderThan time.Duration) ([]domain.TrashItem, error) {
+	items, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var emptied []domain.TrashItem
+	for _, item := range items {
+		if olderThan > 0 && item.DeletedAt.After(cutoff) {
+			continue
+		}
+		if err := s.trash.Purge(ctx, item.ID); err != nil {
+			return emptied, fmt.Errorf("failed to empty %s: %w", item.ID, err)
+		}
+		emptied = append(emptied, item)
+	}
+	return emptied, nil
+}
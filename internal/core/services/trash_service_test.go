@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kamal-hamza/lx-cli/internal/core/domain"
+	"github.com/kamal-hamza/lx-cli/internal/core/ports/mocks"
+)
+
+func setupTrash(t *testing.T) (*TrashService, *mocks.MockRepository, *mocks.MockAssetRepository) {
+	t.Helper()
+	ctx := context.Background()
+
+	repo := mocks.NewMockRepository()
+	for title, content := range map[string]string{
+		"Optics": "\\includegraphics{lens.png}\n\\includegraphics{shared.png}",
+		"Waves":  "\\includegraphics{shared.png}",
+	} {
+		header, _ := domain.NewNoteHeader(title, []string{}, "")
+		repo.Save(ctx, domain.NewNoteBody(header, content))
+	}
+
+	assets := mocks.NewMockAssetRepository()
+	assets.Save(ctx, domain.Asset{Filename: "lens.png", Description: "Thin lens"})
+	assets.Save(ctx, domain.Asset{Filename: "shared.png"})
+
+	indexer := NewIndexerService(repo, filepath.Join(t.TempDir(), "index.json"))
+	if _, err := indexer.Execute(ctx, ReindexRequest{}); err != nil {
+		t.Fatalf("reindex failed: %v", err)
+	}
+
+	return NewTrashService(repo, assets, indexer), repo, assets
+}
+
+func TestTrashService_DeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, assets := setupTrash(t)
+
+	// Only assets no other note uses are orphaned
+	orphans := svc.OrphanedAssets(ctx, "optics")
+	if !reflect.DeepEqual(orphans, []string{"lens.png"}) {
+		t.Fatalf("expected lens.png to be orphaned, got %v", orphans)
+	}
+
+	item, err := svc.Delete(ctx, "optics", orphans)
+	if err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if item.Slug != "optics" || item.Title != "Optics" || len(item.Assets) != 1 || item.Assets[0].Description != "Thin lens" {
+		t.Errorf("unexpected trash item: %+v", item)
+	}
+	if repo.Exists(ctx, "optics") {
+		t.Error("expected the note to be gone")
+	}
+	if _, err := assets.Get(ctx, "lens.png"); err == nil {
+		t.Error("expected the trashed asset to leave the manifest")
+	}
+
+	items, err := svc.List(ctx)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected one trashed note, got %v (%v)", items, err)
+	}
+
+	// Restoring by slug brings back the note and its asset record
+	if _, err := svc.Restore(ctx, "optics"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !repo.Exists(ctx, "optics") {
+		t.Error("expected the note to be restored")
+	}
+	if asset, err := assets.Get(ctx, "lens.png"); err != nil || asset.Description != "Thin lens" {
+		t.Errorf("expected the asset record to be restored, got %v (%v)", asset, err)
+	}
+	if items, _ := svc.List(ctx); len(items) != 0 {
+		t.Errorf("expected an empty trash, got %v", items)
+	}
+
+	if _, err := svc.Restore(ctx, "optics"); err == nil {
+		t.Error("expected restoring a note that is not in the trash to fail")
+	}
+}
+
+func TestTrashService_Empty(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _ := setupTrash(t)
+
+	old, err := svc.Delete(ctx, "optics", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Delete(ctx, "waves", nil); err != nil {
+		t.Fatal(err)
+	}
+	repo.SetDeletedAt(old.ID, time.Now().AddDate(0, 0, -40))
+
+	emptied, err := svc.Empty(ctx, 30*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Empty failed: %v", err)
+	}
+	if len(emptied) != 1 || emptied[0].Slug != "optics" {
+		t.Errorf("expected only the old note to be emptied, got %v", emptied)
+	}
+
+	// Without an age, everything goes
+	emptied, err = svc.Empty(ctx, 0)
+	if err != nil || len(emptied) != 1 || emptied[0].Slug != "waves" {
+		t.Errorf("expected the rest of the trash to be emptied, got %v (%v)", emptied, err)
+	}
+	if _, err := svc.Restore(ctx, "waves"); err == nil {
+		t.Error("expected emptied notes to be gone for good")
+	}
+}
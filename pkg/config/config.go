@@ -15,6 +15,8 @@ type Config struct {
 	Editor          string            `yaml:"editor"`
 	MaxWorkers      int               `yaml:"max_workers"`
 	LatexmkFlags    []string          `yaml:"latexmk_flags"`
+	BuildHistory    int               `yaml:"build_history"`
+	BuildTimeout    int               `yaml:"build_timeout"` // Seconds per note, 0 disables
 	DefaultAction   string            `yaml:"default_action"`
 	DefaultSort     string            `yaml:"default_sort"`
 	ReverseSort     bool              `yaml:"reverse_sort"`
@@ -49,6 +51,9 @@ type Config struct {
 	GrepContextLines  int  `yaml:"grep_context_lines"`
 	MaxSearchResults  int  `yaml:"max_search_results"`
 
+	// Saved searches: name -> query, used as @name in queries
+	SavedSearches map[string]string `yaml:"saved_searches"`
+
 	// Performance
 	WatchDebounceMS        int  `yaml:"watch_debounce_ms"`
 	EnableCache            bool `yaml:"enable_cache"`
@@ -60,6 +65,11 @@ type Config struct {
 
 	// Templates
 	CustomTemplateDir string `yaml:"custom_template_dir"`
+
+	// Vaults: name -> root path, and the one chosen with lx vault use
+	// Only read from the global config
+	Vaults       map[string]string `yaml:"vaults"`
+	CurrentVault string            `yaml:"current_vault"`
 }
 
 // DefaultConfig returns a Config struct with default values
@@ -71,6 +81,8 @@ func DefaultConfig() *Config {
 		Editor:                 "",
 		MaxWorkers:             4,
 		LatexmkFlags:           []string{"-pdf", "-interaction=nonstopmode"},
+		BuildHistory:           20,
+		BuildTimeout:           300,
 		DefaultAction:          "open",
 		DefaultSort:            "date",
 		ReverseSort:            false,
@@ -92,12 +104,15 @@ func DefaultConfig() *Config {
 		GrepCaseSensitive:      false,
 		GrepContextLines:       2,
 		MaxSearchResults:       50,
+		SavedSearches:          make(map[string]string),
 		WatchDebounceMS:        500,
 		EnableCache:            true,
 		CacheExpirationMinutes: 30,
 		DefaultExportFormat:    "pdf",
 		ExportIncludeAssets:    true,
 		CustomTemplateDir:      "",
+		Vaults:                 make(map[string]string),
+		CurrentVault:           "",
 	}
 }
 
@@ -121,43 +136,83 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	cfg.applyDefaults()
+	return cfg, nil
+}
+
+// Overlay layers the settings of a vault's own config file over the config
+// Settings missing from the file keep their value, and maps are merged. The
+// vault registry is global, so the file cannot change it
+func (c *Config) Overlay(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read vault config file: %w", err)
+	}
+
+	vaults, current := c.Vaults, c.CurrentVault
+	c.Vaults = nil
+	err = yaml.Unmarshal(data, c)
+	c.Vaults, c.CurrentVault = vaults, current
+	if err != nil {
+		return fmt.Errorf("failed to parse vault config file: %w", err)
+	}
+
+	c.applyDefaults()
+	return nil
+}
+
+// applyDefaults fills in values a config file left empty or set to invalid values
+func (c *Config) applyDefaults() {
 	// Ensure map is initialized if nil
-	if cfg.Aliases == nil {
-		cfg.Aliases = make(map[string]string)
+	if c.Aliases == nil {
+		c.Aliases = make(map[string]string)
+	}
+	if c.SavedSearches == nil {
+		c.SavedSearches = make(map[string]string)
+	}
+	if c.Vaults == nil {
+		c.Vaults = make(map[string]string)
 	}
 
 	// Apply defaults for essential values if missing
-	if cfg.Compiler == "" {
-		cfg.Compiler = "latexmk"
+	if c.Compiler == "" {
+		c.Compiler = "latexmk"
+	}
+	if c.MaxWorkers <= 0 {
+		c.MaxWorkers = 4
+	}
+	if c.BuildHistory < 0 {
+		c.BuildHistory = 20
 	}
-	if cfg.MaxWorkers <= 0 {
-		cfg.MaxWorkers = 4
+	if c.BuildTimeout < 0 {
+		c.BuildTimeout = 0
 	}
-	if cfg.DefaultAction == "" {
-		cfg.DefaultAction = "open"
+	if c.DefaultAction == "" {
+		c.DefaultAction = "open"
 	}
-	if cfg.DefaultSort == "" {
-		cfg.DefaultSort = "date"
+	if c.DefaultSort == "" {
+		c.DefaultSort = "date"
 	}
-	if cfg.DateFormat == "" {
-		cfg.DateFormat = "20060102"
+	if c.DateFormat == "" {
+		c.DateFormat = "20060102"
 	}
-	if cfg.DisplayDateFormat == "" {
-		cfg.DisplayDateFormat = "2006-01-02"
+	if c.DisplayDateFormat == "" {
+		c.DisplayDateFormat = "2006-01-02"
 	}
-	if cfg.GraphDirection == "" {
-		cfg.GraphDirection = "LR"
+	if c.GraphDirection == "" {
+		c.GraphDirection = "LR"
 	}
-	if cfg.GitCommitTemplate == "" {
-		cfg.GitCommitTemplate = "Auto-sync: {date} {time}"
+	if c.GitCommitTemplate == "" {
+		c.GitCommitTemplate = "Auto-sync: {date} {time}"
 	}
 
 	// Validate DefaultAction
-	if !isValidDefaultAction(cfg.DefaultAction) {
-		cfg.DefaultAction = "open"
+	if !isValidDefaultAction(c.DefaultAction) {
+		c.DefaultAction = "open"
 	}
-
-	return cfg, nil
 }
 
 // Save persists the current configuration to the specified file path
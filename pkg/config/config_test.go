@@ -215,6 +215,34 @@ func TestLoad_NegativeMaxWorkers(t *testing.T) {
 	}
 }
 
+func TestLoad_BuildHistory(t *testing.T) {
+	tests := []struct {
+		yaml string
+		want int
+	}{
+		{"build_history: 0\n", 0},
+		{"build_history: -1\n", 20},
+		{"max_workers: 2\n", 20},
+	}
+
+	for _, tt := range tests {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(configPath, []byte(tt.yaml), 0644); err != nil {
+			t.Fatalf("failed to create test config file: %v", err)
+		}
+
+		cfg, err := Load(configPath)
+		if err != nil {
+			t.Fatalf("failed to load config: %v", err)
+		}
+
+		// 0 keeps every build; only negative values fall back to the default
+		if cfg.BuildHistory != tt.want {
+			t.Errorf("%q: expected BuildHistory=%d, got %d", tt.yaml, tt.want, cfg.BuildHistory)
+		}
+	}
+}
+
 func TestLoad_InvalidYAML(t *testing.T) {
 	// Create a config file with invalid YAML
 	tempDir := t.TempDir()
@@ -372,3 +400,47 @@ func contains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestOverlay(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "config.yaml")
+	vaultPath := filepath.Join(tmpDir, "lx.yaml")
+
+	global := "compiler: tectonic\nmax_workers: 8\naliases:\n  ls: list\nvaults:\n  team: /srv/team\n"
+	local := "max_workers: 2\naliases:\n  t: todo\nvaults:\n  other: /tmp/other\ncurrent_vault: other\n"
+	if err := os.WriteFile(globalPath, []byte(global), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(vaultPath, []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(globalPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if err := cfg.Overlay(vaultPath); err != nil {
+		t.Fatalf("Overlay failed: %v", err)
+	}
+
+	// Settings in the vault file win; the others keep the global value
+	if cfg.MaxWorkers != 2 {
+		t.Errorf("expected MaxWorkers=2 from the vault config, got %d", cfg.MaxWorkers)
+	}
+	if cfg.Compiler != "tectonic" {
+		t.Errorf("expected Compiler='tectonic' from the global config, got %q", cfg.Compiler)
+	}
+	if cfg.Aliases["ls"] != "list" || cfg.Aliases["t"] != "todo" {
+		t.Errorf("expected aliases to be merged, got %v", cfg.Aliases)
+	}
+
+	// The vault registry only comes from the global config
+	if len(cfg.Vaults) != 1 || cfg.Vaults["team"] != "/srv/team" || cfg.CurrentVault != "" {
+		t.Errorf("expected the vault file to leave the registry alone, got %v %q", cfg.Vaults, cfg.CurrentVault)
+	}
+
+	// A vault without its own config file changes nothing
+	if err := cfg.Overlay(filepath.Join(tmpDir, "missing.yaml")); err != nil {
+		t.Errorf("expected a missing vault config to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package latexparser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// IssueKind classifies what an issue is about
+type IssueKind string
+
+const (
+	KindOther              IssueKind = "other"
+	KindUndefinedReference IssueKind = "undefined-reference"
+	KindUndefinedCitation  IssueKind = "undefined-citation"
+	KindMissingFile        IssueKind = "missing-file"
+	KindMissingPackage     IssueKind = "missing-package"
+	KindRerun              IssueKind = "rerun"
+	KindFont               IssueKind = "font"
+	KindBox                IssueKind = "box"
+	KindBuildOption        IssueKind = "build-option" // Build options in the note header that were ignored
+)
+
+// kindOrder is the order in which groups are displayed
+var kindOrder = []IssueKind{
+	KindBuildOption,
+	KindMissingPackage,
+	KindMissingFile,
+	KindUndefinedReference,
+	KindUndefinedCitation,
+	KindRerun,
+	KindFont,
+	KindOther,
+	KindBox,
+}
+
+// Title returns a human-readable group heading for the kind
+func (k IssueKind) Title() string {
+	switch k {
+	case KindUndefinedReference:
+		return "Undefined references"
+	case KindUndefinedCitation:
+		return "Undefined citations"
+	case KindMissingFile:
+		return "Missing files"
+	case KindMissingPackage:
+		return "Missing packages"
+	case KindRerun:
+		return "Rerun needed"
+	case KindFont:
+		return "Fonts"
+	case KindBox:
+		return "Overfull/underfull boxes"
+	case KindBuildOption:
+		return "Build options"
+	default:
+		return "Other"
+	}
+}
+
+var (
+	// File `foo.sty' not found (quotes vary between TeX distributions)
+	missingFilePattern = regexp.MustCompile("File [`'\"]([^`'\"]+)['\"] not found")
+
+	// Overfull \hbox (12.3pt too wide) in paragraph at lines 10--12
+	// Underfull \vbox (badness 10000) detected at line 40
+	boxPattern = regexp.MustCompile(`^(Overfull|Underfull) \\([hv])box \((?:(-?[\d.]+)pt too \w+|badness (\d+))\)(?:.*?lines? (\d+))?`)
+
+	// Warnings that name the input line they refer to
+	inputLinePattern = regexp.MustCompile(`on input line (\d+)`)
+)
+
+// classify determines the kind of an issue from its message
+func classify(message string) IssueKind {
+	lower := strings.ToLower(message)
+
+	switch {
+	case missingFilePattern.MatchString(message):
+		if isPackageFile(missingFilePattern.FindStringSubmatch(message)[1]) {
+			return KindMissingPackage
+		}
+		return KindMissingFile
+	case strings.Contains(lower, "citation") && strings.Contains(lower, "undefined"):
+		return KindUndefinedCitation
+	case strings.Contains(lower, "reference") && strings.Contains(lower, "undefined"):
+		return KindUndefinedReference
+	case strings.Contains(lower, "rerun"):
+		return KindRerun
+	case strings.Contains(lower, "font shape") || strings.HasPrefix(lower, "missing character"):
+		return KindFont
+	default:
+		return KindOther
+	}
+}
+
+// isPackageFile reports whether a missing file is a package or class
+func isPackageFile(name string) bool {
+	ext := filepath.Ext(name)
+	return ext == ".sty" || ext == ".cls"
+}
+
+// packageNames maps files to the distribution package that provides them
+// when the two differ; everything else is installed under its own name
+var packageNames = map[string]string{
+	"tikz":          "pgf",
+	"graphicx":      "graphics",
+	"color":         "graphics",
+	"amssymb":       "amsfonts",
+	"amsthm":        "amscls",
+	"algorithmic":   "algorithms",
+	"algorithm":     "algorithms",
+	"algpseudocode": "algorithmicx",
+	"subcaption":    "caption",
+	"mathrsfs":      "jknapltx",
+}
+
+// suggestPackage returns an install hint for a missing package file
+func suggestPackage(file string) string {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	if pkg, ok := packageNames[name]; ok {
+		name = pkg
+	}
+	return "Install the " + name + " package (e.g. tlmgr install " + name + ")"
+}
+
+// newIssue builds an issue with its kind, source line and suggestion filled in
+func newIssue(level IssueLevel, file string, line int, message string) Issue {
+	issue := Issue{
+		Level:   level,
+		Kind:    classify(message),
+		File:    file,
+		Line:    line,
+		Message: message,
+	}
+
+	if issue.Line == 0 {
+		if matches := inputLinePattern.FindStringSubmatch(message); matches != nil {
+			issue.Line, _ = strconv.Atoi(matches[1])
+		}
+	}
+
+	if issue.Kind == KindMissingPackage {
+		issue.Suggestion = suggestPackage(missingFilePattern.FindStringSubmatch(message)[1])
+	}
+
+	return issue
+}
+
+// parseBox parses an overfull/underfull box warning line
+func parseBox(line string, file string) (Issue, bool) {
+	matches := boxPattern.FindStringSubmatch(line)
+	if matches == nil {
+		return Issue{}, false
+	}
+
+	issue := Issue{
+		Level:   LevelWarning,
+		Kind:    KindBox,
+		File:    file,
+		Message: strings.TrimSpace(line),
+	}
+	if matches[3] != "" {
+		issue.Amount, _ = strconv.ParseFloat(matches[3], 64)
+	}
+	if matches[5] != "" {
+		issue.Line, _ = strconv.Atoi(matches[5])
+	}
+
+	return issue, true
+}
+
+// groupByKind splits issues into groups in display order
+func groupByKind(issues []Issue) ([]IssueKind, map[IssueKind][]Issue) {
+	groups := make(map[IssueKind][]Issue)
+	for _, issue := range issues {
+		kind := issue.Kind
+		if kind == "" {
+			kind = KindOther
+		}
+		groups[kind] = append(groups[kind], issue)
+	}
+
+	var kinds []IssueKind
+	for _, kind := range kindOrder {
+		if len(groups[kind]) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds, groups
+}
@@ -0,0 +1,140 @@
+package latexparser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LineMap maps lines of a generated .tex file (e.g. the preprocessed cache copy)
+// back to the source file and line they came from
+type LineMap struct {
+	Generated string    `json:"generated"` // Base name of the generated file
+	Segments  []Segment `json:"segments"`
+}
+
+// Segment is a run of consecutive generated lines copied from one source
+// An empty File marks lines that were injected and have no source
+type Segment struct {
+	Start      int    `json:"start"`       // First generated line (1-based)
+	Count      int    `json:"count"`       // Number of lines in the run
+	File       string `json:"file"`        // Source file, e.g. "notes/20250101-foo.tex"
+	SourceLine int    `json:"source_line"` // Source line of the first generated line
+}
+
+// NewLineMap creates an empty line map for a generated file
+func NewLineMap(generatedPath string) *LineMap {
+	return &LineMap{
+		Generated: filepath.Base(generatedPath),
+		Segments:  []Segment{},
+	}
+}
+
+// Append adds the next run of generated lines to the map
+// Runs must be appended in generated-file order
+func (m *LineMap) Append(count int, file string, sourceLine int) {
+	if count <= 0 {
+		return
+	}
+
+	start := 1
+	if n := len(m.Segments); n > 0 {
+		last := m.Segments[n-1]
+		start = last.Start + last.Count
+
+		// Merge with the previous run when it continues seamlessly
+		if last.File == file && (file == "" || last.SourceLine+last.Count == sourceLine) {
+			m.Segments[n-1].Count += count
+			return
+		}
+	}
+
+	m.Segments = append(m.Segments, Segment{
+		Start:      start,
+		Count:      count,
+		File:       file,
+		SourceLine: sourceLine,
+	})
+}
+
+// Lookup returns the source location of a generated line
+// ok is false for injected lines and lines outside the map
+func (m *LineMap) Lookup(line int) (file string, sourceLine int, ok bool) {
+	for _, seg := range m.Segments {
+		if line >= seg.Start && line < seg.Start+seg.Count {
+			if seg.File == "" {
+				return "", 0, false
+			}
+			return seg.File, seg.SourceLine + (line - seg.Start), true
+		}
+	}
+	return "", 0, false
+}
+
+// Translate rewrites an issue that points into the generated file so it
+// points at the original source instead. Other issues are returned unchanged.
+func (m *LineMap) Translate(issue Issue) Issue {
+	if issue.File == "" || issue.Line <= 0 {
+		return issue
+	}
+
+	if filepath.Base(issue.File) != m.Generated {
+		return issue
+	}
+
+	if file, line, ok := m.Lookup(issue.Line); ok {
+		issue.File = file
+		issue.Line = line
+	}
+
+	return issue
+}
+
+// ApplyLineMap translates all errors, warnings and box warnings through the line map
+func (pr *ParseResult) ApplyLineMap(m *LineMap) {
+	if m == nil {
+		return
+	}
+
+	for i, issue := range pr.Errors {
+		pr.Errors[i] = m.Translate(issue)
+	}
+	for i, issue := range pr.Warnings {
+		pr.Warnings[i] = m.Translate(issue)
+	}
+	for i, issue := range pr.Boxes {
+		pr.Boxes[i] = m.Translate(issue)
+	}
+}
+
+// LineMapPath returns where the line map for a generated .tex file is stored
+func LineMapPath(texPath string) string {
+	return strings.TrimSuffix(texPath, ".tex") + ".linemap.json"
+}
+
+// Save writes the line map to disk
+func (m *LineMap) Save(path string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to marshal line map: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadLineMap reads a line map written by Save
+func LoadLineMap(path string) (*LineMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read line map: %w", err)
+	}
+
+	var m LineMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal line map: %w", err)
+	}
+
+	return &m, nil
+}
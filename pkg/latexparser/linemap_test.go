@@ -0,0 +1,100 @@
+package latexparser
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLineMap_LookupWithInjectedLine(t *testing.T) {
+	m := NewLineMap("/vault/cache/graph-theory.tex")
+	m.Append(3, "notes/graph-theory.tex", 1) // generated 1-3 -> source 1-3
+	m.Append(1, "", 0)                       // generated 4 is injected
+	m.Append(5, "notes/graph-theory.tex", 4) // generated 5-9 -> source 4-8
+
+	tests := []struct {
+		line     int
+		wantLine int
+		wantOK   bool
+	}{
+		{1, 1, true},
+		{3, 3, true},
+		{4, 0, false},
+		{5, 4, true},
+		{9, 8, true},
+		{10, 0, false},
+	}
+
+	for _, tt := range tests {
+		file, line, ok := m.Lookup(tt.line)
+		if ok != tt.wantOK || line != tt.wantLine {
+			t.Errorf("Lookup(%d) = (%q, %d, %v), want line %d ok=%v", tt.line, file, line, ok, tt.wantLine, tt.wantOK)
+		}
+	}
+}
+
+func TestLineMap_AppendMergesContiguousRuns(t *testing.T) {
+	m := NewLineMap("note.tex")
+	m.Append(2, "notes/a.tex", 1)
+	m.Append(3, "notes/a.tex", 3)
+
+	if len(m.Segments) != 1 {
+		t.Fatalf("Expected contiguous runs to merge into 1 segment, got %d", len(m.Segments))
+	}
+	if m.Segments[0].Count != 5 {
+		t.Errorf("Expected merged count 5, got %d", m.Segments[0].Count)
+	}
+}
+
+func TestParseResult_ApplyLineMap(t *testing.T) {
+	output := `
+/vault/cache/calc.tex:12: Undefined control sequence.
+./other.tex:3: Missing $ inserted.
+`
+	result := ParseLatexOutput(output)
+
+	m := NewLineMap("/vault/cache/calc.tex")
+	m.Append(5, "notes/20250101-calc.tex", 1)
+	m.Append(1, "", 0)
+	m.Append(20, "notes/20250101-calc.tex", 6)
+	result.ApplyLineMap(m)
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d", len(result.Errors))
+	}
+
+	if got := result.Errors[0].Location(); got != "notes/20250101-calc.tex:11" {
+		t.Errorf("Expected mapped location notes/20250101-calc.tex:11, got %s", got)
+	}
+
+	// Issues in other files are left alone
+	if got := result.Errors[1].Location(); got != "./other.tex:3" {
+		t.Errorf("Expected unmapped location ./other.tex:3, got %s", got)
+	}
+}
+
+func TestLineMap_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.linemap.json")
+
+	m := NewLineMap("note.tex")
+	m.Append(4, "notes/note.tex", 1)
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadLineMap(path)
+	if err != nil {
+		t.Fatalf("LoadLineMap failed: %v", err)
+	}
+
+	if file, line, ok := loaded.Lookup(2); !ok || file != "notes/note.tex" || line != 2 {
+		t.Errorf("Loaded map Lookup(2) = (%q, %d, %v)", file, line, ok)
+	}
+}
+
+func TestLineMapPath(t *testing.T) {
+	got := LineMapPath("/vault/cache/note.tex")
+	want := "/vault/cache/note.linemap.json"
+	if got != want {
+		t.Errorf("LineMapPath() = %q, want %q", got, want)
+	}
+}
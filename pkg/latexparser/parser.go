@@ -1,7 +1,6 @@
 package latexparser
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,10 +10,23 @@ import (
 
 // Issue represents a compilation issue (error or warning)
 type Issue struct {
-	Level   IssueLevel
-	File    string
-	Line    int
-	Message string
+	Level      IssueLevel `json:"level"`
+	Kind       IssueKind  `json:"kind,omitempty"`
+	File       string     `json:"file,omitempty"`
+	Line       int        `json:"line,omitempty"`
+	Message    string     `json:"message"`
+	Amount     float64    `json:"amount_pt,omitempty"`  // Overfull boxes: how far the box overflows
+	Context    []string   `json:"context,omitempty"`    // Source excerpt TeX printed with the issue
+	Suggestion string     `json:"suggestion,omitempty"` // How to fix it, when known
+}
+
+// Location returns "file:line" (or just the file when the line is unknown)
+// so terminals and editors can jump straight to the issue
+func (i Issue) Location() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d", i.File, i.Line)
+	}
+	return i.File
 }
 
 type IssueLevel int
@@ -38,10 +50,31 @@ func (l IssueLevel) String() string {
 	}
 }
 
+// MarshalText encodes the level as its lowercase name (e.g. in JSON)
+func (l IssueLevel) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(l.String())), nil
+}
+
+// UnmarshalText decodes a level written by MarshalText
+func (l *IssueLevel) UnmarshalText(text []byte) error {
+	switch strings.ToUpper(string(text)) {
+	case "ERROR":
+		*l = LevelError
+	case "WARNING":
+		*l = LevelWarning
+	case "INFO":
+		*l = LevelInfo
+	default:
+		return fmt.Errorf("unknown issue level: %s", text)
+	}
+	return nil
+}
+
 // ParseResult holds the parsing results
 type ParseResult struct {
 	Errors      []Issue
 	Warnings    []Issue
+	Boxes       []Issue // Overfull/underfull box warnings, kept apart from Warnings
 	HasPDF      bool
 	PDFPath     string
 	CompletedOK bool // latexmk completed without fatal errors
@@ -55,11 +88,23 @@ var (
 	fileLineErrorPattern = regexp.MustCompile(`^([^:]+\.tex):(\d+):\s*(.+)$`)
 
 	// Warning patterns
-	warningPattern = regexp.MustCompile(`^(?:LaTeX|Package\s+\w+)\s+Warning:\s*(.+)$`)
+	warningPattern = regexp.MustCompile(`^(?:LaTeX(?:\s+Font)?|Package\s+[\w.-]+|Class\s+[\w.-]+)\s+Warning:\s*(.+)$`)
+
+	// Continuation of a package warning: "(hyperref)   removing 'math shift'..."
+	warningContinuationPattern = regexp.MustCompile(`^\([\w.-]+\)\s+(.+)$`)
+
+	// Missing glyphs are reported outside the usual warning format
+	missingCharPattern = regexp.MustCompile(`^Missing character: .+`)
 
 	// Box warnings (usually harmless overfull/underfull boxes)
 	boxWarningPattern = regexp.MustCompile(`^(?:Overfull|Underfull)\s+\\[hv]box`)
 
+	// Source excerpt after an error: "l.42 \badcommand"
+	sourceLinePattern = regexp.MustCompile(`^l\.(\d+)(?:\s(.*))?$`)
+
+	// A .tex file being opened: "(./graph.tex" or "(/vault/cache/graph.tex"
+	fileOpenPattern = regexp.MustCompile(`\(([^\s()]+\.tex)\b`)
+
 	// PDF output confirmation - multiple patterns for robustness
 	pdfOutputPatterns = []*regexp.Regexp{
 		regexp.MustCompile(`Output written.*\.pdf`),
@@ -83,31 +128,42 @@ var (
 )
 
 // ParseLatexOutput parses LaTeX/latexmk output comprehensively
+// Issues are classified by kind; errors carry the source excerpt TeX prints
+// after the "l.<n>" marker, and box warnings are collected separately
 func ParseLatexOutput(output string) *ParseResult {
 	result := &ParseResult{
 		Errors:      []Issue{},
 		Warnings:    []Issue{},
+		Boxes:       []Issue{},
 		HasPDF:      false,
 		CompletedOK: false,
 	}
 
 	// First, join wrapped lines for better pattern matching
-	unwrappedOutput := unwrapLatexOutput(output)
+	lines := strings.Split(unwrapLatexOutput(output), "\n")
+
+	// The most recently opened .tex file; issues without a file refer to it
+	currentFile := ""
 
-	scanner := bufio.NewScanner(strings.NewReader(unwrappedOutput))
-	var currentError string
-	inErrorContext := false
-	lineNum := 0
+	// Index of the last error still waiting for its "l.<n>" excerpt (-1 = none)
+	pendingError := -1
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		lineNum++
+	// The last warning, so wrapped continuation lines can be appended
+	var lastWarning *Issue
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
 
 		// Skip empty lines early
 		if strings.TrimSpace(line) == "" {
+			lastWarning = nil
 			continue
 		}
 
+		if matches := fileOpenPattern.FindAllStringSubmatch(line, -1); matches != nil {
+			currentFile = matches[len(matches)-1][1]
+		}
+
 		// Check for PDF output (multiple patterns for robustness)
 		for _, pattern := range pdfOutputPatterns {
 			if matches := pattern.FindStringSubmatch(line); matches != nil {
@@ -124,11 +180,49 @@ func ParseLatexOutput(output string) *ParseResult {
 			result.CompletedOK = true
 		}
 
-		// Skip box warnings (usually not critical)
+		// Source excerpt for the pending error
+		if matches := sourceLinePattern.FindStringSubmatch(line); matches != nil {
+			if pendingError >= 0 {
+				issue := &result.Errors[pendingError]
+				if issue.Line == 0 || issue.File == "" {
+					fmt.Sscanf(matches[1], "%d", &issue.Line)
+				}
+				if issue.File == "" {
+					issue.File = currentFile
+				}
+				issue.Context = []string{line}
+				if i+1 < len(lines) && strings.HasPrefix(lines[i+1], " ") && strings.TrimSpace(lines[i+1]) != "" {
+					issue.Context = append(issue.Context, lines[i+1])
+					i++
+				}
+				pendingError = -1
+			}
+			continue
+		}
+
+		// Box warnings are kept apart since they are usually harmless
 		if boxWarningPattern.MatchString(line) {
+			if box, ok := parseBox(line, currentFile); ok {
+				// The offending material follows on the next lines
+				for i+1 < len(lines) && len(box.Context) < 3 && isBoxMaterial(lines[i+1]) {
+					box.Context = append(box.Context, lines[i+1])
+					i++
+				}
+				result.Boxes = append(result.Boxes, box)
+			}
+			lastWarning = nil
 			continue
 		}
 
+		// Wrapped package warnings continue on lines prefixed with "(package)"
+		if lastWarning != nil {
+			if matches := warningContinuationPattern.FindStringSubmatch(line); matches != nil {
+				*lastWarning = newIssue(LevelWarning, lastWarning.File, 0, lastWarning.Message+" "+strings.TrimSpace(matches[1]))
+				continue
+			}
+			lastWarning = nil
+		}
+
 		// Parse file:line:error format FIRST (before other patterns)
 		// This ensures we catch file:line:message before generic patterns like "Undefined control sequence"
 		if matches := fileLineErrorPattern.FindStringSubmatch(line); matches != nil {
@@ -143,19 +237,10 @@ func ParseLatexOutput(output string) *ParseResult {
 
 				// Determine if it's actually an error or just a warning
 				if strings.Contains(strings.ToLower(message), "warning") {
-					result.Warnings = append(result.Warnings, Issue{
-						Level:   LevelWarning,
-						File:    file,
-						Message: message,
-						Line:    texLine,
-					})
+					result.Warnings = append(result.Warnings, newIssue(LevelWarning, file, texLine, message))
 				} else {
-					result.Errors = append(result.Errors, Issue{
-						Level:   LevelError,
-						File:    file,
-						Message: message,
-						Line:    texLine,
-					})
+					result.Errors = append(result.Errors, newIssue(LevelError, file, texLine, message))
+					pendingError = len(result.Errors) - 1
 				}
 			}
 			// IMPORTANT: Continue to next line to avoid duplicate processing
@@ -163,60 +248,61 @@ func ParseLatexOutput(output string) *ParseResult {
 		}
 
 		// Parse standard LaTeX errors (! format)
+		// The line number comes from the "l.<n>" excerpt that follows
 		if matches := errorPattern.FindStringSubmatch(line); matches != nil {
-			currentError = matches[1]
-			inErrorContext = true
-			continue
-		}
-
-		// If we're in error context, collect the next line as it usually has details
-		if inErrorContext && currentError != "" {
-			result.Errors = append(result.Errors, Issue{
-				Level:   LevelError,
-				Message: currentError,
-				Line:    lineNum,
-			})
-			currentError = ""
-			inErrorContext = false
+			result.Errors = append(result.Errors, newIssue(LevelError, "", 0, matches[1]))
+			pendingError = len(result.Errors) - 1
 			continue
 		}
 
 		// Check for fatal errors (but these are now less likely to match since file:line was checked first)
+		matchedFatal := false
 		for _, pattern := range fatalErrorPatterns {
 			if pattern.MatchString(line) {
-				result.Errors = append(result.Errors, Issue{
-					Level:   LevelError,
-					Message: strings.TrimSpace(line),
-					Line:    lineNum,
-				})
-				inErrorContext = true
+				result.Errors = append(result.Errors, newIssue(LevelError, "", 0, strings.TrimSpace(line)))
+				matchedFatal = true
 				break
 			}
 		}
+		if matchedFatal {
+			continue
+		}
 
 		// Parse standard warnings
 		if matches := warningPattern.FindStringSubmatch(line); matches != nil {
-			result.Warnings = append(result.Warnings, Issue{
-				Level:   LevelWarning,
-				Message: matches[1],
-				Line:    lineNum,
-			})
+			result.Warnings = append(result.Warnings, newIssue(LevelWarning, currentFile, 0, matches[1]))
+			lastWarning = &result.Warnings[len(result.Warnings)-1]
+			continue
+		}
+
+		if missingCharPattern.MatchString(line) {
+			issue := newIssue(LevelWarning, "", 0, strings.TrimSpace(line))
+			issue.Kind = KindFont
+			result.Warnings = append(result.Warnings, issue)
 			continue
 		}
 	}
 
-	// If we have a pending error that wasn't followed by details, add it now
-	if currentError != "" {
-		result.Errors = append(result.Errors, Issue{
-			Level:   LevelError,
-			Message: currentError,
-			Line:    lineNum,
-		})
+	// Warnings without a line number cannot be located in a file
+	for _, issues := range [][]Issue{result.Warnings, result.Boxes} {
+		for i := range issues {
+			if issues[i].Line == 0 {
+				issues[i].File = ""
+			}
+		}
 	}
 
 	return result
 }
 
+// isBoxMaterial reports whether a line is part of the material TeX prints after a box warning
+func isBoxMaterial(line string) bool {
+	if strings.TrimSpace(line) == "" {
+		return false
+	}
+	return !boxWarningPattern.MatchString(line) && !errorPattern.MatchString(line) && !warningPattern.MatchString(line)
+}
+
 // unwrapLatexOutput attempts to join wrapped lines in LaTeX output
 // LaTeX output often wraps long lines at 79 characters, breaking pattern matching
 // We use a conservative approach - only join lines that are clearly continuations
@@ -339,9 +425,25 @@ func (pr *ParseResult) GetSummary() string {
 	return "⚠️  Unknown compilation status"
 }
 
-// FormatIssues returns a formatted string of all issues
+// FormatOptions controls what FormatIssuesWith includes
+type FormatOptions struct {
+	ShowBoxes bool // Include overfull/underfull box warnings
+}
+
+// FormatIssues returns a formatted string of all issues, grouped by kind
+// Box warnings are left out; use FormatIssuesWith to include them
 func (pr *ParseResult) FormatIssues() string {
-	if len(pr.Errors) == 0 && len(pr.Warnings) == 0 {
+	return pr.FormatIssuesWith(FormatOptions{})
+}
+
+// FormatIssuesWith returns a formatted string of the issues selected by opts
+func (pr *ParseResult) FormatIssuesWith(opts FormatOptions) string {
+	warnings := pr.Warnings
+	if opts.ShowBoxes {
+		warnings = append(append([]Issue{}, pr.Warnings...), pr.Boxes...)
+	}
+
+	if len(pr.Errors) == 0 && len(warnings) == 0 {
 		return ""
 	}
 
@@ -349,35 +451,56 @@ func (pr *ParseResult) FormatIssues() string {
 
 	if len(pr.Errors) > 0 {
 		sb.WriteString("\nErrors:\n")
-		for i, err := range pr.Errors {
-			if i >= 10 {
-				sb.WriteString(fmt.Sprintf("  ... and %d more errors\n", len(pr.Errors)-10))
-				break
-			}
-			if err.File != "" {
-				sb.WriteString(fmt.Sprintf("  • %s: %s\n", err.File, err.Message))
-			} else {
-				sb.WriteString(fmt.Sprintf("  • %s\n", err.Message))
-			}
-		}
+		formatGroups(&sb, pr.Errors, 10, "errors", true)
 	}
 
-	if len(pr.Warnings) > 0 {
+	if len(warnings) > 0 {
 		sb.WriteString("\nWarnings:\n")
-		for i, warn := range pr.Warnings {
-			if i >= 5 {
-				sb.WriteString(fmt.Sprintf("  ... and %d more warnings\n", len(pr.Warnings)-5))
+		formatGroups(&sb, warnings, 5, "warnings", false)
+	}
+
+	return sb.String()
+}
+
+// formatGroups writes issues grouped by kind, showing at most limit per group
+// Group headings are omitted when every issue is unclassified
+func formatGroups(sb *strings.Builder, issues []Issue, limit int, noun string, withContext bool) {
+	kinds, groups := groupByKind(issues)
+	headings := len(kinds) > 1 || kinds[0] != KindOther
+
+	indent := "  "
+	if headings {
+		indent = "    "
+	}
+
+	for _, kind := range kinds {
+		group := groups[kind]
+		if headings {
+			sb.WriteString(fmt.Sprintf("  %s (%d):\n", kind.Title(), len(group)))
+		}
+
+		for i, issue := range group {
+			if i >= limit {
+				sb.WriteString(fmt.Sprintf("%s... and %d more %s\n", indent, len(group)-limit, noun))
 				break
 			}
-			if warn.File != "" {
-				sb.WriteString(fmt.Sprintf("  • %s: %s\n", warn.File, warn.Message))
+
+			if issue.File != "" {
+				sb.WriteString(fmt.Sprintf("%s• %s: %s\n", indent, issue.Location(), issue.Message))
 			} else {
-				sb.WriteString(fmt.Sprintf("  • %s\n", warn.Message))
+				sb.WriteString(fmt.Sprintf("%s• %s\n", indent, issue.Message))
+			}
+
+			if withContext {
+				for _, context := range issue.Context {
+					sb.WriteString(fmt.Sprintf("%s    %s\n", indent, context))
+				}
+			}
+			if issue.Suggestion != "" {
+				sb.WriteString(fmt.Sprintf("%s  → %s\n", indent, issue.Suggestion))
 			}
 		}
 	}
-
-	return sb.String()
 }
 
 // VerifyPDFExists checks if the PDF file actually exists on disk
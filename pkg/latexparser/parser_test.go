@@ -223,3 +223,112 @@ func TestIsSuccess(t *testing.T) {
 		})
 	}
 }
+
+func TestParseLatexOutput_Kinds(t *testing.T) {
+	output := `
+(./graph.tex
+LaTeX Warning: Reference ` + "`" + `fig:tree' on page 1 undefined on input line 12.
+LaTeX Warning: Citation ` + "`" + `knuth84' on page 2 undefined on input line 20.
+LaTeX Font Warning: Font shape ` + "`" + `OT1/cmr/bx/sc' undefined
+(Font)              using ` + "`" + `OT1/cmr/bx/n' instead on input line 31.
+LaTeX Warning: Label(s) may have changed. Rerun to get cross-references right.
+./graph.tex:3: LaTeX Error: File ` + "`" + `tikz.sty' not found.
+! Package pdftex.def Error: File ` + "`" + `figure.png' not found: using draft setting.
+`
+	result := ParseLatexOutput(output)
+
+	wantWarnings := []IssueKind{KindUndefinedReference, KindUndefinedCitation, KindFont, KindRerun}
+	if len(result.Warnings) != len(wantWarnings) {
+		t.Fatalf("Expected %d warnings, got %d: %+v", len(wantWarnings), len(result.Warnings), result.Warnings)
+	}
+	for i, kind := range wantWarnings {
+		if result.Warnings[i].Kind != kind {
+			t.Errorf("Warning %d: expected kind %s, got %s", i, kind, result.Warnings[i].Kind)
+		}
+	}
+
+	// Wrapped warnings keep their continuation and input line
+	font := result.Warnings[2]
+	if font.Line != 31 || font.File != "./graph.tex" || !strings.Contains(font.Message, "instead") {
+		t.Errorf("Expected font warning at ./graph.tex:31 with continuation, got %+v", font)
+	}
+
+	if len(result.Errors) != 2 {
+		t.Fatalf("Expected 2 errors, got %d", len(result.Errors))
+	}
+	if result.Errors[0].Kind != KindMissingPackage || !strings.Contains(result.Errors[0].Suggestion, "tlmgr install pgf") {
+		t.Errorf("Expected missing package with pgf suggestion, got %+v", result.Errors[0])
+	}
+	if result.Errors[1].Kind != KindMissingFile || result.Errors[1].Suggestion != "" {
+		t.Errorf("Expected missing file without suggestion, got %+v", result.Errors[1])
+	}
+}
+
+func TestParseLatexOutput_ErrorContext(t *testing.T) {
+	output := `(/vault/cache/graph.tex
+! Undefined control sequence.
+l.10 The value of \badcommand
+                              is unknown.
+`
+	result := ParseLatexOutput(output)
+
+	if len(result.Errors) != 1 {
+		t.Fatalf("Expected 1 error, got %d", len(result.Errors))
+	}
+
+	err := result.Errors[0]
+	if err.File != "/vault/cache/graph.tex" || err.Line != 10 {
+		t.Errorf("Expected /vault/cache/graph.tex:10, got %s", err.Location())
+	}
+	if len(err.Context) != 2 || !strings.Contains(err.Context[0], `\badcommand`) || !strings.Contains(err.Context[1], "is unknown.") {
+		t.Errorf("Expected both context lines, got %q", err.Context)
+	}
+}
+
+func TestParseLatexOutput_Boxes(t *testing.T) {
+	output := `(./graph.tex
+Overfull \hbox (12.5pt too wide) in paragraph at lines 15--16
+[]\OT1/cmr/m/n/10 A very long line
+Underfull \vbox (badness 10000) detected at line 40
+`
+	result := ParseLatexOutput(output)
+
+	if len(result.Warnings) != 0 {
+		t.Errorf("Expected box warnings to stay out of Warnings, got %d", len(result.Warnings))
+	}
+	if len(result.Boxes) != 2 {
+		t.Fatalf("Expected 2 box warnings, got %d", len(result.Boxes))
+	}
+
+	overfull := result.Boxes[0]
+	if overfull.Amount != 12.5 || overfull.Line != 15 || len(overfull.Context) != 1 {
+		t.Errorf("Unexpected overfull box: %+v", overfull)
+	}
+	if result.Boxes[1].Line != 40 {
+		t.Errorf("Expected underfull box at line 40, got %d", result.Boxes[1].Line)
+	}
+}
+
+func TestFormatIssuesWith_GroupsAndBoxes(t *testing.T) {
+	result := &ParseResult{
+		Warnings: []Issue{
+			{Level: LevelWarning, Kind: KindUndefinedReference, Message: "Reference `a' undefined"},
+			{Level: LevelWarning, Kind: KindRerun, Message: "Rerun to get cross-references right."},
+		},
+		Boxes: []Issue{
+			{Level: LevelWarning, Kind: KindBox, Message: `Overfull \hbox (3pt too wide)`},
+		},
+	}
+
+	formatted := result.FormatIssues()
+	if !strings.Contains(formatted, "Undefined references (1):") || !strings.Contains(formatted, "Rerun needed (1):") {
+		t.Errorf("Expected grouped output, got:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "Overfull") {
+		t.Error("Expected box warnings to be hidden by default")
+	}
+
+	if !strings.Contains(result.FormatIssuesWith(FormatOptions{ShowBoxes: true}), "Overfull") {
+		t.Error("Expected box warnings with ShowBoxes")
+	}
+}
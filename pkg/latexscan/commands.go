@@ -0,0 +1,177 @@
+package latexscan
+
+import "strings"
+
+// Invocation is a command together with its arguments
+// Only invocations with a mandatory argument are reported
+type Invocation struct {
+	Name     string
+	Star     bool  // \section*{...}
+	Optional []Arg // [...] arguments before the mandatory one
+	Arg      Arg   // The mandatory {...} argument
+	Start    int   // Byte offset of the backslash
+	End      int   // Byte offset just after the closing brace
+	Line     int   // 1-based line of the command
+}
+
+// Arg is the content of an argument, without its brackets or braces
+type Arg struct {
+	Text  string
+	Start int // Byte offset of the content
+	End   int
+}
+
+// Source returns the full text of the invocation
+func (inv Invocation) Source(src string) string {
+	return src[inv.Start:inv.End]
+}
+
+// FindCommands returns the invocations of the named commands in live code
+// Commands in comments, verbatim environments and \iffalse blocks are ignored,
+// and nested braces in arguments are handled, as in \todo{fix \textbf{this}}
+func FindCommands(src string, names ...string) []Invocation {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	tokens := Tokenize(src)
+	var invocations []Invocation
+
+	for i, tok := range tokens {
+		if tok.Kind != Command || !wanted[tok.Name] {
+			continue
+		}
+		if inv, ok := parseInvocation(src, tokens, i); ok {
+			invocations = append(invocations, inv)
+		}
+	}
+
+	return invocations
+}
+
+// ReplaceCommands rewrites invocations of the named commands
+// replace receives each invocation and returns the text to put in its place
+func ReplaceCommands(src string, replace func(inv Invocation) string, names ...string) string {
+	invocations := FindCommands(src, names...)
+	if len(invocations) == 0 {
+		return src
+	}
+
+	var sb strings.Builder
+	cursor := 0
+	for _, inv := range invocations {
+		sb.WriteString(src[cursor:inv.Start])
+		sb.WriteString(replace(inv))
+		cursor = inv.End
+	}
+	sb.WriteString(src[cursor:])
+
+	return sb.String()
+}
+
+// Comments returns the comment tokens of the source
+func Comments(src string) []Token {
+	var comments []Token
+	for _, tok := range Tokenize(src) {
+		if tok.Kind == Comment {
+			comments = append(comments, tok)
+		}
+	}
+	return comments
+}
+
+// parseInvocation reads the arguments following the command at tokens[i]
+// Arguments may be separated from the command by spaces, but not by a line break
+func parseInvocation(src string, tokens []Token, i int) (Invocation, bool) {
+	cmd := tokens[i]
+	inv := Invocation{
+		Name:  cmd.Name,
+		Start: cmd.Start,
+		Line:  cmd.Line,
+	}
+
+	j := i + 1
+	skipSpaces := func() {
+		for j < len(tokens) && tokens[j].Kind == Text && strings.Trim(tokens[j].Text, " \t") == "" {
+			j++
+		}
+	}
+
+	if j < len(tokens) && tokens[j].Kind == Text && strings.TrimRight(tokens[j].Text, " \t") == "*" {
+		inv.Star = true
+		j++
+	}
+
+	for {
+		skipSpaces()
+		if j >= len(tokens) {
+			return inv, false
+		}
+
+		switch tokens[j].Kind {
+		case OpenBracket:
+			end, ok := matchGroup(tokens, j, OpenBracket, CloseBracket)
+			if !ok {
+				return inv, false
+			}
+			inv.Optional = append(inv.Optional, groupArg(src, tokens, j, end))
+			j = end + 1
+		case OpenBrace:
+			end, ok := matchGroup(tokens, j, OpenBrace, CloseBrace)
+			if !ok {
+				return inv, false
+			}
+			inv.Arg = groupArg(src, tokens, j, end)
+			inv.End = tokens[end].End
+			return inv, true
+		default:
+			return inv, false
+		}
+	}
+}
+
+// matchGroup finds the token closing the group opened at tokens[open]
+// Brackets only count outside braces, so [key={a]b}] is one group
+func matchGroup(tokens []Token, open int, opening, closing Kind) (int, bool) {
+	depth := 0
+	braces := 0
+
+	for k := open; k < len(tokens); k++ {
+		switch tokens[k].Kind {
+		case OpenBrace:
+			braces++
+		case CloseBrace:
+			braces--
+		}
+
+		if opening == OpenBrace {
+			// Braces are the group delimiters themselves
+			if braces == 0 {
+				return k, true
+			}
+			continue
+		}
+
+		if braces > 0 {
+			continue
+		}
+		switch tokens[k].Kind {
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return k, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// groupArg returns the content between the delimiters at tokens[open] and tokens[end]
+func groupArg(src string, tokens []Token, open, end int) Arg {
+	from, to := tokens[open].End, tokens[end].Start
+	return Arg{Text: src[from:to], Start: from, End: to}
+}
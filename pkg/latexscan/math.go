@@ -0,0 +1,214 @@
+package latexscan
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MathBlock is a piece of math in the source
+type MathBlock struct {
+	Env   string // Math environment name, or the opening delimiter: $, $$, \( or \[
+	Label string // First \label inside the block, if any
+	Text  string // Source between the delimiters
+	Start int    // Byte offset of Text in the source
+	End   int    // Byte offset just after Text
+	Line  int    // 1-based line where the math itself starts
+}
+
+// mathAliases map macros to the spelling they are equivalent to
+var mathAliases = map[string]string{
+	"dfrac":  `\frac`,
+	"tfrac":  `\frac`,
+	"le":     `\leq`,
+	"ge":     `\geq`,
+	"ne":     `\neq`,
+	"to":     `\rightarrow`,
+	"gets":   `\leftarrow`,
+	"land":   `\wedge`,
+	"lor":    `\vee`,
+	"lnot":   `\neg`,
+	"lbrace": `\{`,
+	"rbrace": `\}`,
+	"vert":   "|",
+	"lvert":  "|",
+	"rvert":  "|",
+	"Vert":   `\|`,
+	"lVert":  `\|`,
+	"rVert":  `\|`,
+}
+
+// mathLayout are macros that only change spacing, sizing or numbering
+var mathLayout = map[string]bool{
+	",": true, ";": true, ":": true, "!": true, " ": true, "\\": true,
+	"quad": true, "qquad": true,
+	"left": true, "right": true, "middle": true,
+	"big": true, "Big": true, "bigg": true, "Bigg": true,
+	"bigl": true, "bigr": true, "Bigl": true, "Bigr": true,
+	"biggl": true, "biggr": true, "Biggl": true, "Biggr": true,
+	"displaystyle": true, "textstyle": true, "scriptstyle": true,
+	"limits": true, "nolimits": true,
+	"nonumber": true, "notag": true,
+}
+
+// MathBlocks returns the inline and display math of LaTeX source, in order
+// Math in comments, verbatim and inactive code is ignored, and so are unclosed blocks
+func MathBlocks(src string) []MathBlock {
+	envs := make(map[int]Invocation)
+	for _, inv := range FindCommands(src, "begin", "end") {
+		envs[inv.Start] = inv
+	}
+
+	var blocks []MathBlock
+	var open *MathBlock
+
+	finish := func(end int) {
+		open.End = end
+		open.Text = src[open.Start:end]
+		trimmed := strings.TrimLeft(open.Text, " \t\r\n")
+		open.Line = strings.Count(src[:end-len(trimmed)], "\n") + 1
+		blocks = append(blocks, *open)
+		open = nil
+	}
+
+	for _, tok := range Tokenize(src) {
+		switch tok.Kind {
+		case Command:
+			if inv, ok := envs[tok.Start]; ok {
+				env := inv.Arg.Text
+				switch {
+				case open == nil && inv.Name == "begin" && MathEnvironments[env]:
+					open = &MathBlock{Env: env, Start: inv.End}
+				case open != nil && inv.Name == "end" && open.Env == env:
+					finish(inv.Start)
+				}
+				continue
+			}
+
+			switch {
+			case open == nil && (tok.Name == "(" || tok.Name == "["):
+				open = &MathBlock{Env: tok.Text, Start: tok.End}
+			case open != nil && tok.Name == ")" && open.Env == `\(`,
+				open != nil && tok.Name == "]" && open.Env == `\[`:
+				finish(tok.Start)
+			}
+
+		case Text:
+			for i := 0; i < len(tok.Text); i++ {
+				if tok.Text[i] != '$' {
+					continue
+				}
+				delim := "$"
+				if strings.HasPrefix(tok.Text[i:], "$$") {
+					delim = "$$"
+				}
+
+				switch {
+				case open == nil:
+					open = &MathBlock{Env: delim, Start: tok.Start + i + len(delim)}
+				case open.Env == delim:
+					finish(tok.Start + i)
+				}
+				i += len(delim) - 1
+			}
+		}
+	}
+
+	// Attach labels to the blocks they are in
+	labels := FindCommands(src, "label")
+	for i := range blocks {
+		for _, inv := range labels {
+			if inv.Start >= blocks[i].Start && inv.End <= blocks[i].End {
+				blocks[i].Label = inv.Arg.Text
+				break
+			}
+		}
+	}
+
+	return blocks
+}
+
+// NormalizeMath rewrites math so that trivially different spellings compare equal
+// Whitespace, alignment, spacing and sizing macros, labels and comments are dropped,
+// equivalent macros such as \le and \leq are unified, and braces around a single
+// symbol are removed, so x^{2} and \frac{1}{2} match x^2 and \frac12
+func NormalizeMath(math string) string {
+	var atoms []string
+	skipGroup := false // Drop the argument of \label
+	depth := 0         // Brace depth inside the skipped argument
+
+	for _, tok := range Tokenize(math) {
+		if skipGroup {
+			switch {
+			case depth == 0 && tok.Kind == Text && strings.TrimSpace(tok.Text) == "":
+				continue
+			case tok.Kind == OpenBrace:
+				depth++
+				continue
+			case tok.Kind == CloseBrace:
+				depth--
+				skipGroup = depth > 0
+				continue
+			case depth > 0:
+				continue
+			}
+			// \label without an argument
+			skipGroup = false
+		}
+
+		switch tok.Kind {
+		case Command:
+			switch {
+			case tok.Name == "label":
+				skipGroup = true
+			case mathLayout[tok.Name]:
+			case mathAliases[tok.Name] != "":
+				atoms = append(atoms, mathAliases[tok.Name])
+			default:
+				atoms = append(atoms, `\`+tok.Name)
+			}
+		case Text, Verbatim:
+			for _, r := range tok.Text {
+				if !unicode.IsSpace(r) && r != '&' && r != '~' {
+					atoms = append(atoms, string(r))
+				}
+			}
+		case OpenBrace:
+			atoms = append(atoms, "{")
+		case CloseBrace:
+			atoms = append(atoms, "}")
+		case OpenBracket:
+			atoms = append(atoms, "[")
+		case CloseBracket:
+			atoms = append(atoms, "]")
+		}
+	}
+
+	// Unwrap single-atom groups until none are left, so {{x}} becomes x
+	for changed := true; changed; {
+		changed = false
+		kept := atoms[:0]
+		for i := 0; i < len(atoms); i++ {
+			if atoms[i] == "{" && i+2 < len(atoms) && atoms[i+2] == "}" && atoms[i+1] != "{" && atoms[i+1] != "}" {
+				kept = append(kept, atoms[i+1])
+				i += 2
+				changed = true
+				continue
+			}
+			kept = append(kept, atoms[i])
+		}
+		atoms = kept
+	}
+
+	// A control word needs a space before a following letter, or \alpha b would read \alphab
+	var sb strings.Builder
+	for i, atom := range atoms {
+		sb.WriteString(atom)
+		if i+1 < len(atoms) && len(atom) > 1 && atom[0] == '\\' && isWord(atom[1:]) {
+			if r, _ := utf8.DecodeRuneInString(atoms[i+1]); isWordRune(r) {
+				sb.WriteByte(' ')
+			}
+		}
+	}
+	return sb.String()
+}
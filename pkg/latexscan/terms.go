@@ -0,0 +1,205 @@
+package latexscan
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Term is a searchable word of the source
+type Term struct {
+	Text  string // Lowercased word
+	Start int    // Byte offset of the word in the source
+	End   int    // Byte offset just after the word
+	Line  int    // 1-based line of the word
+	Math  bool   // The word is in math mode
+}
+
+// MathEnvironments are environments typeset in display math mode
+var MathEnvironments = map[string]bool{
+	"equation":    true,
+	"equation*":   true,
+	"align":       true,
+	"align*":      true,
+	"alignat":     true,
+	"alignat*":    true,
+	"gather":      true,
+	"gather*":     true,
+	"multline":    true,
+	"multline*":   true,
+	"flalign":     true,
+	"flalign*":    true,
+	"eqnarray":    true,
+	"eqnarray*":   true,
+	"math":        true,
+	"displaymath": true,
+}
+
+// nameArguments are commands whose argument is a name (a label, file or package) rather than prose
+var nameArguments = []string{
+	"begin", "end",
+	"label", "ref", "eqref", "cref", "Cref", "autoref", "pageref",
+	"cite", "citep", "citet",
+	"lxnote", "lxembed",
+	"input", "include", "includegraphics",
+	"usepackage", "RequirePackage", "documentclass",
+	"bibliography", "bibliographystyle",
+	"url", "href",
+}
+
+// Terms returns the searchable words of LaTeX source
+// Command names, comments, inactive code and name arguments such as labels are
+// dropped. In math mode, command names are kept as words (\alpha is "alpha"),
+// as are single letters, so formulas stay searchable
+func Terms(src string) []Term {
+	names := FindCommands(src, nameArguments...)
+	next := 0 // First name argument that has not been passed yet
+
+	var terms []Term
+	inlineMath := false
+	mathDepth := 0
+
+	for _, tok := range Tokenize(src) {
+		// Skip name arguments, tracking math environments on the way
+		for next < len(names) && names[next].End <= tok.Start {
+			next++
+		}
+		if next < len(names) && tok.Start >= names[next].Start {
+			if inv := names[next]; tok.Start == inv.Start && MathEnvironments[inv.Arg.Text] {
+				switch inv.Name {
+				case "begin":
+					mathDepth++
+				case "end":
+					mathDepth = max(mathDepth-1, 0)
+				}
+			}
+			continue
+		}
+
+		switch tok.Kind {
+		case Command:
+			switch tok.Name {
+			case "(", "[":
+				inlineMath = true
+			case ")", "]":
+				inlineMath = false
+			default:
+				if (inlineMath || mathDepth > 0) && isWord(tok.Name) {
+					terms = append(terms, Term{
+						Text:  strings.ToLower(tok.Name),
+						Start: tok.Start + 1,
+						End:   tok.End,
+						Line:  tok.Line,
+						Math:  true,
+					})
+				}
+			}
+		case Text:
+			terms = appendWords(terms, tok, &inlineMath, mathDepth > 0)
+		case Verbatim:
+			terms = appendWords(terms, tok, nil, false)
+		}
+	}
+
+	return terms
+}
+
+// SplitTerms returns the words of plain text, such as a title or a search query
+func SplitTerms(text string) []Term {
+	var terms []Term
+	line := 1
+	start := -1
+
+	for pos := 0; pos <= len(text); {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if pos < len(text) && isWordRune(r) {
+			if start < 0 {
+				start = pos
+			}
+			pos += size
+			continue
+		}
+		if start >= 0 {
+			terms = append(terms, Term{Text: strings.ToLower(text[start:pos]), Start: start, End: pos, Line: line})
+			start = -1
+		}
+		if r == '\n' {
+			line++
+		}
+		if pos == len(text) {
+			break
+		}
+		pos += size
+	}
+
+	return terms
+}
+
+// appendWords adds the words of a text token, toggling inline math at each $
+// Outside math, single characters are dropped as noise; a nil inlineMath takes $ literally
+func appendWords(terms []Term, tok Token, inlineMath *bool, displayMath bool) []Term {
+	text := tok.Text
+	line := tok.Line
+	start := -1
+
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		math := displayMath || (inlineMath != nil && *inlineMath)
+		word := text[start:end]
+		if math || utf8.RuneCountInString(word) > 1 {
+			terms = append(terms, Term{
+				Text:  strings.ToLower(word),
+				Start: tok.Start + start,
+				End:   tok.Start + end,
+				Line:  line,
+				Math:  math,
+			})
+		}
+		start = -1
+	}
+
+	for pos := 0; pos < len(text); {
+		r, size := utf8.DecodeRuneInString(text[pos:])
+		if isWordRune(r) {
+			if start < 0 {
+				start = pos
+			}
+			pos += size
+			continue
+		}
+
+		flush(pos)
+		switch r {
+		case '\n':
+			line++
+		case '$':
+			if inlineMath == nil {
+				break
+			}
+			// $$...$$ toggles once per pair, like $...$
+			if strings.HasPrefix(text[pos:], "$$") {
+				size = 2
+			}
+			*inlineMath = !*inlineMath
+		}
+		pos += size
+	}
+	flush(len(text))
+
+	return terms
+}
+
+func isWord(s string) bool {
+	for _, r := range s {
+		if !isWordRune(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,303 @@
+package latexscan
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind identifies what a token is
+type Kind int
+
+const (
+	Text         Kind = iota // Plain text, including whitespace
+	Command                  // \name, or a control symbol such as \% or \\
+	Comment                  // From an unescaped % up to (not including) the end of the line
+	OpenBrace                // {
+	CloseBrace               // }
+	OpenBracket              // [
+	CloseBracket             // ]
+	Verbatim                 // Body of a verbatim-like environment, or the argument of \verb
+	Disabled                 // Code TeX never sees: \iffalse blocks and comment environments
+)
+
+// Token is a piece of LaTeX source
+type Token struct {
+	Kind  Kind
+	Text  string // Source text of the token
+	Name  string // Command name without the backslash (Command tokens only)
+	Start int    // Byte offset of the token in the source
+	End   int    // Byte offset just after the token
+	Line  int    // 1-based line the token starts on
+}
+
+// VerbatimEnvironments are environments whose body is taken literally
+var VerbatimEnvironments = map[string]bool{
+	"verbatim":      true,
+	"verbatim*":     true,
+	"Verbatim":      true,
+	"Verbatim*":     true,
+	"BVerbatim":     true,
+	"LVerbatim":     true,
+	"lstlisting":    true,
+	"minted":        true,
+	"filecontents":  true,
+	"filecontents*": true,
+}
+
+// DisabledEnvironments are environments whose body is dropped (the comment package)
+var DisabledEnvironments = map[string]bool{
+	"comment": true,
+}
+
+// verbatimCommands take their argument literally between two delimiters, as in \verb|x|
+var verbatimCommands = map[string]bool{
+	"verb":      true,
+	"lstinline": true,
+}
+
+// primitiveConditionals are the conditionals of TeX and its engines, which \fi closes
+// Macros such as \ifthenelse take arguments instead and are not counted
+var primitiveConditionals = map[string]bool{
+	"if":             true,
+	"ifcat":          true,
+	"ifnum":          true,
+	"ifdim":          true,
+	"ifodd":          true,
+	"ifvmode":        true,
+	"ifhmode":        true,
+	"ifmmode":        true,
+	"ifinner":        true,
+	"ifvoid":         true,
+	"ifhbox":         true,
+	"ifvbox":         true,
+	"ifx":            true,
+	"ifeof":          true,
+	"iftrue":         true,
+	"iffalse":        true,
+	"ifcase":         true,
+	"ifdefined":      true,
+	"ifcsname":       true,
+	"iffontchar":     true,
+	"ifincsname":     true,
+	"ifprimitive":    true,
+	"ifabsnum":       true,
+	"ifabsdim":       true,
+	"ifpdfprimitive": true,
+	"ifpdfabsnum":    true,
+	"ifpdfabsdim":    true,
+}
+
+// tokenizer keeps the scanning state
+type tokenizer struct {
+	src    string
+	pos    int
+	line   int
+	tokens []Token
+
+	// Conditionals declared with \newif so far
+	declared map[string]bool
+}
+
+// Tokenize splits LaTeX source into tokens
+// Comments, verbatim content and \iffalse blocks become single tokens, so
+// nothing inside them is mistaken for commands or braces
+func Tokenize(src string) []Token {
+	t := &tokenizer{src: src, line: 1, declared: make(map[string]bool)}
+
+	for t.pos < len(src) {
+		switch src[t.pos] {
+		case '\\':
+			t.command()
+		case '%':
+			end := strings.IndexByte(src[t.pos:], '\n')
+			if end < 0 {
+				end = len(src) - t.pos
+			}
+			t.emit(Comment, t.pos+end, "")
+		case '{':
+			t.emit(OpenBrace, t.pos+1, "")
+		case '}':
+			t.emit(CloseBrace, t.pos+1, "")
+		case '[':
+			t.emit(OpenBracket, t.pos+1, "")
+		case ']':
+			t.emit(CloseBracket, t.pos+1, "")
+		default:
+			end := t.pos
+			for end < len(src) && !strings.ContainsRune(`\%{}[]`, rune(src[end])) {
+				end++
+			}
+			t.emit(Text, end, "")
+		}
+	}
+
+	return t.tokens
+}
+
+// emit adds the token from the current position up to end and advances
+func (t *tokenizer) emit(kind Kind, end int, name string) {
+	if end <= t.pos {
+		return
+	}
+	text := t.src[t.pos:end]
+	t.tokens = append(t.tokens, Token{
+		Kind:  kind,
+		Text:  text,
+		Name:  name,
+		Start: t.pos,
+		End:   end,
+		Line:  t.line,
+	})
+	t.line += strings.Count(text, "\n")
+	t.pos = end
+}
+
+// command scans a control word or symbol and whatever it makes literal
+func (t *tokenizer) command() {
+	start := t.pos
+	end := start + 1
+
+	// Control words are letters (and @ in packages); anything else is a one-character control symbol
+	for end < len(t.src) && isLetter(t.src[end]) {
+		end++
+	}
+	if end == start+1 && end < len(t.src) {
+		_, size := utf8.DecodeRuneInString(t.src[end:])
+		end += size
+	}
+
+	name := t.src[start+1 : end]
+	t.emit(Command, end, name)
+
+	switch {
+	case verbatimCommands[name]:
+		t.verbatimArgument()
+	case name == "begin":
+		t.environment()
+	case name == "newif":
+		if declared := controlWord(t.src, t.pos); strings.HasPrefix(declared, "if") {
+			t.declared[declared] = true
+		}
+	case name == "iffalse":
+		t.disabledConditional()
+	}
+}
+
+// verbatimArgument scans the delimited argument of \verb or \lstinline
+func (t *tokenizer) verbatimArgument() {
+	pos := t.pos
+	if pos < len(t.src) && t.src[pos] == '*' {
+		pos++
+	}
+	if pos >= len(t.src) || t.src[pos] == '\n' {
+		return
+	}
+
+	delimiter := t.src[pos]
+	if delimiter == '{' {
+		delimiter = '}'
+	}
+
+	// The argument ends at the closing delimiter, which must be on the same line
+	for end := pos + 1; end < len(t.src) && t.src[end] != '\n'; end++ {
+		if t.src[end] == delimiter {
+			t.emit(Verbatim, end+1, "")
+			return
+		}
+	}
+}
+
+// environment turns the body of a verbatim-like or comment environment into one token
+// The \begin{...} and \end{...} themselves stay ordinary tokens
+func (t *tokenizer) environment() {
+	rest := t.src[t.pos:]
+	if !strings.HasPrefix(rest, "{") {
+		return
+	}
+	closing := strings.IndexByte(rest, '}')
+	if closing < 0 {
+		return
+	}
+
+	env := rest[1:closing]
+	kind := Verbatim
+	switch {
+	case VerbatimEnvironments[env]:
+	case DisabledEnvironments[env]:
+		kind = Disabled
+	default:
+		return
+	}
+
+	t.emit(OpenBrace, t.pos+1, "")
+	t.emit(Text, t.pos+len(env), "")
+	t.emit(CloseBrace, t.pos+1, "")
+
+	end := strings.Index(t.src[t.pos:], `\end{`+env+`}`)
+	if end < 0 {
+		end = len(t.src) - t.pos
+	}
+	t.emit(kind, t.pos+end, "")
+}
+
+// disabledConditional turns an \iffalse block into one token
+// It ends at the matching \else (after which code is live again) or \fi
+func (t *tokenizer) disabledConditional() {
+	depth := 1
+	pos := t.pos
+
+	for pos < len(t.src) {
+		idx := strings.IndexByte(t.src[pos:], '\\')
+		if idx < 0 {
+			break
+		}
+		start := pos + idx
+		end := start + 1
+		for end < len(t.src) && isLetter(t.src[end]) {
+			end++
+		}
+		if end == start+1 {
+			// Control symbols such as \\ or \% cannot open or close conditionals
+			pos = end + 1
+			continue
+		}
+
+		name := t.src[start+1 : end]
+		switch {
+		case name == "newif":
+			// The name being declared does not open a conditional
+			if next := controlWord(t.src, end); next != "" {
+				end += len(next) + 1
+			}
+		case name == "fi":
+			depth--
+		case name == "else" && depth == 1:
+			depth = 0
+		case primitiveConditionals[name] || t.declared[name]:
+			depth++
+		}
+		if depth == 0 {
+			t.emit(Disabled, start, "")
+			return
+		}
+		pos = end
+	}
+
+	t.emit(Disabled, len(t.src), "")
+}
+
+// controlWord returns the name of the control word starting at pos, or "" if there is none
+func controlWord(src string, pos int) string {
+	if pos >= len(src) || src[pos] != '\\' {
+		return ""
+	}
+	end := pos + 1
+	for end < len(src) && isLetter(src[end]) {
+		end++
+	}
+	return src[pos+1 : end]
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '@'
+}
@@ -0,0 +1,209 @@
+package latexscan
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func commandNames(src string, names ...string) []string {
+	var found []string
+	for _, inv := range FindCommands(src, names...) {
+		found = append(found, inv.Arg.Text)
+	}
+	return found
+}
+
+func TestFindCommands_SkipsInactiveCode(t *testing.T) {
+	src := `\lxnote{live}
+% \lxnote{commented}
+50\% done \lxnote{after-escape}
+\begin{verbatim}
+\lxnote{verbatim}
+\end{verbatim}
+\begin{lstlisting}[language=TeX]
+\lxnote{listing}
+\end{lstlisting}
+\verb|\lxnote{inline}|
+\iffalse
+\lxnote{disabled} \ifx a b \fi
+\else
+\lxnote{else-branch}
+\fi
+\begin{comment}
+\lxnote{comment-env}
+\end{comment}`
+
+	got := commandNames(src, "lxnote")
+	want := []string{"live", "after-escape", "else-branch"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCommands() = %v, want %v", got, want)
+	}
+}
+
+func TestFindCommands_IfFalseCountsOnlyConditionals(t *testing.T) {
+	src := `\newif\ifdraft
+\iffalse
+\newif\ifhidden
+\ifthenelse{\boolean{x}}{a}{b}
+\ifdraft \lxnote{draft} \fi
+\lxnote{disabled}
+\fi
+\lxnote{live}`
+
+	got := commandNames(src, "lxnote")
+	want := []string{"live"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindCommands() = %v, want %v", got, want)
+	}
+}
+
+func TestFindCommands_Arguments(t *testing.T) {
+	src := "\\todo{fix \\textbf{this}}\n\\includegraphics[width={0.5\\textwidth}]{fig.png}\n\\section*{Intro}\n\\label"
+
+	todos := FindCommands(src, "todo")
+	if len(todos) != 1 || todos[0].Arg.Text != `fix \textbf{this}` {
+		t.Fatalf("expected nested braces in the argument, got %+v", todos)
+	}
+	if todos[0].Source(src) != `\todo{fix \textbf{this}}` {
+		t.Errorf("unexpected invocation source %q", todos[0].Source(src))
+	}
+
+	graphics := FindCommands(src, "includegraphics")
+	if len(graphics) != 1 || graphics[0].Arg.Text != "fig.png" || graphics[0].Optional[0].Text != `width={0.5\textwidth}` {
+		t.Errorf("unexpected includegraphics invocation %+v", graphics)
+	}
+	if graphics[0].Line != 2 {
+		t.Errorf("expected line 2, got %d", graphics[0].Line)
+	}
+
+	sections := FindCommands(src, "section")
+	if len(sections) != 1 || !sections[0].Star || sections[0].Arg.Text != "Intro" {
+		t.Errorf("unexpected section invocation %+v", sections)
+	}
+
+	// Without a mandatory argument there is no invocation
+	if labels := FindCommands(src, "label"); len(labels) != 0 {
+		t.Errorf("expected no label invocations, got %+v", labels)
+	}
+}
+
+func TestReplaceCommands(t *testing.T) {
+	src := "\\ref{a} % \\ref{b}\n\\ref{c}"
+
+	got := ReplaceCommands(src, func(inv Invocation) string {
+		return `\cref{` + inv.Arg.Text + `}`
+	}, "ref")
+
+	want := "\\cref{a} % \\ref{b}\n\\cref{c}"
+	if got != want {
+		t.Errorf("ReplaceCommands() = %q, want %q", got, want)
+	}
+}
+
+func TestComments(t *testing.T) {
+	comments := Comments("text % TODO: one\n\\% not a comment\n%TODO: two")
+	if len(comments) != 2 || comments[0].Text != "% TODO: one" || comments[1].Line != 3 {
+		t.Errorf("unexpected comments %+v", comments)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	src := `% title: Hidden
+\section{Compact Spaces}\label{sec:compact}
+A space is compact if $\alpha x$ and \[ \int f \] hold.
+\begin{equation}\beta\end{equation}
+\begin{verbatim}$code$\end{verbatim}
+See \lxnote{other-note}{Lindelöf} too.`
+
+	var words []string
+	math := map[string]bool{}
+	for _, term := range Terms(src) {
+		words = append(words, term.Text)
+		if term.Math {
+			math[term.Text] = true
+		}
+	}
+
+	want := []string{"compact", "spaces", "space", "is", "compact", "if", "alpha", "x", "and", "int", "f", "hold", "beta", "code", "see", "lindelöf", "too"}
+	if strings.Join(words, " ") != strings.Join(want, " ") {
+		t.Errorf("Terms() = %v, want %v", words, want)
+	}
+
+	for _, word := range []string{"alpha", "x", "int", "f", "beta"} {
+		if !math[word] {
+			t.Errorf("expected %q to be in math mode", word)
+		}
+	}
+	if math["code"] || math["hold"] {
+		t.Error("expected verbatim and prose words outside math mode")
+	}
+}
+
+func TestTerms_Lines(t *testing.T) {
+	terms := Terms("first line\n\nthird $y$")
+	if len(terms) != 4 || terms[2].Line != 3 || terms[3].Text != "y" || terms[3].Line != 3 {
+		t.Errorf("unexpected terms: %+v", terms)
+	}
+}
+
+func TestSplitTerms(t *testing.T) {
+	terms := SplitTerms("Graph Theory: a Primer")
+	var words []string
+	for _, term := range terms {
+		words = append(words, term.Text)
+	}
+	if strings.Join(words, ",") != "graph,theory,a,primer" {
+		t.Errorf("SplitTerms() = %v", words)
+	}
+	if terms[1].Start != 6 || terms[1].End != 12 {
+		t.Errorf("unexpected offsets for %+v", terms[1])
+	}
+}
+
+func TestMathBlocks(t *testing.T) {
+	src := "Inline $a+b$ and \\(c\\), cost \\$5.\n" +
+		"\\begin{equation}\n  \\nabla \\times E \\label{eq:faraday}\n\\end{equation}\n" +
+		"% $hidden$\n" +
+		"\\[ x^2 \\] and $$y$$\n" +
+		"\\begin{align}\n  a &= $b$ \\\\\n\\end{align}\n"
+
+	var got []string
+	for _, block := range MathBlocks(src) {
+		got = append(got, block.Env+"|"+strings.TrimSpace(block.Text)+"|"+block.Label)
+	}
+	want := []string{
+		"$|a+b|",
+		`\(|c|`,
+		`equation|\nabla \times E \label{eq:faraday}|eq:faraday`,
+		`\[|x^2|`,
+		"$$|y|",
+		`align|a &= $b$ \\|`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MathBlocks() =\n%q\nwant\n%q", got, want)
+	}
+
+	if blocks := MathBlocks(src); blocks[2].Line != 3 || blocks[3].Line != 6 {
+		t.Errorf("unexpected lines: %d, %d", blocks[2].Line, blocks[3].Line)
+	}
+}
+
+func TestNormalizeMath(t *testing.T) {
+	for _, pair := range [][2]string{
+		{`\nabla \times \vec{E}`, `\nabla\times\vec E`},
+		{`x^{2} + \frac{1}{2}`, `x^2+\frac12`},
+		{`\dfrac{a}{b} \le c`, `\frac ab\leq c`},
+		{`\left( a \right) \, \quad b`, `(a)b`},
+		{`a &= b \label{eq:x} \\ c`, `a=bc`},
+		{`\int_0^\infty e^{-x}\,dx % note`, `\int_0^\infty e^{-x}dx`},
+	} {
+		if got := NormalizeMath(pair[0]); got != NormalizeMath(pair[1]) {
+			t.Errorf("NormalizeMath(%q) = %q, want %q", pair[0], got, NormalizeMath(pair[1]))
+		}
+	}
+
+	if NormalizeMath(`\alpha b`) == NormalizeMath(`\alphab`) {
+		t.Error("expected a control word to stay separate from a following letter")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -12,6 +13,23 @@ type Metadata struct {
 	Title string
 	Date  string
 	Tags  []string
+
+	// Build options (optional, apply to this note only)
+	Engine       string   // pdflatex, lualatex or xelatex
+	ShellEscape  bool     // Enables \write18 (needed by minted, etc.)
+	LatexmkFlags []string // Replaces latexmk_flags from config
+	Bib          string   // biber, bibtex or none
+}
+
+// SupportedEngines lists the values accepted by the Engine field
+var SupportedEngines = []string{"pdflatex", "lualatex", "xelatex"}
+
+// SupportedBibTools lists the values accepted by the Bib field
+var SupportedBibTools = []string{"biber", "bibtex", "none"}
+
+// HasBuildOptions reports whether any per-note build option is set
+func (m *Metadata) HasBuildOptions() bool {
+	return m.Engine != "" || m.ShellEscape || len(m.LatexmkFlags) > 0 || m.Bib != ""
 }
 
 // ParseError represents a metadata parsing error
@@ -60,6 +78,10 @@ func (p *Parser) Parse(content string) (*ParseResult, error) {
 	reTitle := regexp.MustCompile(`(?i)^%+\s*Title:\s*(.+)`)
 	reDate := regexp.MustCompile(`(?i)^%+\s*Date:\s*(.+)`)
 	reTags := regexp.MustCompile(`(?i)^%+\s*Tags:\s*(.+)`)
+	reEngine := regexp.MustCompile(`(?i)^%+\s*Engine:\s*(.+)`)
+	reShellEscape := regexp.MustCompile(`(?i)^%+\s*Shell-Escape:\s*(.+)`)
+	reLatexmkFlags := regexp.MustCompile(`(?i)^%+\s*Latexmk-Flags:\s*(.+)`)
+	reBib := regexp.MustCompile(`(?i)^%+\s*Bib:\s*(.+)`)
 
 	foundTitle := false
 	foundDate := false
@@ -95,6 +117,45 @@ func (p *Parser) Parse(content string) (*ParseResult, error) {
 				}
 			}
 		}
+
+		// Engine
+		if matches := reEngine.FindStringSubmatch(line); len(matches) > 1 {
+			engine := strings.ToLower(stripComment(matches[1]))
+			if contains(SupportedEngines, engine) {
+				result.Metadata.Engine = engine
+			} else {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("line %d: unsupported engine '%s' ignored", lineNum, engine))
+			}
+		}
+
+		// Shell-Escape
+		if matches := reShellEscape.FindStringSubmatch(line); len(matches) > 1 {
+			value := stripComment(matches[1])
+			enabled, err := parseBool(value)
+			if err != nil {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("line %d: invalid Shell-Escape value '%s' ignored", lineNum, value))
+			} else {
+				result.Metadata.ShellEscape = enabled
+			}
+		}
+
+		// Latexmk-Flags
+		if matches := reLatexmkFlags.FindStringSubmatch(line); len(matches) > 1 {
+			result.Metadata.LatexmkFlags = strings.Fields(stripComment(matches[1]))
+		}
+
+		// Bib
+		if matches := reBib.FindStringSubmatch(line); len(matches) > 1 {
+			tool := strings.ToLower(stripComment(matches[1]))
+			if contains(SupportedBibTools, tool) {
+				result.Metadata.Bib = tool
+			} else {
+				result.Warnings = append(result.Warnings,
+					fmt.Sprintf("line %d: unsupported bibliography tool '%s' ignored", lineNum, tool))
+			}
+		}
 	}
 
 	// Validation
@@ -136,6 +197,41 @@ func ExtractStrict(content string) (*Metadata, error) {
 	return result.Metadata, nil
 }
 
+// stripComment removes a trailing % comment from a header value, as in
+// "lualatex % or xelatex"; an escaped \% is kept
+func stripComment(value string) string {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '%':
+			return strings.TrimSpace(value[:i])
+		}
+	}
+	return strings.TrimSpace(value)
+}
+
+// parseBool accepts the usual boolean spellings plus yes/no and on/off
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// contains reports whether list holds value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
 // -----------------------------------------------------------------------------
 // New Functionality (Merged from utils.go)
 // -----------------------------------------------------------------------------
@@ -149,6 +245,18 @@ func Format(m *Metadata) string {
 	if len(m.Tags) > 0 {
 		b.WriteString(fmt.Sprintf("%% tags: %s\n", strings.Join(m.Tags, ", ")))
 	}
+	if m.Engine != "" {
+		b.WriteString(fmt.Sprintf("%% engine: %s\n", m.Engine))
+	}
+	if m.ShellEscape {
+		b.WriteString("% shell-escape: true\n")
+	}
+	if len(m.LatexmkFlags) > 0 {
+		b.WriteString(fmt.Sprintf("%% latexmk-flags: %s\n", strings.Join(m.LatexmkFlags, " ")))
+	}
+	if m.Bib != "" {
+		b.WriteString(fmt.Sprintf("%% bib: %s\n", m.Bib))
+	}
 	b.WriteString("% ---\n")
 	return b.String()
 }
@@ -168,3 +276,21 @@ func UpdateTitle(content, newTitle string) (string, error) {
 	// Preserve the prefix ("% title: " or "% Title: ") and replace the rest
 	return re.ReplaceAllString(content, "${1}"+newTitle), nil
 }
+
+// UpdateTags replaces the tags line in the content
+// Without a tags line, one is added after the date line, or at the top
+func UpdateTags(content string, tags []string) string {
+	re := regexp.MustCompile(`(?mi)^(%+[ \t]*tags:[ \t]*)(.*)$`)
+	value := strings.Join(tags, ", ")
+
+	// Only the first tags line is the header; later ones are note content
+	if loc := re.FindStringSubmatchIndex(content); loc != nil {
+		return content[:loc[3]] + value + content[loc[1]:]
+	}
+
+	line := "% tags: " + value
+	if loc := regexp.MustCompile(`(?mi)^%+[ \t]*date:.*$`).FindStringIndex(content); loc != nil {
+		return content[:loc[1]] + "\n" + line + content[loc[1]:]
+	}
+	return line + "\n" + content
+}
@@ -29,6 +29,18 @@ func TestFormat(t *testing.T) {
 			},
 			expected: "% ---\n% title: Simple Note\n% date: 2025-01-01\n% ---\n",
 		},
+		{
+			name: "build options",
+			input: &Metadata{
+				Title:       "Minted Note",
+				Date:        "2025-01-01",
+				Tags:        []string{},
+				Engine:      "xelatex",
+				ShellEscape: true,
+				Bib:         "bibtex",
+			},
+			expected: "% ---\n% title: Minted Note\n% date: 2025-01-01\n% engine: xelatex\n% shell-escape: true\n% bib: bibtex\n% ---\n",
+		},
 	}
 
 	for _, tt := range tests {
@@ -90,6 +102,60 @@ func TestExtract(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "build options",
+			content: `% ---
+% title: Fancy Fonts
+% date: 2025-01-01
+% Engine: LuaLaTeX
+% Shell-Escape: yes
+% Latexmk-Flags: -lualatex  -synctex=1
+% Bib: biber
+% ---`,
+			want: &Metadata{
+				Title:        "Fancy Fonts",
+				Date:         "2025-01-01",
+				Tags:         []string{},
+				Engine:       "lualatex",
+				ShellEscape:  true,
+				LatexmkFlags: []string{"-lualatex", "-synctex=1"},
+				Bib:          "biber",
+			},
+			wantErr: false,
+		},
+		{
+			name: "build options with trailing comments",
+			content: `% ---
+% title: Commented Options
+% engine: lualatex              % pdflatex, lualatex or xelatex
+% shell-escape: true            % e.g. for minted
+% latexmk-flags: -synctex=1 -jobname=50\%  % replaces latexmk_flags from config
+% bib: biber                    % biber, bibtex or none
+% ---`,
+			want: &Metadata{
+				Title:        "Commented Options",
+				Tags:         []string{},
+				Engine:       "lualatex",
+				ShellEscape:  true,
+				LatexmkFlags: []string{"-synctex=1", "-jobname=50\\%"},
+				Bib:          "biber",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported build options ignored",
+			content: `% ---
+% title: Odd Options
+% engine: context
+% shell-escape: maybe
+% bib: zotero
+% ---`,
+			want: &Metadata{
+				Title: "Odd Options",
+				Tags:  []string{},
+			},
+			wantErr: false,
+		},
 		{
 			name: "missing title (error)",
 			content: `% ---
@@ -203,3 +269,39 @@ func TestUpdateTitle(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		tags    []string
+		want    string
+	}{
+		{
+			name:    "replaces the header line",
+			content: "% title: A\n%%  Tags: math, old\n\\documentclass{article}\n% tags: body",
+			tags:    []string{"math", "new/nested"},
+			want:    "% title: A\n%%  Tags: math, new/nested\n\\documentclass{article}\n% tags: body",
+		},
+		{
+			name:    "adds after the date",
+			content: "% title: A\n% date: 2025-01-01\n\\documentclass{article}",
+			tags:    []string{"math"},
+			want:    "% title: A\n% date: 2025-01-01\n% tags: math\n\\documentclass{article}",
+		},
+		{
+			name:    "adds at the top",
+			content: "\\documentclass{article}",
+			tags:    []string{"math"},
+			want:    "% tags: math\n\\documentclass{article}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UpdateTags(tt.content, tt.tags); got != tt.want {
+				t.Errorf("UpdateTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package synctex
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+)
+
+// Rewrite corrects a SyncTeX file produced from a generated .tex file
+// (e.g. the preprocessed cache copy) so it points at the original sources.
+// Records tagged with the generated file are translated through the line map,
+// and the generated input is replaced by the files it was built from.
+// Relative source paths in the line map are resolved against root.
+func Rewrite(path string, m *latexparser.LineMap, root string) error {
+	lines, err := readLines(path)
+	if err != nil {
+		return err
+	}
+
+	// 1. Find the tag of the generated file and the highest tag in use
+	generatedTag, maxTag := 0, 0
+	for _, line := range lines {
+		if matches := inputPattern.FindStringSubmatch(line); matches != nil {
+			tag := atoi(matches[1])
+			if tag > maxTag {
+				maxTag = tag
+			}
+			if generatedTag == 0 && filepath.Base(matches[2]) == m.Generated {
+				generatedTag = tag
+			}
+		}
+	}
+
+	if generatedTag == 0 {
+		return fmt.Errorf("generated file %s not found in synctex data", m.Generated)
+	}
+
+	// 2. Assign a tag to every source file; the first one reuses the generated tag
+	tags := make(map[string]int)
+	var inputs []string
+	for _, seg := range m.Segments {
+		if seg.File == "" {
+			continue
+		}
+		if _, exists := tags[seg.File]; exists {
+			continue
+		}
+		if len(tags) == 0 {
+			tags[seg.File] = generatedTag
+		} else {
+			maxTag++
+			tags[seg.File] = maxTag
+		}
+		inputs = append(inputs, seg.File)
+	}
+
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	// 3. Rewrite inputs and records
+	var out strings.Builder
+	for _, line := range lines {
+		if matches := inputPattern.FindStringSubmatch(line); matches != nil && atoi(matches[1]) == generatedTag {
+			for _, file := range inputs {
+				fmt.Fprintf(&out, "Input:%d:%s\n", tags[file], resolvePath(file, root))
+			}
+			continue
+		}
+
+		if matches := recordPattern.FindStringSubmatchIndex(line); matches != nil {
+			tag := atoi(line[matches[4]:matches[5]])
+			if tag == generatedTag {
+				genLine := atoi(line[matches[6]:matches[7]])
+				if file, sourceLine, ok := nearestSource(m, genLine); ok {
+					line = line[:matches[4]] + strconv.Itoa(tags[file]) + "," + strconv.Itoa(sourceLine) + line[matches[7]:]
+				}
+			}
+		}
+
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	return writeFile(path, out.String())
+}
+
+// nearestSource returns the source location of a generated line
+// Injected lines have no source of their own, so they point at the closest
+// mapped line before them, or the first mapped line when none comes before
+func nearestSource(m *latexparser.LineMap, line int) (file string, sourceLine int, ok bool) {
+	if file, sourceLine, ok := m.Lookup(line); ok {
+		return file, sourceLine, true
+	}
+
+	for _, seg := range m.Segments {
+		if seg.File == "" {
+			continue
+		}
+		if seg.Start > line {
+			if file == "" {
+				return seg.File, seg.SourceLine, true
+			}
+			break
+		}
+		file, sourceLine = seg.File, seg.SourceLine+seg.Count-1
+	}
+	return file, sourceLine, file != ""
+}
+
+// resolvePath turns a line map source path into an absolute path
+func resolvePath(file string, root string) string {
+	if filepath.IsAbs(file) || root == "" {
+		return file
+	}
+	return filepath.Join(root, filepath.FromSlash(file))
+}
+
+// writeFile writes SyncTeX data, compressing it for .gz paths
+func writeFile(path string, content string) error {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to write synctex file: %w", err)
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(f)
+		if _, err := gz.Write([]byte(content)); err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to write synctex file: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			f.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("failed to write synctex file: %w", err)
+		}
+	} else if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write synctex file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write synctex file: %w", err)
+	}
+
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,336 @@
+package synctex
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// spPerBP is the number of TeX scaled points in one PDF big point
+const spPerBP = 65781.76
+
+// maxLineLength bounds a single SyncTeX record line
+const maxLineLength = 1024 * 1024
+
+var (
+	// Records look like "(tag,line:h,v:W,H,D", "k tag,line:h,v:W" or "$tag,line:h,v"
+	// An optional column may follow the line: "tag,line,column:"
+	recordPattern = regexp.MustCompile(`^([\[(vhxkg$])(\d+),(\d+)(?:,-?\d+)?:(-?\d+),(-?\d+)(?::(-?\d+)(?:,(-?\d+),(-?\d+))?)?`)
+
+	// Input lines declare the files referenced by tag: "Input:1:/path/file.tex"
+	inputPattern = regexp.MustCompile(`^Input:(\d+):(.*)$`)
+
+	// Page boundaries: "{3" opens page 3
+	pagePattern = regexp.MustCompile(`^\{(\d+)`)
+)
+
+// Record is a single box, glue, kern or math node with its source origin
+type Record struct {
+	Kind   byte // '(' hbox, '[' vbox, 'h'/'v' void boxes, 'x', 'k', 'g', '$'
+	Page   int
+	Tag    int
+	Line   int
+	H      float64 // Horizontal position (sp)
+	V      float64 // Vertical position of the baseline (sp)
+	Width  float64
+	Height float64
+	Depth  float64
+}
+
+// isBox reports whether the record carries box dimensions
+func (r Record) isBox() bool {
+	return r.Kind == '(' || r.Kind == '['
+}
+
+// Document is a parsed SyncTeX file
+type Document struct {
+	Inputs  map[int]string // tag -> input file path
+	Records []Record
+
+	unit          float64
+	magnification float64
+	xOffset       float64
+	yOffset       float64
+}
+
+// Position is a location in the PDF, in big points from the page's top-left corner
+type Position struct {
+	Page   int
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// SourceLocation is a location in a source file
+type SourceLocation struct {
+	File string
+	Line int
+}
+
+// PathForPDF returns the SyncTeX file that belongs to a PDF
+// Compressed data (.synctex.gz) is preferred over plain .synctex
+func PathForPDF(pdfPath string) string {
+	base := strings.TrimSuffix(pdfPath, ".pdf")
+	if _, err := os.Stat(base + ".synctex"); err == nil {
+		if _, err := os.Stat(base + ".synctex.gz"); err != nil {
+			return base + ".synctex"
+		}
+	}
+	return base + ".synctex.gz"
+}
+
+// Open reads and parses a .synctex or .synctex.gz file
+func Open(path string) (*Document, error) {
+	lines, err := readLines(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseLines(lines), nil
+}
+
+// Parse parses uncompressed SyncTeX data
+func Parse(r io.Reader) (*Document, error) {
+	lines, err := scanLines(r)
+	if err != nil {
+		return nil, err
+	}
+	return parseLines(lines), nil
+}
+
+// parseLines builds a document from the lines of a SyncTeX file
+func parseLines(lines []string) *Document {
+	doc := &Document{
+		Inputs:        make(map[int]string),
+		unit:          1,
+		magnification: 1000,
+	}
+
+	page := 0
+	for _, line := range lines {
+		if matches := recordPattern.FindStringSubmatch(line); matches != nil {
+			doc.Records = append(doc.Records, Record{
+				Kind:   matches[1][0],
+				Page:   page,
+				Tag:    atoi(matches[2]),
+				Line:   atoi(matches[3]),
+				H:      atof(matches[4]),
+				V:      atof(matches[5]),
+				Width:  atof(matches[6]),
+				Height: atof(matches[7]),
+				Depth:  atof(matches[8]),
+			})
+			continue
+		}
+
+		if matches := pagePattern.FindStringSubmatch(line); matches != nil {
+			page = atoi(matches[1])
+			continue
+		}
+
+		if matches := inputPattern.FindStringSubmatch(line); matches != nil {
+			doc.Inputs[atoi(matches[1])] = matches[2]
+			continue
+		}
+
+		// Preamble settings
+		if value, ok := strings.CutPrefix(line, "Unit:"); ok {
+			doc.unit = atof(value)
+		} else if value, ok := strings.CutPrefix(line, "Magnification:"); ok {
+			doc.magnification = atof(value)
+		} else if value, ok := strings.CutPrefix(line, "X Offset:"); ok {
+			doc.xOffset = atof(value)
+		} else if value, ok := strings.CutPrefix(line, "Y Offset:"); ok {
+			doc.yOffset = atof(value)
+		}
+	}
+
+	return doc
+}
+
+// toBP converts a SyncTeX distance to PDF big points
+func (d *Document) toBP(value float64) float64 {
+	return value * d.unit * (d.magnification / 1000) / spPerBP
+}
+
+// tagsForFile returns the input tags that refer to file
+// An exact path match wins; otherwise inputs with the same base name are used
+func (d *Document) tagsForFile(file string) []int {
+	clean := filepath.Clean(file)
+	var exact, byName []int
+
+	for tag, input := range d.Inputs {
+		if filepath.Clean(input) == clean {
+			exact = append(exact, tag)
+		} else if filepath.Base(input) == filepath.Base(clean) {
+			byName = append(byName, tag)
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	return byName
+}
+
+// Forward finds where a source line ends up in the PDF
+// If no node was recorded for the exact line, the nearest recorded line is used
+func (d *Document) Forward(file string, line int) (*Position, error) {
+	tags := d.tagsForFile(file)
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("file not found in SyncTeX data: %s", file)
+	}
+
+	isTag := make(map[int]bool, len(tags))
+	for _, tag := range tags {
+		isTag[tag] = true
+	}
+
+	// Find the closest recorded line
+	bestLine, bestDistance := 0, math.MaxInt
+	for _, r := range d.Records {
+		if !isTag[r.Tag] || r.Line <= 0 {
+			continue
+		}
+		distance := r.Line - line
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance < bestDistance {
+			bestLine, bestDistance = r.Line, distance
+		}
+	}
+
+	if bestDistance == math.MaxInt {
+		return nil, fmt.Errorf("no PDF content recorded for %s", file)
+	}
+
+	// Prefer a box on that line since it carries the full extent of the text
+	var match *Record
+	for i := range d.Records {
+		r := &d.Records[i]
+		if !isTag[r.Tag] || r.Line != bestLine {
+			continue
+		}
+		if match == nil || (r.Kind == '(' && match.Kind != '(') {
+			match = r
+		}
+		if match.Kind == '(' {
+			break
+		}
+	}
+
+	return &Position{
+		Page:   match.Page,
+		X:      d.toBP(match.H + d.xOffset),
+		Y:      d.toBP(match.V - match.Height + d.yOffset),
+		Width:  d.toBP(match.Width),
+		Height: d.toBP(match.Height + match.Depth),
+	}, nil
+}
+
+// Inverse finds the source line behind a point in the PDF
+// x and y are in big points from the top-left corner of the page
+func (d *Document) Inverse(page int, x, y float64) (*SourceLocation, error) {
+	// 1. Find the smallest hbox containing the point
+	var box *Record
+	for i := range d.Records {
+		r := &d.Records[i]
+		if r.Page != page || r.Kind != '(' {
+			continue
+		}
+		left, right := d.toBP(r.H+d.xOffset), d.toBP(r.H+r.Width+d.xOffset)
+		top, bottom := d.toBP(r.V-r.Height+d.yOffset), d.toBP(r.V+r.Depth+d.yOffset)
+		if x < left || x > right || y < top || y > bottom {
+			continue
+		}
+		if box == nil || r.Width*(r.Height+r.Depth) < box.Width*(box.Height+box.Depth) {
+			box = r
+		}
+	}
+
+	// 2. Pick the nearest node, restricted to the box's line when we have one
+	var best *Record
+	bestDistance := math.MaxFloat64
+	for i := range d.Records {
+		r := &d.Records[i]
+		if r.Page != page || r.Line <= 0 || r.isBox() {
+			continue
+		}
+		if box != nil && (r.V < box.V-box.Height || r.V > box.V+box.Depth) {
+			continue
+		}
+		dx := d.toBP(r.H+d.xOffset) - x
+		dy := d.toBP(r.V+d.yOffset) - y
+		distance := math.Hypot(dx, dy)
+		if distance < bestDistance {
+			best, bestDistance = r, distance
+		}
+	}
+
+	if best == nil {
+		best = box
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no source found at page %d (%.1f, %.1f)", page, x, y)
+	}
+
+	file, ok := d.Inputs[best.Tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown input tag %d", best.Tag)
+	}
+
+	return &SourceLocation{File: file, Line: best.Line}, nil
+}
+
+// readLines reads a SyncTeX file, transparently decompressing .gz files
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open synctex file: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress synctex file: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	return scanLines(r)
+}
+
+// scanLines splits SyncTeX data into lines
+func scanLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineLength)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read synctex data: %w", err)
+	}
+	return lines, nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func atof(s string) float64 {
+	f, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f
+}
@@ -0,0 +1,179 @@
+package synctex
+
+import (
+	"compress/gzip"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kamal-hamza/lx-cli/pkg/latexparser"
+)
+
+// sample is a trimmed pdfTeX SyncTeX file
+// 4736286sp = 72bp, 6578176sp = 100bp, 9472573sp = 144bp
+const sample = `SyncTeX Version:1
+Input:1:/vault/cache/./graph.tex
+Input:2:/usr/share/texmf/tex/latex/base/article.cls
+Output:pdf
+Magnification:1000
+Unit:1
+X Offset:0
+Y Offset:0
+Content:
+!120
+{1
+[1,5:4736286,49062285:26851152,43038535,0
+(1,5:4736286,6578176:26851152,500000,100000
+x1,5:4736286,6578176
+k1,6:9472573,6578176:10000
+)
+]
+}1
+{2
+(1,20:4736286,13156352:26851152,500000,100000
+g1,20:4736286,13156352
+)
+}2
+Postamble:
+Count:8
+`
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 0.1
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if len(doc.Inputs) != 2 {
+		t.Errorf("Expected 2 inputs, got %d", len(doc.Inputs))
+	}
+	if len(doc.Records) != 6 {
+		t.Errorf("Expected 6 records, got %d", len(doc.Records))
+	}
+	if doc.Records[len(doc.Records)-1].Page != 2 {
+		t.Errorf("Expected last record on page 2, got %d", doc.Records[len(doc.Records)-1].Page)
+	}
+}
+
+func TestForward(t *testing.T) {
+	doc, _ := Parse(strings.NewReader(sample))
+
+	pos, err := doc.Forward("/vault/cache/graph.tex", 5)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if pos.Page != 1 || !approx(pos.X, 72) || !approx(pos.Y, 92.4) {
+		t.Errorf("Forward(5) = page %d (%.2f, %.2f), want page 1 (72, 92.4)", pos.Page, pos.X, pos.Y)
+	}
+
+	// Lines without nodes snap to the nearest recorded line
+	pos, err = doc.Forward("graph.tex", 18)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if pos.Page != 2 {
+		t.Errorf("Forward(18) page = %d, want 2", pos.Page)
+	}
+
+	if _, err := doc.Forward("missing.tex", 1); err == nil {
+		t.Error("Expected error for unknown file")
+	}
+}
+
+func TestInverse(t *testing.T) {
+	doc, _ := Parse(strings.NewReader(sample))
+
+	loc, err := doc.Inverse(1, 150, 99)
+	if err != nil {
+		t.Fatalf("Inverse failed: %v", err)
+	}
+	if loc.File != "/vault/cache/./graph.tex" || loc.Line != 6 {
+		t.Errorf("Inverse = %s:%d, want graph.tex:6", loc.File, loc.Line)
+	}
+
+	loc, err = doc.Inverse(2, 80, 199)
+	if err != nil {
+		t.Fatalf("Inverse failed: %v", err)
+	}
+	if loc.Line != 20 {
+		t.Errorf("Inverse on page 2 = line %d, want 20", loc.Line)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.synctex.gz")
+	f, _ := os.Create(path)
+	gz := gzip.NewWriter(f)
+	gz.Write([]byte(sample))
+	gz.Close()
+	f.Close()
+
+	// Generated line 4 was injected, so generated 5 is source line 4
+	m := latexparser.NewLineMap("/vault/cache/graph.tex")
+	m.Append(3, "notes/20250101-graph.tex", 1)
+	m.Append(1, "", 0)
+	m.Append(30, "notes/20250101-graph.tex", 4)
+
+	if err := Rewrite(path, m, "/vault"); err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	notePath := filepath.Join("/vault", "notes", "20250101-graph.tex")
+	if doc.Inputs[1] != notePath {
+		t.Errorf("Input 1 = %q, want %q", doc.Inputs[1], notePath)
+	}
+	if doc.Inputs[2] == "" {
+		t.Error("Unrelated inputs must be kept")
+	}
+
+	loc, err := doc.Inverse(1, 150, 99)
+	if err != nil {
+		t.Fatalf("Inverse failed: %v", err)
+	}
+	if loc.File != notePath || loc.Line != 5 {
+		t.Errorf("Inverse after rewrite = %s:%d, want %s:5", loc.File, loc.Line, notePath)
+	}
+
+	if _, err := doc.Forward(notePath, 4); err != nil {
+		t.Errorf("Forward on original note failed: %v", err)
+	}
+}
+
+func TestRewrite_InjectedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.synctex")
+	os.WriteFile(path, []byte(sample), 0644)
+
+	// Generated line 5 was injected after source line 4
+	m := latexparser.NewLineMap("/vault/cache/graph.tex")
+	m.Append(4, "notes/20250101-graph.tex", 1)
+	m.Append(1, "", 0)
+	m.Append(30, "notes/20250101-graph.tex", 5)
+
+	if err := Rewrite(path, m, "/vault"); err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	loc, err := doc.Inverse(1, 72, 99)
+	if err != nil {
+		t.Fatalf("Inverse failed: %v", err)
+	}
+	if loc.Line != 4 {
+		t.Errorf("Inverse on an injected line = %d, want the line before it (4)", loc.Line)
+	}
+}
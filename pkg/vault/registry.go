@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultName is the name of the vault at the XDG data location
+const DefaultName = "default"
+
+// EnvVar selects a vault by name or path, like the --vault flag
+const EnvVar = "LX_VAULT"
+
+// LocalConfigName is the config file at the root of a vault
+// Besides overriding settings, it marks a folder as a vault for auto-detection
+const LocalConfigName = "lx.yaml"
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidateName checks that a vault name can be registered and used in \lxnote{vault:slug}
+func ValidateName(name string) error {
+	if name == DefaultName {
+		return fmt.Errorf("vault name %q is reserved for the default vault", name)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid vault name %q: use lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// Selection holds the ways a vault can be chosen, in order of precedence
+type Selection struct {
+	Flag    string            // --vault
+	Env     string            // LX_VAULT
+	Dir     string            // Working directory, searched for a vault root
+	Current string            // Chosen with lx vault use
+	Vaults  map[string]string // Registered vaults: name -> root path
+}
+
+// Select returns the vault a command works on
+// Without any selection, it is the default vault
+func Select(sel Selection) (*Vault, error) {
+	if sel.Flag != "" {
+		return Lookup(sel.Flag, sel.Vaults)
+	}
+	if sel.Env != "" {
+		v, err := Lookup(sel.Env, sel.Vaults)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EnvVar, err)
+		}
+		return v, nil
+	}
+	if sel.Dir != "" {
+		if v, ok := Detect(sel.Dir, sel.Vaults); ok {
+			return v, nil
+		}
+	}
+	if sel.Current != "" {
+		v, err := Lookup(sel.Current, sel.Vaults)
+		if err != nil {
+			return nil, fmt.Errorf("current vault: %w", err)
+		}
+		return v, nil
+	}
+	return New()
+}
+
+// Lookup returns a vault by its registered name, or by the path to its root
+func Lookup(ref string, vaults map[string]string) (*Vault, error) {
+	if root, ok := vaults[ref]; ok {
+		return Open(ref, root)
+	}
+	if ref == DefaultName {
+		return New()
+	}
+
+	// An unregistered vault is named after its folder
+	isPath := ref == "." || ref == ".." || filepath.Base(ref) != ref
+	if info, err := os.Stat(ref); err == nil && info.IsDir() && isPath {
+		root, err := filepath.Abs(ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve vault root: %w", err)
+		}
+		if v, ok := Detect(root, vaults); ok && v.RootPath == root {
+			return v, nil
+		}
+		return Open(filepath.Base(root), root)
+	}
+
+	return nil, fmt.Errorf("unknown vault: %s (see lx vault list)", ref)
+}
+
+// Detect finds the vault that dir is in, looking for a registered vault root or
+// a folder with an lx.yaml in dir and its parents
+func Detect(dir string, vaults map[string]string) (*Vault, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, false
+	}
+
+	roots := make(map[string]string, len(vaults)+1)
+	if root, err := getVaultRoot(); err == nil {
+		roots[filepath.Clean(root)] = DefaultName
+	}
+	for _, name := range Names(vaults) {
+		if root, err := filepath.Abs(vaults[name]); err == nil {
+			roots[root] = name
+		}
+	}
+
+	for {
+		if name, ok := roots[dir]; ok {
+			v, err := Open(name, dir)
+			return v, err == nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, LocalConfigName)); err == nil {
+			v, err := Open(filepath.Base(dir), dir)
+			return v, err == nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, false
+		}
+		dir = parent
+	}
+}
+
+// Names returns the names of the registered vaults in order
+func Names(vaults map[string]string) []string {
+	names := make([]string, 0, len(vaults))
+	for name := range vaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package vault
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"research", "team-2025", "my_notes"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", DefaultName, "Research", "a:b", "a/b", "-x", "two words"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) should fail", name)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmpDir, "data"))
+
+	research := filepath.Join(tmpDir, "research")
+	team := filepath.Join(tmpDir, "team")
+	unregistered := filepath.Join(tmpDir, "scratch")
+	for _, dir := range []string{filepath.Join(research, "notes", "courses"), team, unregistered} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(unregistered, LocalConfigName), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	vaults := map[string]string{"research": research, "team": team}
+
+	tests := []struct {
+		name     string
+		sel      Selection
+		wantName string
+		wantRoot string
+		wantErr  bool
+	}{
+		{name: "default", sel: Selection{Vaults: vaults}, wantName: DefaultName, wantRoot: filepath.Join(tmpDir, "data", "lx")},
+		{name: "current", sel: Selection{Current: "team", Vaults: vaults}, wantName: "team", wantRoot: team},
+		{name: "detected in a notebook", sel: Selection{Dir: filepath.Join(research, "notes", "courses"), Current: "team", Vaults: vaults}, wantName: "research", wantRoot: research},
+		{name: "detected by lx.yaml", sel: Selection{Dir: unregistered, Vaults: vaults}, wantName: "scratch", wantRoot: unregistered},
+		{name: "env over directory", sel: Selection{Env: "team", Dir: research, Vaults: vaults}, wantName: "team", wantRoot: team},
+		{name: "flag over env", sel: Selection{Flag: "research", Env: "team", Vaults: vaults}, wantName: "research", wantRoot: research},
+		{name: "flag with a path", sel: Selection{Flag: team + string(filepath.Separator), Vaults: vaults}, wantName: "team", wantRoot: team},
+		{name: "default by name", sel: Selection{Flag: DefaultName, Current: "team", Vaults: vaults}, wantName: DefaultName, wantRoot: filepath.Join(tmpDir, "data", "lx")},
+		{name: "unknown flag", sel: Selection{Flag: "missing", Vaults: vaults}, wantErr: true},
+		{name: "unknown current", sel: Selection{Current: "missing", Vaults: vaults}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := Select(tt.sel)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got vault %s", v.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if v.Name != tt.wantName || v.RootPath != tt.wantRoot {
+				t.Errorf("Select() = %s at %s, want %s at %s", v.Name, v.RootPath, tt.wantName, tt.wantRoot)
+			}
+			if v.NotesPath != filepath.Join(tt.wantRoot, "notes") {
+				t.Errorf("unexpected notes path %s", v.NotesPath)
+			}
+		})
+	}
+}
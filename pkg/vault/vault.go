@@ -2,12 +2,18 @@ package vault
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// LocalDirs are the vault folders that only make sense on this machine, and are kept out of git
+var LocalDirs = []string{".trash", ".journal"}
+
 // Vault represents the managed storage directory for lx
 type Vault struct {
+	Name          string // Name the vault is registered under, see Select
 	RootPath      string
 	NotesPath     string
 	TemplatesPath string
@@ -18,16 +24,27 @@ type Vault struct {
 
 // New creates a new Vault instance with XDG-compliant paths
 func New() (*Vault, error) {
-	rootPath, rootErr := getVaultRoot()
-	configPath, configErr := getConfigPath()
-	if rootErr != nil {
-		return nil, fmt.Errorf("failed to determine vault root: %w", rootErr)
+	rootPath, err := getVaultRoot()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine vault root: %w", err)
+	}
+	return Open(DefaultName, rootPath)
+}
+
+// Open creates a Vault instance for a vault at rootPath
+// All vaults share the global config file
+func Open(name, rootPath string) (*Vault, error) {
+	rootPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve vault root: %w", err)
 	}
-	if configErr != nil {
-		return nil, fmt.Errorf("failed to determine config path: %w", configErr)
+	configPath, err := getConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine config path: %w", err)
 	}
 
 	vault := &Vault{
+		Name:          name,
 		RootPath:      rootPath,
 		NotesPath:     filepath.Join(rootPath, "notes"),
 		TemplatesPath: filepath.Join(rootPath, "templates"),
@@ -61,6 +78,11 @@ func getVaultRoot() (string, error) {
 	return filepath.Join(homeDir, ".local", "share", "lx"), nil
 }
 
+// GlobalConfigPath returns the path of the global config file
+func GlobalConfigPath() (string, error) {
+	return getConfigPath()
+}
+
 func getConfigPath() (string, error) {
 	// Check XDG_CONFIG_HOME first (Unix-like systems)
 	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
@@ -109,6 +131,12 @@ func (v *Vault) Exists() bool {
 	return info.IsDir()
 }
 
+// LocalConfigPath returns the path of the vault's own config file
+// Its settings override the global config for this vault
+func (v *Vault) LocalConfigPath() string {
+	return filepath.Join(v.RootPath, LocalConfigName)
+}
+
 // GetTexInputsEnv returns the TEXINPUTS environment variable value
 // This allows LaTeX to find templates in the vault
 func (v *Vault) GetTexInputsEnv() string {
@@ -124,6 +152,37 @@ func (v *Vault) GetNotePath(filename string) string {
 	return filepath.Join(v.NotesPath, filename)
 }
 
+// ListNoteFiles returns the .tex files under a notes directory and its notebooks
+// Paths are relative to notesPath and use / separators; hidden directories are skipped
+func ListNoteFiles(notesPath string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(notesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != notesPath && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(d.Name()) != ".tex" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(notesPath, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
 // GetCachePath returns the full path for a cached file
 func (v *Vault) GetCachePath(filename string) string {
 	return filepath.Join(v.CachePath, filename)
@@ -139,6 +198,36 @@ func (v *Vault) IndexPath() string {
 	return filepath.Join(v.CachePath, "index.json")
 }
 
+// SearchIndexPath returns the path to the full-text search index
+func (v *Vault) SearchIndexPath() string {
+	return filepath.Join(v.CachePath, "search-index.json")
+}
+
+// SlugIndexPath returns the path to the cached slug-to-file lookup table
+func (v *Vault) SlugIndexPath() string {
+	return filepath.Join(v.CachePath, "slug-index.json")
+}
+
+// MathIndexPath returns the path to the index of formulas
+func (v *Vault) MathIndexPath() string {
+	return filepath.Join(v.CachePath, "math-index.json")
+}
+
+// BuildManifestPath returns the path to the incremental build manifest
+func (v *Vault) BuildManifestPath() string {
+	return filepath.Join(v.CachePath, "build-manifest.json")
+}
+
+// TrashPath returns the directory holding deleted notes until the trash is emptied
+func (v *Vault) TrashPath() string {
+	return filepath.Join(v.RootPath, ".trash")
+}
+
+// JournalPath returns the directory holding the before-images of recent operations
+func (v *Vault) JournalPath() string {
+	return filepath.Join(v.RootPath, ".journal")
+}
+
 // CleanCache removes all files in the cache directory
 func (v *Vault) CleanCache() error {
 	entries, err := os.ReadDir(v.CachePath)
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 )
@@ -117,6 +118,19 @@ func TestVault_IndexPath(t *testing.T) {
 	}
 }
 
+func TestVault_BuildManifestPath(t *testing.T) {
+	v := &Vault{
+		CachePath: "/test/vault/cache",
+	}
+
+	expected := filepath.Join("/test/vault/cache", "build-manifest.json")
+	result := v.BuildManifestPath()
+
+	if result != expected {
+		t.Errorf("BuildManifestPath() = %q, want %q", result, expected)
+	}
+}
+
 func TestVault_GetTexInputsEnv(t *testing.T) {
 	v := &Vault{
 		TemplatesPath: "/test/vault/templates",
@@ -215,3 +229,36 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestListNoteFiles(t *testing.T) {
+	notesPath := t.TempDir()
+	for _, name := range []string{
+		"inbox.tex",
+		"courses/math201/limits.tex",
+		"courses/math201/figure.png",
+		".trash/deleted.tex",
+	} {
+		path := filepath.Join(notesPath, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := ListNoteFiles(notesPath)
+	if err != nil {
+		t.Fatalf("ListNoteFiles failed: %v", err)
+	}
+
+	want := []string{"courses/math201/limits.tex", "inbox.tex"}
+	if len(files) != len(want) {
+		t.Fatalf("ListNoteFiles() = %v, want %v", files, want)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("ListNoteFiles()[%d] = %q, want %q", i, files[i], want[i])
+		}
+	}
+}